@@ -1,6 +1,7 @@
 package main
 
 import (
+	"archive/zip"
 	"bus_history/internal/collector"
 	"bus_history/internal/config"
 	"bus_history/internal/model"
@@ -8,9 +9,15 @@ import (
 	"bus_history/internal/service"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,25 +33,31 @@ type App struct {
 	cfg      *config.Config
 
 	db         *sql.DB
-	busRepo    *repository.BusRepository
-	configRepo *repository.ConfigRepository
-	apiClient  *service.OpenAPIClient
-	gbisClient *service.GBISClient
+	busRepo    repository.BusStore
+	configRepo repository.ConfigStore
 	busService *service.BusService
 	collector  *collector.Collector
 
+	liveLocations  *service.LiveLocationCache
+	livePollCancel context.CancelFunc
+
+	logs *logSink
+
 	mu sync.Mutex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	logs := newLogSink(recentLogLines)
+	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+	return &App{logs: logs}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.logs.attach(ctx)
 
 	// Load settings
 	settings, err := config.LoadAppSettings()
@@ -69,6 +82,10 @@ func (a *App) initializeServices() error {
 	if a.collector != nil {
 		a.collector.Stop()
 	}
+	if a.livePollCancel != nil {
+		a.livePollCancel()
+		a.livePollCancel = nil
+	}
 	if a.db != nil {
 		a.db.Close()
 	}
@@ -94,27 +111,96 @@ func (a *App) initializeServices() error {
 	a.busRepo = repository.NewBusRepository(db)
 	a.configRepo = repository.NewConfigRepository(db)
 
+	// Backfill plate normalization for rows recorded before it existed
+	a.runDataMigration(plateDataMigration, "normalize stored plates by config region", a.busRepo.NormalizeStoredPlates)
+	// Move arrival times recorded in another zone into the one they are bucketed by
+	a.runDataMigration(timeDataMigration, "normalize stored arrival times to Asia/Seoul", a.busRepo.NormalizeStoredTimes)
+
+	if a.settings.DedupeConfigsOnStartup {
+		if n, err := a.configRepo.DeduplicateConfigs(); err != nil {
+			log.Printf("Failed to deduplicate configs: %v", err)
+		} else if n > 0 {
+			log.Printf("Collapsed %d duplicate configs", n)
+		}
+	}
+
 	// Init Clients (Passing the same service key to both)
-	a.apiClient = service.NewOpenAPIClient(a.cfg.OpenAPI.BaseURL, a.cfg.OpenAPI.ServiceKey)
-	a.gbisClient = service.NewGBISClient(a.cfg.OpenAPI.ServiceKey)
+	apiClient := service.NewOpenAPIClient(a.cfg.OpenAPI.BaseURL, a.cfg.OpenAPI.ServiceKey)
+	gbisClient := service.NewGBISClient(a.cfg.OpenAPI.GBISBaseURL, a.cfg.OpenAPI.ServiceKey)
+	incheonClient := service.NewIncheonClient(a.cfg.OpenAPI.IncheonBaseURL, a.cfg.OpenAPI.ServiceKey)
+
+	apiClient.SetMaxResponseBytes(a.cfg.OpenAPI.MaxResponseBytes)
+	gbisClient.SetMaxResponseBytes(a.cfg.OpenAPI.MaxResponseBytes)
+	incheonClient.SetMaxResponseBytes(a.cfg.OpenAPI.MaxResponseBytes)
+	apiClient.SetAdaptiveTimeout(!a.settings.FixedTimeout)
+	gbisClient.SetAdaptiveTimeout(!a.settings.FixedTimeout)
+	incheonClient.SetAdaptiveTimeout(!a.settings.FixedTimeout)
+	incheonClient.SetArrivalTimeUnit(a.settings.IncheonArrivalUnit)
+	a.busService = service.NewBusService(apiClient, gbisClient, incheonClient)
+	a.busService.SetRateLimit(a.cfg.OpenAPI.MaxRequestsPerSec)
 
-	incheonClient := service.NewIncheonClient(a.cfg.OpenAPI.ServiceKey)
-	a.busService = service.NewBusService(a.gbisClient, incheonClient)
+	a.liveLocations = service.NewLiveLocationCache(a.busService, 10*time.Second)
+	if a.settings.LiveLocationIntervalMs > 0 {
+		var pollCtx context.Context
+		pollCtx, a.livePollCancel = context.WithCancel(a.ctx)
+		go a.liveLocations.Poll(pollCtx, time.Duration(a.settings.LiveLocationIntervalMs)*time.Millisecond, a.activeLiveRoutes)
+	}
 
 	// Init Collector
 	a.collector = collector.NewCollector(
 		a.configRepo,
 		a.busRepo,
-		a.apiClient,
-		a.gbisClient,
+		a.busService,
 		a.cfg.Collector.IntervalMs,
 		a.settings.StartHour,
 		a.settings.EndHour,
+		collector.Options{
+			ApproachStops:        a.cfg.Collector.ApproachStops,
+			ApproachIntervalMs:   a.cfg.Collector.ApproachIntervalMs,
+			StartJitterMs:        a.cfg.Collector.StartJitterMs,
+			WebhookURL:           a.cfg.Collector.WebhookURL,
+			WebhookTimeoutMs:     a.cfg.Collector.WebhookTimeoutMs,
+			RecordHeartbeats:     a.cfg.Collector.RecordHeartbeats,
+			MaxTrackedBuses:      a.cfg.Collector.MaxTrackedBuses,
+			ScheduleToleranceMin: a.cfg.Collector.ScheduleToleranceMin,
+			AggregateOnly:        a.cfg.Collector.AggregateOnly,
+			AggregateBucketMin:   a.cfg.Collector.AggregateBucketMin,
+			SeatsFillLookbackMin: a.cfg.Collector.SeatsFillLookbackMin,
+			MinRecordGapSec:      a.cfg.Collector.MinRecordGapSec,
+			WindowWarmupSec:      a.cfg.Collector.WindowWarmupSec,
+			Windows:              a.settings.Windows,
+			OnRecordSaved: func(count int64) {
+				runtime.EventsEmit(a.ctx, "session-record-count", count)
+			},
+			OnStorageChange: func(status collector.StorageStatus) {
+				runtime.EventsEmit(a.ctx, "storage-status", status)
+			},
+		},
 	)
 
 	return nil
 }
 
+// activeLiveRoutes lists the distinct routes of active configs for the live location poller
+func (a *App) activeLiveRoutes() []service.LiveRoute {
+	configs, err := a.configRepo.FindActive()
+	if err != nil {
+		log.Printf("Failed to load configs for live locations: %v", err)
+		return nil
+	}
+
+	seen := make(map[service.LiveRoute]bool)
+	var routes []service.LiveRoute
+	for _, cfg := range configs {
+		route := service.LiveRoute{RouteID: cfg.RouteID, Region: cfg.Region}
+		if !seen[route] {
+			seen[route] = true
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
 func (a *App) runInitSchema() {
 	schema := `
 	CREATE TABLE IF NOT EXISTS route_configs (
@@ -140,11 +226,146 @@ func (a *App) runInitSchema() {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (route_config_id) REFERENCES route_configs(id)
 	);
+
+	CREATE TABLE IF NOT EXISTS config_schedule_exceptions (
+		config_id INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL,
+		PRIMARY KEY (config_id, date),
+		FOREIGN KEY (config_id) REFERENCES route_configs(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS config_schedules (
+		config_id INTEGER NOT NULL,
+		time TEXT NOT NULL,
+		PRIMARY KEY (config_id, time),
+		FOREIGN KEY (config_id) REFERENCES route_configs(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS arrival_aggregates (
+		route_config_id INTEGER NOT NULL,
+		bucket DATETIME NOT NULL,
+		arrival_count INTEGER NOT NULL DEFAULT 0,
+		boarding_sum INTEGER NOT NULL DEFAULT 0,
+		boarding_count INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (route_config_id, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		statement TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS collection_heartbeats (
+		route_config_id INTEGER NOT NULL,
+		minute DATETIME NOT NULL,
+		PRIMARY KEY (route_config_id, minute)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bus_arrivals_arrival_time ON bus_arrivals(arrival_time);
+	CREATE INDEX IF NOT EXISTS idx_bus_arrivals_config_time ON bus_arrivals(route_config_id, arrival_time);
+	CREATE INDEX IF NOT EXISTS idx_bus_arrivals_bus_time ON bus_arrivals(bus_number, arrival_time);
 	`
 	_, err := a.db.Exec(schema)
 	if err != nil {
 		log.Printf("Failed to init schema: %v", err)
 	}
+
+	applied, err := repository.AppliedMigrations(a.db)
+	if err != nil {
+		log.Printf("Failed to read applied migrations: %v", err)
+		return
+	}
+	done := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+	}
+
+	// Migration N is columnMigrations[N-1]. SQLite has no ADD COLUMN IF NOT EXISTS,
+	// so on databases upgraded before versions were tracked a migration fails with
+	// "duplicate column name"; it is then just recorded as applied.
+	for i, migration := range columnMigrations {
+		version := i + 1
+		if done[version] {
+			continue
+		}
+		if _, err := a.db.Exec(migration); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			log.Printf("Failed to run migration %d %q: %v", version, migration, err)
+			continue
+		}
+		if err := repository.RecordMigration(a.db, version, migration); err != nil {
+			log.Printf("Failed to record migration %d: %v", version, err)
+		}
+	}
+}
+
+// Data migrations are one-off rewrites of stored rows. They are recorded in
+// schema_migrations under negative versions, so they run once but don't count
+// towards the schema version.
+const (
+	plateDataMigration = -1
+	timeDataMigration  = -2
+)
+
+// runDataMigration runs fn unless the data migration version is already recorded
+func (a *App) runDataMigration(version int, name string, fn func() (int, error)) {
+	applied, err := repository.AppliedMigrations(a.db)
+	if err != nil {
+		log.Printf("Failed to read applied migrations: %v", err)
+		return
+	}
+	for _, m := range applied {
+		if m.Version == version {
+			return
+		}
+	}
+
+	n, err := fn()
+	if err != nil {
+		log.Printf("Failed data migration %q: %v", name, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Data migration %q updated %d rows", name, n)
+	}
+	if err := repository.RecordMigration(a.db, version, name); err != nil {
+		log.Printf("Failed to record data migration %q: %v", name, err)
+	}
+}
+
+// schemaIndexes lists the indexes created by runInitSchema, checked by RepairDatabase
+var schemaIndexes = []string{
+	"idx_bus_arrivals_arrival_time",
+	"idx_bus_arrivals_config_time",
+	"idx_bus_arrivals_bus_time",
+}
+
+// columnMigrations adds columns introduced after the initial schema
+var columnMigrations = []string{
+	`ALTER TABLE route_configs ADD COLUMN expected_headway_min INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE bus_arrivals ADD COLUMN is_suspect BOOLEAN NOT NULL DEFAULT 0`,
+	`ALTER TABLE bus_arrivals ADD COLUMN raw_bus_number TEXT`,
+	`ALTER TABLE route_configs ADD COLUMN deleted_at DATETIME`,
+	`ALTER TABLE route_configs ADD COLUMN interval_ms INTEGER`,
+	`ALTER TABLE route_configs ADD COLUMN route_type TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE bus_arrivals ADD COLUMN route_name TEXT`,
+	`ALTER TABLE route_configs ADD COLUMN route_group TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE route_configs ADD COLUMN record_approach BOOLEAN NOT NULL DEFAULT 0`,
+	`ALTER TABLE bus_arrivals ADD COLUMN approach_path TEXT`,
+	`ALTER TABLE route_configs ADD COLUMN stop_type TEXT NOT NULL DEFAULT 'mixed'`,
+	`ALTER TABLE route_configs ADD COLUMN region TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE bus_arrivals ADD COLUMN retry_count INTEGER`,
+	`ALTER TABLE bus_arrivals ADD COLUMN direction TEXT`,
+	`ALTER TABLE bus_arrivals ADD COLUMN is_scheduled BOOLEAN NOT NULL DEFAULT 0`,
+	`ALTER TABLE bus_arrivals ADD COLUMN approach_seconds INTEGER`,
+	`ALTER TABLE route_configs ADD COLUMN display_name TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE bus_arrivals ADD COLUMN utc_offset_min INTEGER`,
+	`ALTER TABLE route_configs ADD COLUMN min_record_gap_sec INTEGER`,
+	`ALTER TABLE route_configs ADD COLUMN record_mode TEXT NOT NULL DEFAULT 'pass'`,
+	`ALTER TABLE bus_arrivals ADD COLUMN record_mode TEXT NOT NULL DEFAULT 'pass'`,
+	`ALTER TABLE bus_arrivals ADD COLUMN pass_station_seq INTEGER`,
+	`ALTER TABLE bus_arrivals ADD COLUMN poll_interval_ms INTEGER`,
 }
 
 // --- Bindings for Settings ---
@@ -153,11 +374,21 @@ func (a *App) GetSettings() *config.AppSettings {
 	return a.settings
 }
 
+// UpdateSettings sets the basic settings. The single startHour-endHour window
+// replaces any multiple windows set with SaveSettings.
 func (a *App) UpdateSettings(storagePath, serviceKey string, startHour, endHour, intervalMs int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	window := model.TimeWindow{StartHour: startHour, EndHour: endHour}
+	if startHour < 0 || startHour > 23 || endHour < 0 || endHour > 23 {
+		return fmt.Errorf("invalid collection window %s: hours must be 0-23", window)
+	}
 	a.settings.StoragePath = storagePath
 	a.settings.ServiceKey = serviceKey
 	a.settings.StartHour = startHour
 	a.settings.EndHour = endHour
+	a.settings.Windows = nil
 	a.settings.IntervalMs = intervalMs
 
 	if err := config.SaveAppSettings(a.settings); err != nil {
@@ -167,19 +398,46 @@ func (a *App) UpdateSettings(storagePath, serviceKey string, startHour, endHour,
 	return a.initializeServices()
 }
 
+// SaveSettings replaces all settings, including the options UpdateSettings doesn't cover
+func (a *App) SaveSettings(settings *config.AppSettings) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if settings == nil {
+		return fmt.Errorf("settings are required")
+	}
+	if err := settings.NormalizeWindows(); err != nil {
+		return err
+	}
+	a.settings = settings
+
+	if err := config.SaveAppSettings(a.settings); err != nil {
+		return err
+	}
+
+	return a.initializeServices()
+}
+
 // --- Bindings for Collector Control ---
 
 func (a *App) StartCollection() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	if a.collector == nil {
 		return fmt.Errorf("app not initialized. Please check settings.")
 	}
 	return a.collector.Start(a.ctx)
 }
 
-func (a *App) StopCollection() {
+func (a *App) StopCollection() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
 	if a.collector != nil {
 		a.collector.Stop()
 	}
+	return nil
 }
 
 func (a *App) GetCollectionStatus() bool {
@@ -189,6 +447,77 @@ func (a *App) GetCollectionStatus() bool {
 	return a.collector.IsRunning()
 }
 
+// GetSessionRecordCount returns how many arrivals were saved since collection last started.
+// Changes are pushed live as "session-record-count" events.
+func (a *App) GetSessionRecordCount() (int64, error) {
+	if a.collector == nil {
+		return 0, fmt.Errorf("system not initialized")
+	}
+	return a.collector.SessionRecordCount(), nil
+}
+
+// ListActiveCollectors returns the IDs of the configs currently being polled
+func (a *App) ListActiveCollectors() ([]int64, error) {
+	if a.collector == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.collector.ActiveCollectors(), nil
+}
+
+// StopCollector stops collecting one config for this session without changing
+// its stored active flag; StartCollector resumes it
+func (a *App) StopCollector(configID int64) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.collector == nil {
+		return fmt.Errorf("system not initialized")
+	}
+	a.collector.StopCollector(configID)
+	return nil
+}
+
+// StartCollector resumes a config stopped with StopCollector
+func (a *App) StartCollector(configID int64) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.collector == nil {
+		return fmt.Errorf("system not initialized")
+	}
+	a.collector.StartCollector(configID)
+	return nil
+}
+
+// GetConfigStatus lists the collection state of each active config, e.g. configs
+// skipped because no API client serves their region, with the last error each hit
+func (a *App) GetConfigStatus() ([]collector.ConfigStatus, error) {
+	if a.collector == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.collector.ConfigStatuses(), nil
+}
+
+// GetStorageStatus reports whether collection is paused because the database can't be
+// written (disk full, read-only or a detached drive). Changes are also sent as the
+// "storage-status" event.
+func (a *App) GetStorageStatus() (collector.StorageStatus, error) {
+	if a.collector == nil {
+		return collector.StorageStatus{}, fmt.Errorf("system not initialized")
+	}
+	return a.collector.StorageStatus(), nil
+}
+
+// GetCollectionHealth shows, per active config, how the polls of the last hour went:
+// returned buses, returned nothing, failed or were skipped by the time window.
+// Configs whose polls all failed come first.
+func (a *App) GetCollectionHealth() ([]collector.ConfigHealth, error) {
+	if a.collector == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.collector.CollectionHealth(), nil
+}
+
 // --- Bindings for Data ---
 
 func (a *App) SearchRoutes(keyword string) ([]model.RouteInfo, error) {
@@ -205,6 +534,82 @@ func (a *App) GetRouteStations(routeID string, region string) ([]model.RouteStat
 	return a.busService.GetRouteStations(a.ctx, routeID, region)
 }
 
+// GetRouteStationsAnnotated returns a route's stations, each marked with the config monitoring it
+func (a *App) GetRouteStationsAnnotated(routeID string, region string) ([]model.AnnotatedStation, error) {
+	if a.busService == nil || a.configRepo == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+
+	stations, err := a.busService.GetRouteStations(a.ctx, routeID, region)
+	if err != nil {
+		return nil, err
+	}
+
+	configs, err := a.configRepo.FindByRoute(routeID)
+	if err != nil {
+		return nil, err
+	}
+	byStation := make(map[string]*model.RouteConfig)
+	for _, cfg := range configs {
+		// Prefer an active config when a station has several
+		if existing, ok := byStation[cfg.StationID]; !ok || (!existing.IsActive && cfg.IsActive) {
+			byStation[cfg.StationID] = cfg
+		}
+	}
+
+	result := make([]model.AnnotatedStation, len(stations))
+	for i, st := range stations {
+		result[i] = model.AnnotatedStation{RouteStation: st}
+		if cfg, ok := byStation[strconv.Itoa(st.StationID)]; ok {
+			id := cfg.ID
+			result[i].Monitored = true
+			result[i].ConfigID = &id
+			result[i].IsActive = cfg.IsActive
+		}
+	}
+	return result, nil
+}
+
+// GetRoutePath returns a route's stations as map points in route order, for drawing
+// the route line. X/Y from the APIs are WGS84 longitude/latitude; stations given
+// without them are still listed, flagged Missing, so the caller can bridge the gap.
+func (a *App) GetRoutePath(routeID string, region string) ([]model.LatLng, error) {
+	if a.busService == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+
+	stations, err := a.busService.GetRouteStations(a.ctx, routeID, region)
+	if err != nil {
+		return nil, err
+	}
+	stations = slices.Clone(stations)
+	slices.SortStableFunc(stations, func(x, y model.RouteStation) int { return x.StationSeq - y.StationSeq })
+
+	path := make([]model.LatLng, len(stations))
+	for i, st := range stations {
+		path[i] = model.LatLng{
+			StationID:   st.StationID,
+			StationName: st.StationName,
+			StationSeq:  st.StationSeq,
+		}
+		if st.X == 0 || st.Y == 0 {
+			path[i].Missing = true
+			continue
+		}
+		path[i].Lat = st.Y
+		path[i].Lng = st.X
+	}
+	return path, nil
+}
+
+// GetLiveBusLocations returns the current positions of all buses on a route, deduplicated by plate
+func (a *App) GetLiveBusLocations(routeID string, region string) ([]model.BusLocation, error) {
+	if a.liveLocations == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.liveLocations.Get(a.ctx, routeID, region)
+}
+
 func (a *App) SearchStations(keyword string) ([]model.StationInfo, error) {
 	if a.busService == nil {
 		return nil, fmt.Errorf("system not initialized")
@@ -219,6 +624,43 @@ func (a *App) GetStationRoutes(stationID string, region string) ([]service.Stati
 	return a.busService.GetStationRoutes(a.ctx, stationID, region)
 }
 
+// GetStationRoutesWithSeats lists the routes at a station along with the seats
+// currently left on each route's next bus, to help pick a route to monitor
+func (a *App) GetStationRoutesWithSeats(stationID string, region string) ([]service.StationRouteSeats, error) {
+	if a.busService == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.busService.GetStationRoutesWithSeats(a.ctx, stationID, region)
+}
+
+// ClearRouteStationCache forgets cached route station lists, e.g. after a route was rerouted
+func (a *App) ClearRouteStationCache() error {
+	if a.busService == nil {
+		return fmt.Errorf("system not initialized")
+	}
+	a.busService.ClearRouteStationCache()
+	return nil
+}
+
+// DetectRegion tells whether a route or station ID (kind "route" or "station")
+// belongs to Gyeonggi ("경기") or Incheon ("인천"), for configs saved without a region
+func (a *App) DetectRegion(kind string, id int) (string, error) {
+	if a.busService == nil {
+		return "", fmt.Errorf("system not initialized")
+	}
+	region, err := a.busService.DetectRegion(a.ctx, kind, id)
+	return string(region), err
+}
+
+// PreviewDirection shows which direction (상행/하행/회차) a config for this route/station
+// would monitor, along with the detected turn point, before the config is created
+func (a *App) PreviewDirection(routeID, stationID, region string) (*service.DirectionPreview, error) {
+	if a.busService == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+	return a.busService.PreviewDirection(a.ctx, routeID, stationID, region)
+}
+
 func (a *App) GetConfigs() ([]*model.RouteConfig, error) {
 	if a.configRepo == nil {
 		return nil, fmt.Errorf("DB not initialized")
@@ -226,11 +668,79 @@ func (a *App) GetConfigs() ([]*model.RouteConfig, error) {
 	return a.configRepo.FindAll()
 }
 
+// GetDeletedConfigs returns the soft-deleted configs, whose arrivals are kept
+// until they are purged with PurgeConfig
+func (a *App) GetDeletedConfigs() ([]*model.RouteConfig, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.FindDeleted()
+}
+
+// GetConfigsWithStats returns all configs with their record count, last recorded
+// arrival and, for configs being collected, the last error collection hit
+func (a *App) GetConfigsWithStats() ([]*model.RouteConfigWithStats, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	configs, err := a.configRepo.FindAllWithStats()
+	if err != nil || a.collector == nil {
+		return configs, err
+	}
+
+	statuses := make(map[int64]collector.ConfigStatus)
+	for _, status := range a.collector.ConfigStatuses() {
+		statuses[status.ConfigID] = status
+	}
+	for _, cfg := range configs {
+		if status, ok := statuses[cfg.ID]; ok {
+			cfg.LastError = status.LastError
+			cfg.LastErrorAt = status.LastErrorAt
+		}
+	}
+	return configs, nil
+}
+
+// CreateConfig registers a new config after checking that the station is on the route
 func (a *App) CreateConfig(cfg *model.RouteConfig) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	return a.createConfig(cfg, true)
+}
+
+// CreateConfigUnchecked registers a config without the route/station check, for
+// routes whose station list in the API is incomplete
+func (a *App) CreateConfigUnchecked(cfg *model.RouteConfig) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	return a.createConfig(cfg, false)
+}
+
+func (a *App) createConfig(cfg *model.RouteConfig, validate bool) error {
 	if a.configRepo == nil {
 		return fmt.Errorf("DB not initialized")
 	}
 
+	if cfg.Region == "" {
+		a.fillRegion(cfg)
+	}
+
+	if validate {
+		if err := a.checkStationOnRoute(cfg.RouteID, cfg.StationID, cfg.Region); err != nil {
+			return err
+		}
+	}
+
+	if cfg.StopType != "" && !model.ValidStopType(cfg.StopType) {
+		return fmt.Errorf("invalid stop type %q", cfg.StopType)
+	}
+	if cfg.RecordMode != "" && !model.ValidRecordMode(cfg.RecordMode) {
+		return fmt.Errorf("invalid record mode %q", cfg.RecordMode)
+	}
+
 	// Ensure always active on registration
 	cfg.IsActive = true
 
@@ -249,66 +759,1596 @@ func (a *App) CreateConfig(cfg *model.RouteConfig) error {
 	return nil
 }
 
-func (a *App) DeleteConfig(id int64) error {
-	if a.configRepo == nil {
-		return fmt.Errorf("DB not initialized")
+// CreateConfigsForRoute creates a config for every station on a route, in route order,
+// skipping stations that already have one. direction ("상행", "하행") limits it to the
+// stations in that direction; "" takes all. Returns the number of configs created.
+func (a *App) CreateConfigsForRoute(routeID, region, direction string) (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
 	}
-	return a.configRepo.Delete(id)
-}
-
-func (a *App) ToggleConfig(id int64, active bool) error {
 	if a.configRepo == nil {
-		return fmt.Errorf("DB not initialized")
+		return 0, fmt.Errorf("DB not initialized")
 	}
-	return a.configRepo.UpdateStatus(id, active)
-}
-
-func (a *App) GetArrivals(routeID, stationID, fromDate, toDate string, page, limit int) (map[string]interface{}, error) {
-	if a.busRepo == nil {
-		return nil, fmt.Errorf("DB not initialized")
+	if a.busService == nil {
+		return 0, fmt.Errorf("system not initialized")
 	}
 
-	filter := model.BusArrivalFilter{
-		RouteID:   routeID,
-		StationID: stationID,
-		Page:      page,
-		Limit:     limit,
+	stations, directions, err := a.busService.StationDirections(a.ctx, routeID, region)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load stations of route %s: %w", routeID, err)
+	}
+	if len(stations) == 0 {
+		return 0, fmt.Errorf("route %s has no stations", routeID)
 	}
 
-	loc, _ := time.LoadLocation("Asia/Seoul")
-	if fromDate != "" {
-		t, _ := time.ParseInLocation("2006-01-02", fromDate, loc)
-		filter.FromDate = &t
+	existing, err := a.configRepo.FindByRoute(routeID)
+	if err != nil {
+		return 0, err
 	}
-	if toDate != "" {
-		t, _ := time.ParseInLocation("2006-01-02", toDate, loc)
-		endOfDay := t.Add(24*time.Hour - time.Second)
-		filter.ToDate = &endOfDay
+	monitored := make(map[string]bool, len(existing))
+	for _, cfg := range existing {
+		monitored[cfg.StationID] = true
 	}
 
-	arrivals, total, err := a.busRepo.FindByFilter(filter)
+	// The station list has no route details; take them from the first station
+	routeName, routeType := routeID, ""
+	info, err := a.busService.FindRouteAtStation(a.ctx, routeID, strconv.Itoa(stations[0].StationID), region)
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to look up name of route %s: %v", routeID, err)
+	} else if info != nil {
+		routeName, routeType = info.RouteName, info.RouteTypeName
 	}
 
-	// Ensure data is an empty array instead of null
-	if arrivals == nil {
-		arrivals = []*model.BusArrivalWithConfig{}
+	created := 0
+	for i, st := range stations {
+		stationID := strconv.Itoa(st.StationID)
+		// Loop routes list some stations twice; keep the first pass
+		if monitored[stationID] || (direction != "" && directions[i] != direction) {
+			continue
+		}
+		monitored[stationID] = true
+
+		cfg := &model.RouteConfig{
+			RouteID:     routeID,
+			RouteName:   routeName,
+			StationID:   stationID,
+			StationName: st.StationName,
+			Direction:   directions[i],
+			RouteType:   routeType,
+			StaOrder:    st.StationSeq,
+			IsActive:    true,
+			Region:      string(service.ParseRegion(region)),
+		}
+		if err := a.configRepo.Create(cfg); err != nil {
+			return created, err
+		}
+		created++
 	}
 
-	return map[string]interface{}{
-		"data":  arrivals,
-		"total": total,
-		"page":  page,
-		"limit": limit,
-	}, nil
+	if created > 0 && a.collector != nil {
+		if !a.collector.IsRunning() {
+			a.collector.Start(a.ctx)
+		}
+		a.collector.NotifySync()
+	}
+	return created, nil
 }
 
-func (a *App) GetTrip(arrivalID int64) ([]*model.BusArrivalWithConfig, error) {
-	if a.busRepo == nil {
-		return nil, fmt.Errorf("DB not initialized")
+// fillRegion sets the region of a config created without one from its route ID.
+// The region stays empty (collected as 경기) if it can't be determined.
+func (a *App) fillRegion(cfg *model.RouteConfig) {
+	if a.busService == nil {
+		return
+	}
+	id, err := strconv.Atoi(cfg.RouteID)
+	if err != nil {
+		return
+	}
+	region, err := a.busService.DetectRegion(a.ctx, "route", id)
+	if err != nil {
+		log.Printf("Failed to detect region of route %s: %v", cfg.RouteID, err)
+		return
+	}
+	cfg.Region = string(region)
+}
+
+// errStationCheck starts the CreateConfig errors that CreateConfigUnchecked can get
+// past: the station isn't in the route's station list, or the list couldn't be loaded.
+// The frontend only offers the unchecked fallback for errors with this prefix.
+var errStationCheck = errors.New("station check failed")
+
+// checkStationOnRoute verifies via the route's station list that a config would see buses
+func (a *App) checkStationOnRoute(routeID, stationID, region string) error {
+	if a.busService == nil {
+		return fmt.Errorf("system not initialized")
+	}
+
+	stations, err := a.busService.GetRouteStations(a.ctx, routeID, region)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load the stations of route %s: %w", errStationCheck, routeID, err)
+	}
+	for _, st := range stations {
+		if strconv.Itoa(st.StationID) == stationID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: station %s is not on route %s", errStationCheck, stationID, routeID)
+}
+
+// PreviewImportConfigs reads a JSON config list (as returned by GetConfigs) and reports
+// which configs would be created, which already exist and which conflict, without changing anything
+func (a *App) PreviewImportConfigs(path string) (*model.ImportPlan, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	imported, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.configRepo.PlanImport(imported)
+}
+
+// ImportConfigs creates the new configs from a JSON config list. Existing and
+// conflicting configs are left untouched. Returns the applied plan.
+func (a *App) ImportConfigs(path string) (*model.ImportPlan, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	plan, err := a.PreviewImportConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cfg := range plan.New {
+		cfg.IsActive = true
+		if err := a.configRepo.Create(cfg); err != nil {
+			return nil, fmt.Errorf("failed to import config %s/%s: %w", cfg.RouteName, cfg.StationName, err)
+		}
+	}
+
+	if len(plan.New) > 0 && a.collector != nil && a.collector.IsRunning() {
+		a.collector.NotifySync()
+	}
+	return plan, nil
+}
+
+// readConfigFile loads a JSON array of route configs, ignoring ids and timestamps
+func readConfigFile(path string) ([]*model.RouteConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var configs []*model.RouteConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return validConfigs(configs), nil
+}
+
+// validConfigs drops entries without a route or station and clears ids
+func validConfigs(configs []*model.RouteConfig) []*model.RouteConfig {
+	valid := configs[:0]
+	for _, cfg := range configs {
+		if cfg == nil || cfg.RouteID == "" || cfg.StationID == "" {
+			continue
+		}
+		cfg.ID = 0
+		valid = append(valid, cfg)
+	}
+	return valid
+}
+
+// DeleteConfig removes a config from the list but keeps its recorded arrivals
+func (a *App) DeleteConfig(id int64) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if err := a.configRepo.Delete(id); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// PurgeConfig permanently deletes a config and all of its arrivals
+func (a *App) PurgeConfig(id int64) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if err := a.configRepo.HardDelete(id); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// PreviewPurge shows what deleting arrivals older than the given number of days would
+// remove: the row count, the affected date range and a per-config breakdown. The
+// cutoff is midnight Asia/Seoul that many days before today. Nothing is deleted.
+func (a *App) PreviewPurge(days int) (*model.PurgePreview, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive")
+	}
+
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	now := time.Now().In(loc)
+	cutoff := time.Date(now.Year(), now.Month(), now.Day()-days, 0, 0, 0, 0, loc)
+
+	preview, err := a.busRepo.PreviewPurge(cutoff)
+	if err != nil {
+		return nil, err
+	}
+	preview.Days = days
+	return preview, nil
+}
+
+// GetStaOrderConflicts reports configs of a route with a duplicate or missing sta_order
+func (a *App) GetStaOrderConflicts(routeID string) ([]model.ConflictPair, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.FindStaOrderConflicts(routeID)
+}
+
+// RederiveStaOrders resets the sta_order of a route's configs from the current
+// station list of the API. A station the route passes twice takes the pass in the
+// config's direction. Returns how many configs changed.
+func (a *App) RederiveStaOrders(routeID, region string) (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	if a.configRepo == nil {
+		return 0, fmt.Errorf("DB not initialized")
+	}
+	if a.busService == nil {
+		return 0, fmt.Errorf("system not initialized")
+	}
+
+	stations, directions, err := a.busService.StationDirections(a.ctx, routeID, region)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load stations of route %s: %w", routeID, err)
+	}
+	configs, err := a.configRepo.FindByRoute(routeID)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, cfg := range configs {
+		staOrder := 0
+		for i, st := range stations {
+			if strconv.Itoa(st.StationID) != cfg.StationID {
+				continue
+			}
+			if staOrder == 0 || directions[i] == cfg.Direction {
+				staOrder = st.StationSeq
+			}
+			if directions[i] == cfg.Direction {
+				break
+			}
+		}
+		if staOrder == 0 {
+			log.Printf("Station %s (%s) is no longer on route %s; sta_order left as is",
+				cfg.StationID, cfg.StationName, routeID)
+			continue
+		}
+		if staOrder == cfg.StaOrder {
+			continue
+		}
+		if err := a.configRepo.UpdateStaOrder(cfg.ID, staOrder); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// DeduplicateConfigs collapses configs for the same route, station and direction into
+// the oldest one, moving their arrivals over. Returns how many configs were removed.
+func (a *App) DeduplicateConfigs() (int, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	if a.configRepo == nil {
+		return 0, fmt.Errorf("DB not initialized")
+	}
+	n, err := a.configRepo.DeduplicateConfigs()
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 && a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return n, nil
+}
+
+func (a *App) ToggleConfig(id int64, active bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.UpdateStatus(id, active)
+}
+
+func (a *App) SetExpectedHeadway(id int64, minutes int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if minutes < 0 {
+		return fmt.Errorf("expected headway must not be negative")
+	}
+	return a.configRepo.UpdateExpectedHeadway(id, minutes)
+}
+
+// SetConfigInterval overrides the polling interval of a single config.
+// 0 reverts to the global interval.
+func (a *App) SetConfigInterval(id int64, intervalMs int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if intervalMs < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+
+	var override *int
+	if intervalMs > 0 {
+		if intervalMs < minConfigIntervalMs {
+			return fmt.Errorf("interval must be at least %dms", minConfigIntervalMs)
+		}
+		override = &intervalMs
+	}
+	if err := a.configRepo.UpdateInterval(id, override); err != nil {
+		return err
+	}
+
+	// Running collectors pick up the new interval on the next sync
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// SetMinRecordGap sets the minimum seconds between two records of the same bus at a
+// config, to match the route's real minimum headway; 0 goes back to the global setting
+func (a *App) SetMinRecordGap(id int64, seconds int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if seconds < 0 {
+		return fmt.Errorf("minimum gap must not be negative")
+	}
+
+	var override *int
+	if seconds > 0 {
+		override = &seconds
+	}
+	if err := a.configRepo.UpdateMinRecordGap(id, override); err != nil {
+		return err
+	}
+
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// minConfigIntervalMs keeps per-config and temporary interval overrides from hammering the API
+const minConfigIntervalMs = 1000
+
+// SetRouteGroup puts a config into a named group of sibling routes; "" removes it
+func (a *App) SetRouteGroup(id int64, group string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.UpdateRouteGroup(id, strings.TrimSpace(group))
+}
+
+// SetDisplayName sets a nickname shown for the config's route in place of the API's
+// route name; "" goes back to the route name
+func (a *App) SetDisplayName(id int64, name string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	name = strings.TrimSpace(name)
+	return a.configRepo.Update(id, nil, nil, &name)
+}
+
+// SetCollectionInterval temporarily changes the global polling interval of the running
+// collectors without re-initializing services. The saved setting is untouched; use
+// ResetCollectionInterval (or restart) to go back to it.
+func (a *App) SetCollectionInterval(ms int) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.collector == nil {
+		return fmt.Errorf("system not initialized")
+	}
+	if ms < minConfigIntervalMs {
+		return fmt.Errorf("interval must be at least %dms", minConfigIntervalMs)
+	}
+	a.collector.SetInterval(ms)
+	return nil
+}
+
+// ResetCollectionInterval restores the polling interval from the saved settings
+func (a *App) ResetCollectionInterval() error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.collector == nil {
+		return fmt.Errorf("system not initialized")
+	}
+	a.collector.SetInterval(a.cfg.Collector.IntervalMs)
+	return nil
+}
+
+// SetRecordApproach enables storing each arrival's approach path (stops away and seats
+// over time) for a config. Off by default because it makes rows much larger.
+func (a *App) SetRecordApproach(id int64, enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if err := a.configRepo.UpdateRecordApproach(id, enabled); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// SetStopType marks a config as a boarding, alighting (near a terminal) or mixed stop,
+// which decides how its seat delta is labelled and which metric stats lead with
+func (a *App) SetStopType(id int64, stopType string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if !model.ValidStopType(stopType) {
+		return fmt.Errorf("invalid stop type %q", stopType)
+	}
+	if err := a.configRepo.UpdateStopType(id, stopType); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// SetRecordMode switches a config between recording each bus once it has passed,
+// with seats before and after ("pass"), and recording it when it is first 0-1
+// stops away, with the seats on arrival ("arrival"). Rows keep the mode they were
+// recorded under.
+func (a *App) SetRecordMode(id int64, mode string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if !model.ValidRecordMode(mode) {
+		return fmt.Errorf("invalid record mode %q", mode)
+	}
+	if err := a.configRepo.UpdateRecordMode(id, mode); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// SetScheduleException enables (collect all day) or disables (skip the day) collection
+// for a config on a date given as YYYY-MM-DD, overriding the normal time window
+func (a *App) SetScheduleException(configID int64, date string, enabled bool) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	cfg, err := a.configRepo.FindByID(configID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("config %d not found", configID)
+	}
+	if err := a.configRepo.SetScheduleException(configID, date, enabled); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// RemoveScheduleException restores the normal time window for a config on a date
+func (a *App) RemoveScheduleException(configID int64, date string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+	if err := a.configRepo.RemoveScheduleException(configID, date); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// GetScheduleExceptions lists the dated exceptions of a config
+func (a *App) GetScheduleExceptions(configID int64) ([]model.ScheduleException, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.FindScheduleExceptions(configID)
+}
+
+// SetScheduleTimes sets a config's timetable ("HH:MM" times, empty to clear). Scheduled
+// times without an observed pass are then recorded as scheduled-only arrivals.
+func (a *App) SetScheduleTimes(configID int64, times []string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	if a.configRepo == nil {
+		return fmt.Errorf("DB not initialized")
+	}
+
+	normalized := make([]string, 0, len(times))
+	for _, s := range times {
+		t, err := time.Parse("15:04", strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid time %q, expected HH:MM", s)
+		}
+		normalized = append(normalized, t.Format("15:04"))
+	}
+	if err := a.configRepo.SetScheduleTimes(configID, normalized); err != nil {
+		return err
+	}
+	if a.collector != nil {
+		a.collector.NotifySync()
+	}
+	return nil
+}
+
+// GetScheduleTimes returns a config's timetable
+func (a *App) GetScheduleTimes(configID int64) ([]string, error) {
+	if a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.configRepo.FindScheduleTimes(configID)
+}
+
+// GetApproachPath returns how a recorded bus approached the station
+func (a *App) GetApproachPath(arrivalID int64) ([]model.ApproachPoint, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.busRepo.GetApproachPath(arrivalID)
+}
+
+// GetSeatsByApproachDistance shows the average reported seats by stops away from the
+// station, from the approach paths recorded for a config
+func (a *App) GetSeatsByApproachDistance(configID int64, fromDate, toDate string) (map[int]float64, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetSeatsByApproachDistance(configID, from, to)
+}
+
+// GetHeadwayAlerts checks active configs with an expected headway over the last
+// windowMinutes and reports gaps longer than twice the expected value.
+// A "headway-alert" event is emitted when any are found.
+func (a *App) GetHeadwayAlerts(windowMinutes int) ([]model.HeadwayAlert, error) {
+	if a.configRepo == nil || a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	if windowMinutes <= 0 {
+		windowMinutes = 120
+	}
+
+	configs, err := a.configRepo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-time.Duration(windowMinutes) * time.Minute)
+
+	alerts := []model.HeadwayAlert{}
+	for _, cfg := range configs {
+		gaps, err := a.busRepo.FindHeadwayGaps(cfg, since, nil, 2)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, gaps...)
+	}
+
+	if len(alerts) > 0 {
+		runtime.EventsEmit(a.ctx, "headway-alert", alerts)
+	}
+	return alerts, nil
+}
+
+// ArrivalsPage is one page of arrivals as returned by GetArrivals and its variants
+type ArrivalsPage struct {
+	Data  []*model.BusArrivalWithConfig `json:"data"` // Never null
+	Total int64                         `json:"total"`
+	Page  int                           `json:"page"`
+	Limit int                           `json:"limit"`
+}
+
+func (a *App) GetArrivals(routeID, stationID, fromDate, toDate string, page, limit int) (*ArrivalsPage, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	// The list shows timetable-only markers too, flagged by is_scheduled
+	filter := model.BusArrivalFilter{
+		RouteID:          routeID,
+		StationID:        stationID,
+		Page:             page,
+		Limit:            limit,
+		IncludeScheduled: true,
+	}
+
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	if fromDate != "" {
+		t, _ := time.ParseInLocation("2006-01-02", fromDate, loc)
+		filter.FromDate = &t
+	}
+	if toDate != "" {
+		t, _ := time.ParseInLocation("2006-01-02", toDate, loc)
+		endOfDay := t.Add(24*time.Hour - time.Second)
+		filter.ToDate = &endOfDay
+	}
+
+	return a.findArrivalsPage(filter)
+}
+
+// GetArrivalsByPeriods is GetArrivals over several non-contiguous periods (e.g. weekdays only)
+func (a *App) GetArrivalsByPeriods(routeID, stationID string, periods []model.DatePeriod, page, limit int) (*ArrivalsPage, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	ranges, err := parseDatePeriods(periods)
+	if err != nil {
+		return nil, err
+	}
+
+	// Timetable-only markers are listed like in GetArrivals
+	return a.findArrivalsPage(model.BusArrivalFilter{
+		RouteID:          routeID,
+		StationID:        stationID,
+		Ranges:           ranges,
+		Page:             page,
+		Limit:            limit,
+		IncludeScheduled: true,
+	})
+}
+
+// parseDatePeriods converts frontend "2006-01-02" periods to inclusive whole-day ranges in KST
+func parseDatePeriods(periods []model.DatePeriod) ([]model.DateRange, error) {
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	ranges := make([]model.DateRange, 0, len(periods))
+	for _, p := range periods {
+		from, err := time.ParseInLocation("2006-01-02", p.From, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period start %q: %w", p.From, err)
+		}
+		to, err := time.ParseInLocation("2006-01-02", p.To, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period end %q: %w", p.To, err)
+		}
+		ranges = append(ranges, model.DateRange{From: from, To: to.Add(24*time.Hour - time.Second)})
+	}
+	return ranges, nil
+}
+
+func (a *App) findArrivalsPage(filter model.BusArrivalFilter) (*ArrivalsPage, error) {
+	page, limit := filter.Page, filter.Limit
+
+	arrivals, total, err := a.busRepo.FindByFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure data is an empty array instead of null
+	if arrivals == nil {
+		arrivals = []*model.BusArrivalWithConfig{}
+	}
+
+	return &ArrivalsPage{Data: arrivals, Total: total, Page: page, Limit: limit}, nil
+}
+
+// ArrivalsCursorPage is one batch of arrivals as returned by GetArrivalsCursor
+type ArrivalsCursorPage struct {
+	Data       []*model.BusArrivalWithConfig `json:"data"`       // Never null
+	NextCursor string                        `json:"nextCursor"` // "" after the last batch
+	Limit      int                           `json:"limit"`
+}
+
+// GetArrivalsCursor is GetArrivals for infinite scroll. Pass "" as cursor for the first
+// batch, then the returned NextCursor until it comes back empty.
+func (a *App) GetArrivalsCursor(routeID, stationID, fromDate, toDate, cursor string, limit int) (*ArrivalsCursorPage, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 {
+		limit = 20
+	}
+
+	var afterTime time.Time
+	var afterID int64
+	if cursor != "" {
+		if afterTime, afterID, err = parseArrivalCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Timetable-only markers are listed like in GetArrivals
+	filter := model.BusArrivalFilter{RouteID: routeID, StationID: stationID, FromDate: from, ToDate: to, IncludeScheduled: true}
+	arrivals, err := a.busRepo.FindByFilterCursor(filter, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if arrivals == nil {
+		arrivals = []*model.BusArrivalWithConfig{}
+	} else if len(arrivals) == limit {
+		last := arrivals[len(arrivals)-1]
+		nextCursor = last.ArrivalTime.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(last.ID, 10)
+	}
+
+	return &ArrivalsCursorPage{Data: arrivals, NextCursor: nextCursor, Limit: limit}, nil
+}
+
+// parseArrivalCursor decodes a "<RFC3339 time>|<id>" cursor from GetArrivalsCursor
+func parseArrivalCursor(cursor string) (time.Time, int64, error) {
+	timePart, idPart, ok := strings.Cut(cursor, "|")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	t, err := time.Parse(time.RFC3339Nano, timePart)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor time: %w", err)
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor id: %w", err)
+	}
+	return t, id, nil
+}
+
+// GetDatesWithData lists the Korean dates with at least one arrival for a config,
+// so the history calendar can disable empty days
+func (a *App) GetDatesWithData(configID int64) ([]string, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	return a.busRepo.GetDatesWithData(configID, loc)
+}
+
+// GetServiceSpan returns the first and last observed bus per day for a config.
+// fromDate/toDate are optional "2006-01-02" dates.
+func (a *App) GetServiceSpan(configID int64, fromDate, toDate string) (*model.ServiceSpanReport, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	spans, err := a.busRepo.GetServiceSpan(configID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ServiceSpanReport{
+		Spans:   spans,
+		Note:    "Observed first/last passes only; limited by the collection time window and by periods when collection was stopped.",
+		Windows: a.settings.CollectionWindows(),
+	}, nil
+}
+
+// GetCoverageGaps returns the periods between fromDate and toDate ("2006-01-02", empty
+// for today in Asia/Seoul) in which a config was not polled, read from the collection heartbeats.
+// The range is clipped to now.
+func (a *App) GetCoverageGaps(configID int64, fromDate, toDate string) (*model.CoverageReport, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	today := time.Now().In(loc).Format("2006-01-02")
+	if fromDate == "" {
+		fromDate = today
+	}
+	if toDate == "" {
+		toDate = today
+	}
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	end := *to
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+
+	minutes, err := a.busRepo.FindHeartbeats(configID, *from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Polls slower than once a minute leave minutes without a heartbeat in between
+	step := time.Minute
+	if a.cfg != nil {
+		if interval := time.Duration(a.cfg.Collector.IntervalMs) * time.Millisecond; interval > step {
+			step = interval
+		}
+	}
+
+	return &model.CoverageReport{
+		Gaps:       coverageGaps(minutes, *from, end, step),
+		Heartbeats: len(minutes),
+		Recording:  a.settings.RecordHeartbeats,
+		Windows:    a.settings.CollectionWindows(),
+	}, nil
+}
+
+// coverageGaps returns the stretches of [from, to] not covered by a heartbeat minute,
+// ignoring those no longer than step
+func coverageGaps(minutes []time.Time, from, to time.Time, step time.Duration) []model.CoverageGap {
+	gaps := []model.CoverageGap{}
+	addGap := func(start, end time.Time) {
+		if end.Sub(start) > step {
+			gaps = append(gaps, model.CoverageGap{Start: start, End: end, Minutes: int(end.Sub(start).Minutes())})
+		}
+	}
+
+	covered := from
+	for _, minute := range minutes {
+		addGap(covered, minute)
+		covered = minute.Add(time.Minute)
+	}
+	if !to.Before(covered) {
+		addGap(covered, to)
+	}
+	return gaps
+}
+
+// GenerateDailyReport summarizes one date ("2006-01-02") across all active configs:
+// arrivals, average boarding, busiest hour and observed service span per config.
+// A date without data gives a report with no config entries.
+func (a *App) GenerateDailyReport(date string) (*model.DailyReport, error) {
+	if a.configRepo == nil || a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(date, date)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, fmt.Errorf("date is required")
+	}
+
+	configs, err := a.configRepo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.DailyReport{
+		Date:    date,
+		Configs: []model.DailyReportEntry{},
+		Windows: a.settings.CollectionWindows(),
+	}
+	for _, cfg := range configs {
+		spans, err := a.busRepo.GetServiceSpan(cfg.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		stats, err := a.busRepo.GetStatistics(model.BusArrivalFilter{
+			ConfigID: cfg.ID,
+			FromDate: from,
+			ToDate:   to,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entry := model.DailyReportEntry{
+			ConfigID:      cfg.ID,
+			RouteName:     cfg.Label(),
+			StationName:   cfg.StationName,
+			Direction:     cfg.Direction,
+			StopType:      cfg.StopType,
+			TotalArrivals: spans[0].TotalArrivals,
+			FirstArrival:  spans[0].FirstArrival,
+			LastArrival:   spans[0].LastArrival,
+		}
+		if stats != nil {
+			entry.AvgBoarding = stats.AvgBoarding
+			if len(stats.BusiestHours) > 0 {
+				entry.BusiestHour = stats.BusiestHours[0]
+			}
+		}
+		report.Configs = append(report.Configs, entry)
+		report.TotalArrivals += entry.TotalArrivals
+	}
+
+	return report, nil
+}
+
+// GetBoardingByRouteType compares arrival and boarding stats across route types (express, regular, ...).
+// With weighted, averages are weighted by each arrival's poll interval.
+func (a *App) GetBoardingByRouteType(fromDate, toDate string, weighted bool) (map[string]model.BusArrivalStats, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.busRepo.GetBoardingByRouteType(from, to, weighted)
+}
+
+// GetTopBoardings returns the n arrivals with the most passengers boarding in a period
+func (a *App) GetTopBoardings(routeID, stationID, fromDate, toDate string, n int) ([]*model.BusArrivalWithConfig, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.busRepo.GetTopBoardings(routeID, stationID, from, to, n)
+}
+
+// GetGroupArrivals is GetArrivals across every config in a route group
+func (a *App) GetGroupArrivals(group, fromDate, toDate string, page, limit int) (*ArrivalsPage, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	if group == "" {
+		return nil, fmt.Errorf("route group is required")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.findArrivalsPage(model.BusArrivalFilter{
+		RouteGroup: group,
+		FromDate:   from,
+		ToDate:     to,
+		Page:       page,
+		Limit:      limit,
+	})
+}
+
+// GetGroupStatistics aggregates arrival and boarding stats across a route group. With
+// weighted, averages are weighted by each arrival's poll interval so periods collected
+// at different intervals count alike; the sampling density is reported either way.
+func (a *App) GetGroupStatistics(group, fromDate, toDate string, weighted bool) (*model.BusArrivalStats, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	if group == "" {
+		return nil, fmt.Errorf("route group is required")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.busRepo.GetStatistics(model.BusArrivalFilter{RouteGroup: group, FromDate: from, ToDate: to, WeightByInterval: weighted})
+}
+
+// GetDailyBoarding returns total passengers boarding per day for a config.
+// fromDate/toDate are optional "2006-01-02" dates.
+func (a *App) GetDailyBoarding(configID int64, fromDate, toDate string) (*model.DailyBoardingReport, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := a.busRepo.GetDailyBoarding(configID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DailyBoardingReport{
+		Days:    days,
+		Note:    "Boarding inferred from seat changes of recorded buses only; bounded by the collection time window and by periods when collection was stopped.",
+		Windows: a.settings.CollectionWindows(),
+	}, nil
+}
+
+// GetHourBoardingCorrelation returns how strongly boarding correlates with the hour
+// of day for a config, a quick indicator of a rush-hour pattern at the stop
+func (a *App) GetHourBoardingCorrelation(configID int64, fromDate, toDate string) (*model.HourBoardingCorrelation, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	r, n, err := a.busRepo.GetHourBoardingCorrelation(configID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &model.HourBoardingCorrelation{Coefficient: r, SampleSize: n}, nil
+}
+
+// GetRegularityScore returns how evenly a config's buses are spaced: the coefficient
+// of variation of its headways, a compact reliability figure for dashboards
+func (a *App) GetRegularityScore(configID int64, fromDate, toDate string) (*model.RegularityScore, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	cv, n, err := a.busRepo.GetRegularityScore(configID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RegularityScore{CoefficientOfVariation: cv, SampleSize: n}, nil
+}
+
+// GetRetryStats shows how many polls a config's arrivals needed to get seats_after
+// and how many timed out, to tune the polling interval and retry window
+func (a *App) GetRetryStats(configID int64, fromDate, toDate string) ([]model.RetryBucket, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetRetryStats(configID, from, to)
+}
+
+// GetDirectionSplit counts a config's arrivals per direction of travel, showing
+// whether a stop served both ways is used mostly in one direction
+func (a *App) GetDirectionSplit(configID int64, fromDate, toDate string) (map[string]int, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetDirectionSplit(configID, from, to)
+}
+
+// GetStatsByDirectionHour returns a station's average boarding per hour for each
+// direction, e.g. inbound filling up in the morning and outbound in the evening
+func (a *App) GetStatsByDirectionHour(stationID, fromDate, toDate string) (map[string][24]float64, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetStatsByDirectionHour(stationID, from, to)
+}
+
+// GetBoardingSeries returns boarding over time for a chart, averaged into at most
+// maxPoints buckets (default 500) when there are more arrivals than that. A downsampled
+// point is stamped with the start of its bucket.
+func (a *App) GetBoardingSeries(configID int64, fromDate, toDate string, maxPoints int) ([]model.SeriesPoint, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	if maxPoints <= 0 {
+		maxPoints = 500
+	}
+	return a.busRepo.GetBoardingSeries(configID, from, to, maxPoints)
+}
+
+// GetArrivalAggregates returns the time buckets recorded for a config in
+// aggregate-only mode (arrival count and average boarding per bucket)
+func (a *App) GetArrivalAggregates(configID int64, fromDate, toDate string) ([]model.ArrivalAggregate, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.FindAggregates(configID, from, to)
+}
+
+// GetHourlyCountsVsExpected compares the arrivals seen in each hour with the number
+// expected, flagging hours with a large shortfall (missed passes or disruption).
+// expectedPerHour <= 0 derives it from the config's expected headway.
+func (a *App) GetHourlyCountsVsExpected(configID int64, expectedPerHour float64, fromDate, toDate string) ([]model.HourlyComparison, error) {
+	if a.busRepo == nil || a.configRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	if expectedPerHour <= 0 {
+		cfg, err := a.configRepo.FindByID(configID)
+		if err != nil {
+			return nil, err
+		}
+		if cfg == nil || cfg.ExpectedHeadwayMin <= 0 {
+			return nil, fmt.Errorf("config %d has no expected headway; pass the expected arrivals per hour", configID)
+		}
+		expectedPerHour = 60 / float64(cfg.ExpectedHeadwayMin)
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetHourlyCountsVsExpected(configID, expectedPerHour, from, to)
+}
+
+// GetApproachDurationStats returns how long a config's buses typically take from first
+// appearing in the arrival list to passing the station, for tuning retry windows
+func (a *App) GetApproachDurationStats(configID int64) (*model.ApproachDurationStats, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.busRepo.GetApproachDurationStats(configID)
+}
+
+// GetBusiestStations ranks the monitored stations of a route by how many people board there
+func (a *App) GetBusiestStations(routeID, fromDate, toDate string) ([]model.StationRank, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	return a.busRepo.GetBusiestStations(routeID, from, to)
+}
+
+// GetRouteSnapshot shows how full buses were across a route's monitored stations at
+// a moment: at is a Korean time like "2025-03-03 08:00", and arrivals within
+// windowMinutes (default 30) either side of it are included
+func (a *App) GetRouteSnapshot(routeID, at string, windowMinutes int) (*model.RouteSnapshot, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	t, err := parseContextTime(at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot time: %w", err)
+	}
+	if windowMinutes <= 0 {
+		windowMinutes = 30
+	}
+	return a.busRepo.GetRouteSnapshot(routeID, t, time.Duration(windowMinutes)*time.Minute)
+}
+
+// parseDateBounds parses optional "2006-01-02" dates into an inclusive whole-day KST range
+func parseDateBounds(fromDate, toDate string) (*time.Time, *time.Time, error) {
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	var from, to *time.Time
+	if fromDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", fromDate, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid from date %q: %w", fromDate, err)
+		}
+		from = &t
+	}
+	if toDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", toDate, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid to date %q: %w", toDate, err)
+		}
+		endOfDay := t.Add(24*time.Hour - time.Second)
+		to = &endOfDay
+	}
+	return from, to, nil
+}
+
+// GetBusHistory returns one vehicle's pass history across all configs, oldest first
+func (a *App) GetBusHistory(busNumber, fromDate, toDate string, page, limit int) (*ArrivalsPage, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivals, total, err := a.busRepo.FindByBusNumber(busNumber, from, to, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if arrivals == nil {
+		arrivals = []*model.BusArrivalWithConfig{}
+	}
+
+	return &ArrivalsPage{Data: arrivals, Total: total, Page: page, Limit: limit}, nil
+}
+
+// GetTrip returns the trip an arrival belongs to. windowHours (default 6) caps the
+// search either side of it; orderTolerance lets sta_order drop by that much within a
+// trip, for loop routes; a gap over maxGapMinutes (default 60) between consecutive
+// arrivals splits trips. Pass 0 for the defaults.
+func (a *App) GetTrip(arrivalID int64, windowHours, orderTolerance, maxGapMinutes int) ([]*model.BusArrivalWithConfig, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	return a.busRepo.GetTripByArrivalID(arrivalID, model.TripOptions{
+		WindowHours:    windowHours,
+		OrderTolerance: orderTolerance,
+		MaxGapMinutes:  maxGapMinutes,
+	})
+}
+
+// GetSystemOverview returns totals across all configs for the landing page
+func (a *App) GetSystemOverview() (*model.SystemOverview, error) {
+	if a.configRepo == nil || a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	var overview model.SystemOverview
+	var err error
+	overview.TotalConfigs, overview.ActiveConfigs, err = a.configRepo.CountConfigs()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.busRepo.GetArrivalTotals(&overview, time.Now().Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+
+	return &overview, nil
+}
+
+// RecomputeBoarding re-checks stored seat values for a config (0 = all) and flags
+// impossible ones as suspect. Returns how many rows changed.
+func (a *App) RecomputeBoarding(configID int64) (int64, error) {
+	if err := a.checkWritable(); err != nil {
+		return 0, err
+	}
+	if a.busRepo == nil {
+		return 0, fmt.Errorf("DB not initialized")
+	}
+	return a.busRepo.RecomputeBoarding(configID)
+}
+
+// exportManifestEntry describes one CSV inside the export zip
+type exportManifestEntry struct {
+	ConfigID    int64  `json:"configId"`
+	File        string `json:"file"`
+	RouteID     string `json:"routeId"`
+	RouteName   string `json:"routeName"`
+	StationID   string `json:"stationId"`
+	StationName string `json:"stationName"`
+	Rows        int64  `json:"rows"`
+}
+
+// ExportAllConfigsZip writes one CSV per config plus a manifest.json into a zip
+// chosen via the save dialog. opts can round times and hash or drop plates for
+// sharing. Returns the saved path, or "" if cancelled.
+func (a *App) ExportAllConfigsZip(opts ExportOptions) (string, error) {
+	if a.busRepo == nil || a.configRepo == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	an, err := newExportAnonymizer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "전체 데이터 내보내기",
+		DefaultFilename: fmt.Sprintf("bus_history_%s.zip", time.Now().Format("20060102")),
+		Filters:         []runtime.FileFilter{{DisplayName: "ZIP (*.zip)", Pattern: "*.zip"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	configs, err := a.configRepo.FindAll()
+	if err != nil {
+		return "", err
+	}
+
+	// Build the archive next to the destination and only move it into place once
+	// complete, so a failed export leaves no truncated zip (or an older export intact)
+	tmpPath := path + ".part"
+	defer os.Remove(tmpPath)
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	n, err := writeConfigsZip(f, a.busRepo, configs, an)
+	if err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to save export file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	log.Printf("Exported %d configs to %s", n, path)
+	return path, nil
+}
+
+// writeConfigsZip writes one CSV per config plus a manifest.json listing them into
+// a zip archive, returning the number of configs written
+func writeConfigsZip(out io.Writer, busRepo repository.BusStore, configs []*model.RouteConfig, an *exportAnonymizer) (int, error) {
+	zw := zip.NewWriter(out)
+	manifest := make([]exportManifestEntry, 0, len(configs))
+	for _, cfg := range configs {
+		name := exportFileName(cfg)
+		w, err := zw.Create(name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add %s to zip: %w", name, err)
+		}
+		rows, err := writeArrivalsCSV(w, busRepo, cfg.ID, an)
+		if err != nil {
+			return 0, fmt.Errorf("failed to export config %d: %w", cfg.ID, err)
+		}
+		manifest = append(manifest, exportManifestEntry{
+			ConfigID:    cfg.ID,
+			File:        name,
+			RouteID:     cfg.RouteID,
+			RouteName:   cfg.Label(),
+			StationID:   cfg.StationID,
+			StationName: cfg.StationName,
+			Rows:        rows,
+		})
+	}
+
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return 0, fmt.Errorf("failed to add manifest to zip: %w", err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return 0, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish zip: %w", err)
+	}
+	return len(manifest), nil
+}
+
+// RepairDatabase checks the database file, removes arrivals whose config row is gone and
+// recreates missing indexes. Collection is stopped during the repair and services are
+// re-initialized afterwards; the collector is restarted if it was running.
+func (a *App) RepairDatabase() (*model.RepairReport, error) {
+	if err := a.checkWritable(); err != nil {
+		return nil, err
+	}
+	if a.db == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	wasRunning := a.collector != nil && a.collector.IsRunning()
+	if a.collector != nil {
+		a.collector.Stop()
+	}
+
+	report, err := a.repairDatabase()
+
+	// Restart collection however the repair ended; a.collector may have been
+	// replaced by initializeServices by then
+	if wasRunning && a.collector != nil {
+		if startErr := a.collector.Start(a.ctx); startErr != nil && err == nil {
+			return report, fmt.Errorf("repair finished but collection failed to restart: %w", startErr)
+		}
+	}
+	return report, err
+}
+
+// repairDatabase does the work of RepairDatabase with collection stopped
+func (a *App) repairDatabase() (*model.RepairReport, error) {
+	report := &model.RepairReport{RecreatedIndexes: []string{}}
+
+	problems, err := repository.IntegrityCheck(a.db)
+	if err != nil {
+		return nil, err
+	}
+	report.IntegrityOK = len(problems) == 0
+	report.IntegrityErrors = problems
+
+	if report.MissingIndexes, err = repository.MissingIndexes(a.db, schemaIndexes); err != nil {
+		return nil, err
+	}
+	if err := removeOrphanArrivals(a.db, report); err != nil {
+		return nil, err
+	}
+
+	// Re-initializing re-runs the schema, which recreates missing indexes
+	if err := a.initializeServices(); err != nil {
+		return nil, fmt.Errorf("failed to re-initialize after repair: %w", err)
+	}
+
+	stillMissing, err := repository.MissingIndexes(a.db, report.MissingIndexes)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range report.MissingIndexes {
+		if !slices.Contains(stillMissing, name) {
+			report.RecreatedIndexes = append(report.RecreatedIndexes, name)
+		}
+	}
+
+	log.Printf("Database repair: integrity_ok=%v, missing_indexes=%v, recreated=%v, orphans_removed=%d, orphan_cleanup_skipped=%v",
+		report.IntegrityOK, report.MissingIndexes, report.RecreatedIndexes, report.OrphansRemoved, report.OrphanCleanupSkipped)
+	return report, nil
+}
+
+// removeOrphanArrivals deletes the arrivals whose config row is gone, unless the
+// integrity check failed: in a damaged file a row can look orphaned only because
+// an index or page is broken, and it should survive until a backup is restored
+func removeOrphanArrivals(db *sql.DB, report *model.RepairReport) error {
+	if !report.IntegrityOK {
+		report.OrphanCleanupSkipped = true
+		return nil
+	}
+	n, err := repository.DeleteOrphanArrivals(db)
+	if err != nil {
+		return err
+	}
+	report.OrphansRemoved = n
+	return nil
+}
+
+// GetRecentLogs returns up to n of the latest log lines at or above level ("info",
+// "warn", "error"; "" = all), oldest first (n <= 0 = all kept). New lines are
+// pushed live in batches as "log-lines" events.
+func (a *App) GetRecentLogs(n int, level string) ([]LogLine, error) {
+	if err := checkLogLevel(level); err != nil {
+		return nil, err
+	}
+	return a.logs.recent(n, level), nil
+}
+
+// GetClockDriftReport checks for a wrong host clock or a timezone change while
+// collecting: arrivals recorded under more than one UTC offset, and arrivals stored
+// more than maxSkewMinutes (<= 0 = 30) away from their arrival_time
+func (a *App) GetClockDriftReport(maxSkewMinutes int) (*model.ClockDriftReport, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	if maxSkewMinutes <= 0 {
+		maxSkewMinutes = 30
+	}
+	return a.busRepo.GetClockDriftReport(maxSkewMinutes, 100)
+}
+
+// GetSchemaInfo reports the database schema version and which migrations have run
+func (a *App) GetSchemaInfo() (*model.SchemaInfo, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+
+	applied, err := repository.AppliedMigrations(a.db)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &model.SchemaInfo{
+		LatestVersion: len(columnMigrations),
+		Applied:       applied,
+	}
+	done := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+		info.Version = max(info.Version, m.Version)
+	}
+	for i := range columnMigrations {
+		if !done[i+1] {
+			info.Pending = append(info.Pending, i+1)
+		}
 	}
-	return a.busRepo.GetTripByArrivalID(arrivalID)
+	return info, nil
 }
 
 // SelectFolder opens a native directory dialog and returns the selected path
@@ -0,0 +1,648 @@
+package main
+
+import (
+	"archive/zip"
+	"bus_history/internal/config"
+	"bus_history/internal/model"
+	"bus_history/internal/repository"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// newTestApp returns an App with its repositories on a fresh in-memory database
+func newTestApp(tb testing.TB) *App {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	tb.Cleanup(func() { db.Close() })
+
+	a := &App{db: db}
+	a.runInitSchema()
+	a.busRepo = repository.NewBusRepository(db)
+	a.configRepo = repository.NewConfigRepository(db)
+	return a
+}
+
+// denseRouteApp returns an App on an in-memory database holding a week of a busy
+// loop route with a config at each of its stations. Every bus loops the route from
+// 05:00 to midnight, 2 minutes between stations with a 10 minute layover per loop.
+// It also returns the ID of an arrival mid-route.
+func denseRouteApp(b *testing.B, stations, buses int) (*App, int64) {
+	a := newTestApp(b)
+	db := a.db
+
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for s := 1; s <= stations; s++ {
+		if _, err := tx.Exec(`INSERT INTO route_configs (route_id, route_name, station_id, station_name, sta_order)
+			VALUES ('200000115', '7770', ?, ?, ?)`, fmt.Sprint(228000000+s), fmt.Sprintf("station %d", s), s); err != nil {
+			b.Fatal(err)
+		}
+	}
+	insert, err := tx.Prepare(`INSERT INTO bus_arrivals (route_config_id, bus_number, arrival_time, seats_before, seats_after)
+		VALUES (?, ?, ?, 30, 25)`)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	kst, _ := time.LoadLocation("Asia/Seoul")
+	day0 := time.Date(2025, 3, 3, 5, 0, 0, 0, kst)
+	loop := time.Duration(stations)*2*time.Minute + 10*time.Minute
+	for day := range 7 {
+		start, end := day0.AddDate(0, 0, day), day0.AddDate(0, 0, day).Add(19*time.Hour)
+		for bus := range buses {
+			plate := fmt.Sprintf("70아%04d", bus)
+			// Buses leave the first station evenly spread over one loop
+			for t := start.Add(loop * time.Duration(bus) / time.Duration(buses)); t.Before(end); t = t.Add(loop) {
+				for s := range stations {
+					if _, err := insert.Exec(s+1, plate, t.Add(time.Duration(s)*2*time.Minute)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+	insert.Close()
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+
+	var id int64
+	at := day0.AddDate(0, 0, 3).Add(7 * time.Hour)
+	if err := db.QueryRow(`SELECT id FROM bus_arrivals WHERE route_config_id = ? AND arrival_time >= ?
+		ORDER BY arrival_time LIMIT 1`, stations/2, at).Scan(&id); err != nil {
+		b.Fatal(err)
+	}
+	return a, id
+}
+
+func BenchmarkGetTripDenseRoute(b *testing.B) {
+	const stations = 40
+	a, id := denseRouteApp(b, stations, 20)
+
+	for _, windowHours := range []int{2, 6, 12} {
+		b.Run(fmt.Sprintf("window=%dh", windowHours), func(b *testing.B) {
+			for b.Loop() {
+				trip, err := a.GetTrip(id, windowHours, 0, 0)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(trip) != stations {
+					b.Fatalf("trip has %d arrivals, want %d", len(trip), stations)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteConfigArrivals(t *testing.T) {
+	tests := []struct {
+		name         string
+		remove       func(a *App, id int64) error
+		wantArrivals int64 // Arrivals of the config still listed
+		wantRows     int   // bus_arrivals rows left for the config
+		wantBeats    int   // collection_heartbeats rows left for the config
+		wantDeleted  int   // Configs listed by GetDeletedConfigs
+	}{
+		{"delete keeps history", (*App).DeleteConfig, 2, 2, 2, 1},
+		{"purge removes arrivals with the config", (*App).PurgeConfig, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true}
+			if err := a.configRepo.Create(cfg); err != nil {
+				t.Fatal(err)
+			}
+			for i := range 2 {
+				arrival := &model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234", ArrivalTime: time.Now().Add(time.Duration(-i) * time.Hour)}
+				if err := a.busRepo.Create(arrival); err != nil {
+					t.Fatal(err)
+				}
+				if err := a.busRepo.RecordHeartbeat(cfg.ID, arrival.ArrivalTime.Truncate(time.Minute)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := tt.remove(a, cfg.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			configs, err := a.GetConfigs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(configs) != 0 {
+				t.Errorf("GetConfigs still lists %d configs", len(configs))
+			}
+			deleted, err := a.GetDeletedConfigs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deleted) != tt.wantDeleted {
+				t.Errorf("GetDeletedConfigs lists %d configs, want %d", len(deleted), tt.wantDeleted)
+			}
+
+			_, total, err := a.busRepo.FindByFilter(model.BusArrivalFilter{RouteID: cfg.RouteID, Page: 1, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.wantArrivals {
+				t.Errorf("FindByFilter lists %d arrivals, want %d", total, tt.wantArrivals)
+			}
+
+			var rows int
+			if err := a.db.QueryRow("SELECT COUNT(*) FROM bus_arrivals WHERE route_config_id = ?", cfg.ID).Scan(&rows); err != nil {
+				t.Fatal(err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("%d arrival rows left, want %d", rows, tt.wantRows)
+			}
+
+			var beats int
+			if err := a.db.QueryRow("SELECT COUNT(*) FROM collection_heartbeats WHERE route_config_id = ?", cfg.ID).Scan(&beats); err != nil {
+				t.Fatal(err)
+			}
+			if beats != tt.wantBeats {
+				t.Errorf("%d heartbeat rows left, want %d", beats, tt.wantBeats)
+			}
+		})
+	}
+}
+
+func TestFindHeadwayGaps(t *testing.T) {
+	now := time.Now()
+	ago := func(min int) time.Time { return now.Add(-time.Duration(min) * time.Minute) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name        string
+		arrivals    []time.Time
+		to          *time.Time
+		wantGaps    int
+		wantOngoing bool
+	}{
+		{"no arrivals", nil, nil, 0, false},
+		{"no arrivals in a closed window", nil, ptr(ago(10)), 0, false},
+		{"regular service", []time.Time{ago(50), ago(40), ago(30), ago(20), ago(10)}, nil, 0, false},
+		{"gap between arrivals", []time.Time{ago(100), ago(60), ago(50), ago(5)}, nil, 2, false},
+		{"open window since last arrival", []time.Time{ago(110), ago(100), ago(90)}, nil, 1, true},
+		{"window ending in the future", []time.Time{ago(110), ago(100), ago(90)}, ptr(now.Add(time.Hour)), 1, true},
+		{"closed window", []time.Time{ago(110), ago(100), ago(90)}, ptr(ago(30)), 0, false},
+		{"arrivals after a closed window", []time.Time{ago(110), ago(100), ago(50)}, ptr(ago(60)), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true, ExpectedHeadwayMin: 10}
+			if err := a.configRepo.Create(cfg); err != nil {
+				t.Fatal(err)
+			}
+			for _, at := range tt.arrivals {
+				if err := a.busRepo.Create(&model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234", ArrivalTime: at}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			alerts, err := a.busRepo.FindHeadwayGaps(cfg, ago(120), tt.to, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(alerts) != tt.wantGaps {
+				t.Fatalf("got %d alerts, want %d: %+v", len(alerts), tt.wantGaps, alerts)
+			}
+			if ongoing := len(alerts) > 0 && alerts[len(alerts)-1].Ongoing; ongoing != tt.wantOngoing {
+				t.Errorf("last alert ongoing = %v, want %v", ongoing, tt.wantOngoing)
+			}
+		})
+	}
+}
+
+func TestCoverageGaps(t *testing.T) {
+	from := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Minute)
+	at := func(mins ...int) []time.Time {
+		var minutes []time.Time
+		for _, m := range mins {
+			minutes = append(minutes, from.Add(time.Duration(m)*time.Minute))
+		}
+		return minutes
+	}
+
+	tests := []struct {
+		name     string
+		minutes  []time.Time
+		step     time.Duration
+		wantGaps [][2]int // Start and end minutes after from
+	}{
+		{"no heartbeats", nil, time.Minute, [][2]int{{0, 10}}},
+		{"fully covered", at(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10), time.Minute, nil},
+		{"gap in the middle", at(0, 1, 2, 6, 7, 8, 9, 10), time.Minute, [][2]int{{3, 6}}},
+		{"late start and early stop", at(3, 4, 5, 6), time.Minute, [][2]int{{0, 3}, {7, 10}}},
+		{"one missed minute", at(0, 1, 3, 4, 5, 6, 7, 8, 9, 10), time.Minute, nil},
+		{"slow polling", at(0, 2, 4, 6, 8, 10), 2 * time.Minute, nil},
+		{"slow polling with a gap", at(0, 2, 8, 10), 2 * time.Minute, [][2]int{{3, 8}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps := coverageGaps(tt.minutes, from, to, tt.step)
+			if len(gaps) != len(tt.wantGaps) {
+				t.Fatalf("got %d gaps, want %d: %+v", len(gaps), len(tt.wantGaps), gaps)
+			}
+			for i, want := range tt.wantGaps {
+				start, end := from.Add(time.Duration(want[0])*time.Minute), from.Add(time.Duration(want[1])*time.Minute)
+				if !gaps[i].Start.Equal(start) || !gaps[i].End.Equal(end) || gaps[i].Minutes != want[1]-want[0] {
+					t.Errorf("gap %d = %s-%s (%d min), want %s-%s", i, gaps[i].Start.Format("15:04"), gaps[i].End.Format("15:04"), gaps[i].Minutes, start.Format("15:04"), end.Format("15:04"))
+				}
+			}
+		})
+	}
+}
+
+func TestWriteConfigsZip(t *testing.T) {
+	tests := []struct {
+		name     string
+		arrivals []int // Arrivals per config
+	}{
+		{"no configs", nil},
+		{"config without arrivals", []int{0}},
+		{"several configs", []int{3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			var configs []*model.RouteConfig
+			for i, n := range tt.arrivals {
+				cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: strconv.Itoa(228000001 + i), StationName: "test", IsActive: true}
+				if err := a.configRepo.Create(cfg); err != nil {
+					t.Fatal(err)
+				}
+				configs = append(configs, cfg)
+				for j := range n {
+					arrival := &model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234", ArrivalTime: time.Now().Add(time.Duration(-j) * time.Hour)}
+					if err := a.busRepo.Create(arrival); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			an, err := newExportAnonymizer(ExportOptions{PlateMode: PlateKeep})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			n, err := writeConfigsZip(&buf, a.busRepo, configs, an)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(configs) {
+				t.Errorf("wrote %d configs, want %d", n, len(configs))
+			}
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(zr.File) != len(configs)+1 {
+				t.Fatalf("zip has %d files, want %d", len(zr.File), len(configs)+1)
+			}
+			r, err := zr.Open("manifest.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			var manifest []exportManifestEntry
+			if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+				t.Fatal(err)
+			}
+			if len(manifest) != len(configs) {
+				t.Fatalf("manifest lists %d configs, want %d", len(manifest), len(configs))
+			}
+			for i, entry := range manifest {
+				if entry.Rows != int64(tt.arrivals[i]) {
+					t.Errorf("manifest entry %s has %d rows, want %d", entry.File, entry.Rows, tt.arrivals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteWorkbook(t *testing.T) {
+	seats := func(n int) *int { return &n }
+	at := time.Date(2026, 10, 15, 8, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name        string
+		arrivals    []model.BusArrival
+		wantRows    int // Arrival rows below the header
+		wantDays    int
+		wantHeatmap int // Dates with a boarding count
+	}{
+		{"no arrivals", nil, 0, 0, 0},
+		{"without seat counts", []model.BusArrival{{ArrivalTime: at}}, 1, 1, 0},
+		{"two days", []model.BusArrival{
+			{ArrivalTime: at, SeatsBefore: seats(40), SeatsAfter: seats(30)},
+			{ArrivalTime: at.Add(time.Hour), SeatsBefore: seats(40), SeatsAfter: seats(35)},
+			{ArrivalTime: at.Add(24 * time.Hour), SeatsBefore: seats(20), SeatsAfter: seats(10)},
+		}, 3, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true}
+			if err := a.configRepo.Create(cfg); err != nil {
+				t.Fatal(err)
+			}
+			for _, arrival := range tt.arrivals {
+				arrival.RouteConfigID = cfg.ID
+				arrival.BusNumber = "70아1234"
+				if err := a.busRepo.Create(&arrival); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			an, err := newExportAnonymizer(ExportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			count, err := writeWorkbook(&buf, a.busRepo, cfg, nil, nil, an)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != int64(tt.wantRows) {
+				t.Errorf("wrote %d arrivals, want %d", count, tt.wantRows)
+			}
+
+			wb, err := excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer wb.Close()
+			for sheet, want := range map[string]int{"Arrivals": tt.wantRows, "Daily": tt.wantDays, "Heatmap": tt.wantHeatmap} {
+				rows, err := wb.GetRows(sheet)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(rows) != want+1 {
+					t.Errorf("sheet %s has %d rows, want %d and a header", sheet, len(rows), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeQueriesInOtherZones(t *testing.T) {
+	kst := time.FixedZone("KST", 9*60*60)
+	arrivedAt := time.Date(2026, 10, 15, 8, 30, 0, 0, kst)
+
+	tests := []struct {
+		name string
+		zone *time.Location
+	}{
+		{"KST", kst},
+		{"UTC", time.UTC},
+		{"behind UTC", time.FixedZone("EST", -5*60*60)},
+		{"ahead of KST", time.FixedZone("NZST", 12*60*60)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true}
+			if err := a.configRepo.Create(cfg); err != nil {
+				t.Fatal(err)
+			}
+			arrival := &model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234", ArrivalTime: arrivedAt}
+			if err := a.busRepo.Create(arrival); err != nil {
+				t.Fatal(err)
+			}
+
+			// Windows just around the arrival, in the zone under test, must match it;
+			// windows just before or after it must not
+			at := arrivedAt.In(tt.zone)
+			for _, w := range []struct {
+				from, to time.Time
+				want     bool
+			}{
+				{at.Add(-time.Minute), at.Add(time.Minute), true},
+				{at.Add(-time.Hour), at.Add(-time.Minute), false},
+				{at.Add(time.Minute), at.Add(time.Hour), false},
+			} {
+				_, total, err := a.busRepo.FindByFilter(model.BusArrivalFilter{FromDate: &w.from, ToDate: &w.to})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (total == 1) != w.want {
+					t.Errorf("FindByFilter(%s - %s) found %d arrivals, want match %v", w.from, w.to, total, w.want)
+				}
+
+				observed, err := a.busRepo.HasObservedArrival(cfg.ID, w.from, w.to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if observed != w.want {
+					t.Errorf("HasObservedArrival(%s - %s) = %v, want %v", w.from, w.to, observed, w.want)
+				}
+
+				spans, err := a.busRepo.GetServiceSpan(cfg.ID, &w.from, &w.to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (len(spans) == 1) != w.want {
+					t.Errorf("GetServiceSpan(%s - %s) found %d days, want match %v", w.from, w.to, len(spans), w.want)
+				}
+			}
+
+			missing, err := a.busRepo.FindMissingSeatsAfter(at.Add(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(missing) != 0 {
+				t.Errorf("FindMissingSeatsAfter after the arrival found %d arrivals", len(missing))
+			}
+		})
+	}
+}
+
+func TestStatisticsAtAlightingStop(t *testing.T) {
+	a := newTestApp(t)
+	cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "terminal",
+		StopType: model.StopTypeAlighting, IsActive: true}
+	if err := a.configRepo.Create(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mostly people getting off, plus one arrival where more got on
+	kst := time.FixedZone("KST", 9*60*60)
+	for i, seats := range [][2]int{{10, 30}, {5, 25}, {20, 18}} {
+		before, after := seats[0], seats[1]
+		arrival := &model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234",
+			ArrivalTime: time.Date(2026, 10, 15, 8, i*10, 0, 0, kst), SeatsBefore: &before, SeatsAfter: &after}
+		if err := a.busRepo.Create(arrival); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := a.busRepo.GetStatistics(model.BusArrivalFilter{RouteID: cfg.RouteID, StationID: cfg.StationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PrimaryMetric != "net_alighting" {
+		t.Errorf("PrimaryMetric = %q, want net_alighting", stats.PrimaryMetric)
+	}
+	if want := (20.0 + 20 - 2) / 3; stats.AvgAlighting != want {
+		t.Errorf("AvgAlighting = %v, want %v", stats.AvgAlighting, want)
+	}
+	if want := 2.0 / 3; stats.AvgBoarding != want {
+		t.Errorf("AvgBoarding = %v, want %v", stats.AvgBoarding, want)
+	}
+}
+
+func TestDailyReportPerConfig(t *testing.T) {
+	a := newTestApp(t)
+	a.settings = &config.AppSettings{StartHour: 5, EndHour: 23}
+
+	// Both directions of a route at one station, boarding 10 and 2 a bus
+	kst := time.FixedZone("KST", 9*60*60)
+	boarding := map[int64]float64{}
+	for i, dir := range []struct {
+		direction string
+		before    int
+		after     int
+	}{{"up", 30, 20}, {"down", 30, 28}} {
+		cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test",
+			Direction: dir.direction, IsActive: true}
+		if err := a.configRepo.Create(cfg); err != nil {
+			t.Fatal(err)
+		}
+		boarding[cfg.ID] = float64(dir.before - dir.after)
+		for j := range 2 {
+			before, after := dir.before, dir.after
+			arrival := &model.BusArrival{RouteConfigID: cfg.ID, BusNumber: fmt.Sprintf("70아%d%d", i, j),
+				ArrivalTime: time.Date(2026, 10, 15, 8, 10*j, 0, 0, kst), SeatsBefore: &before, SeatsAfter: &after}
+			if err := a.busRepo.Create(arrival); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	report, err := a.GenerateDailyReport("2026-10-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Configs) != 2 {
+		t.Fatalf("report has %d configs, want 2", len(report.Configs))
+	}
+	for _, entry := range report.Configs {
+		if entry.TotalArrivals != 2 {
+			t.Errorf("config %d: TotalArrivals = %d, want 2", entry.ConfigID, entry.TotalArrivals)
+		}
+		if entry.AvgBoarding != boarding[entry.ConfigID] {
+			t.Errorf("config %d: AvgBoarding = %v, want %v", entry.ConfigID, entry.AvgBoarding, boarding[entry.ConfigID])
+		}
+	}
+}
+
+func TestRemoveOrphanArrivals(t *testing.T) {
+	tests := []struct {
+		name        string
+		integrityOK bool
+		wantRemoved int64
+		wantRows    int
+	}{
+		{"healthy file", true, 1, 0},
+		{"corrupt file keeps orphans", false, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true}
+			if err := a.configRepo.Create(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.busRepo.Create(&model.BusArrival{RouteConfigID: cfg.ID, BusNumber: "70아1234", ArrivalTime: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := a.db.Exec("DELETE FROM route_configs WHERE id = ?", cfg.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			report := &model.RepairReport{IntegrityOK: tt.integrityOK}
+			if err := removeOrphanArrivals(a.db, report); err != nil {
+				t.Fatal(err)
+			}
+			if report.OrphansRemoved != tt.wantRemoved || report.OrphanCleanupSkipped == tt.integrityOK {
+				t.Errorf("report = %+v, want %d removed, skipped %v", report, tt.wantRemoved, !tt.integrityOK)
+			}
+			var rows int
+			if err := a.db.QueryRow("SELECT COUNT(*) FROM bus_arrivals").Scan(&rows); err != nil {
+				t.Fatal(err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("%d arrivals left, want %d", rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestExportsAnonymizePlates(t *testing.T) {
+	const plate = "70아1234"
+	a := newTestApp(t)
+	cfg := &model.RouteConfig{RouteID: "200000115", RouteName: "7770", StationID: "228000001", StationName: "test", IsActive: true}
+	if err := a.configRepo.Create(cfg); err != nil {
+		t.Fatal(err)
+	}
+	arrivedAt := time.Date(2026, 10, 15, 8, 31, 17, 0, time.FixedZone("KST", 9*60*60))
+	if err := a.busRepo.Create(&model.BusArrival{RouteConfigID: cfg.ID, BusNumber: plate, ArrivalTime: arrivedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	writers := map[string]func(w *bytes.Buffer, an *exportAnonymizer) (string, error){
+		"NDJSON": func(w *bytes.Buffer, an *exportAnonymizer) (string, error) {
+			_, err := writeArrivalsNDJSON(w, a.busRepo, cfg, nil, nil, an)
+			return w.String(), err
+		},
+		"XLSX": func(w *bytes.Buffer, an *exportAnonymizer) (string, error) {
+			if _, err := writeWorkbook(w, a.busRepo, cfg, nil, nil, an); err != nil {
+				return "", err
+			}
+			wb, err := excelize.OpenReader(w)
+			if err != nil {
+				return "", err
+			}
+			defer wb.Close()
+			rows, err := wb.GetRows("Arrivals")
+			return fmt.Sprint(rows), err
+		},
+	}
+	for name, write := range writers {
+		for _, mode := range []string{PlateHash, PlateOmit} {
+			t.Run(name+" "+mode, func(t *testing.T) {
+				an, err := newExportAnonymizer(ExportOptions{PlateMode: mode, RoundMinutes: 15})
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				out, err := write(&buf, an)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(out, plate) || strings.Contains(out, "8:31") {
+					t.Errorf("output still holds the plate or the exact time: %s", out)
+				}
+			})
+		}
+	}
+}
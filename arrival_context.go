@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bus_history/internal/model"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// contextTimeLayouts are the timestamp formats accepted in context files;
+// times without an offset are read as Korean time
+var contextTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// ArrivalsWithContextPage is one page of arrivals as returned by GetArrivalsWithContext
+type ArrivalsWithContextPage struct {
+	Data  []model.ArrivalWithContext `json:"data"` // Never null
+	Total int64                      `json:"total"`
+	Page  int                        `json:"page"`
+	Limit int                        `json:"limit"`
+}
+
+// GetArrivalsWithContext is GetArrivals with each arrival matched to the latest sample
+// of an external time series at or before its arrival time, e.g. a weather log.
+// contextPath is a CSV with a "time" column (other columns become values) or a
+// JSON array of objects with a "time" key. Samples older than toleranceMinutes
+// (default 60) before an arrival aren't attached.
+func (a *App) GetArrivalsWithContext(routeID, stationID, fromDate, toDate string, page, limit int, contextPath string, toleranceMinutes int) (*ArrivalsWithContextPage, error) {
+	samples, err := loadContextSamples(contextPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.GetArrivals(routeID, stationID, fromDate, toDate, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if toleranceMinutes <= 0 {
+		toleranceMinutes = 60
+	}
+	return &ArrivalsWithContextPage{
+		Data:  matchContext(result.Data, samples, time.Duration(toleranceMinutes)*time.Minute),
+		Total: result.Total,
+		Page:  result.Page,
+		Limit: result.Limit,
+	}, nil
+}
+
+// matchContext pairs each arrival with the nearest sample at or before it within
+// tolerance. samples must be sorted by time.
+func matchContext(arrivals []*model.BusArrivalWithConfig, samples []model.ContextSample, tolerance time.Duration) []model.ArrivalWithContext {
+	out := make([]model.ArrivalWithContext, len(arrivals))
+	for i, arrival := range arrivals {
+		out[i].BusArrivalWithConfig = arrival
+
+		// First sample after the arrival; the one before it is the candidate
+		idx := sort.Search(len(samples), func(j int) bool {
+			return samples[j].Time.After(arrival.ArrivalTime)
+		})
+		if idx == 0 {
+			continue
+		}
+		if s := &samples[idx-1]; arrival.ArrivalTime.Sub(s.Time) <= tolerance {
+			out[i].Context = s
+		}
+	}
+	return out
+}
+
+// loadContextSamples reads a context file, choosing the format by extension,
+// and returns its samples sorted by time
+func loadContextSamples(path string) ([]model.ContextSample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context file: %w", err)
+	}
+
+	var samples []model.ContextSample
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		samples, err = parseContextJSON(data)
+	} else {
+		samples, err = parseContextCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	return samples, nil
+}
+
+func parseContextCSV(data []byte) ([]model.ContextSample, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse context CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return []model.ContextSample{}, nil
+	}
+
+	header := records[0]
+	timeCol := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), "time") {
+			timeCol = i
+		}
+	}
+	if timeCol < 0 {
+		return nil, fmt.Errorf("context CSV has no time column")
+	}
+
+	samples := make([]model.ContextSample, 0, len(records)-1)
+	for line, rec := range records[1:] {
+		t, err := parseContextTime(rec[timeCol])
+		if err != nil {
+			return nil, fmt.Errorf("context CSV line %d: %w", line+2, err)
+		}
+		values := make(map[string]string, len(rec)-1)
+		for i, v := range rec {
+			if i != timeCol {
+				values[strings.TrimSpace(header[i])] = strings.TrimSpace(v)
+			}
+		}
+		samples = append(samples, model.ContextSample{Time: t, Values: values})
+	}
+	return samples, nil
+}
+
+func parseContextJSON(data []byte) ([]model.ContextSample, error) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse context JSON: %w", err)
+	}
+
+	samples := make([]model.ContextSample, 0, len(rows))
+	for i, row := range rows {
+		raw, _ := row["time"].(string)
+		t, err := parseContextTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("context JSON entry %d: %w", i, err)
+		}
+		values := make(map[string]string, len(row)-1)
+		for k, v := range row {
+			if k != "time" {
+				values[k] = fmt.Sprint(v)
+			}
+		}
+		samples = append(samples, model.ContextSample{Time: t, Values: values})
+	}
+	return samples, nil
+}
+
+func parseContextTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	for _, layout := range contextTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"archive/zip"
+	"bus_history/internal/config"
+	"bus_history/internal/model"
+	"bus_history/internal/repository"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Entry names inside a backup bundle
+const (
+	bundleSettingsFile = "settings.json"
+	bundleConfigsFile  = "configs.json"
+	bundleDBFile       = "bus_history.db"
+)
+
+// ExportBundle saves settings, all configs and, if includeData is set, a snapshot
+// of the database into one zip chosen via the save dialog, for moving a setup to
+// another machine. The service key is left out unless includeServiceKey is set;
+// ImportBundle only uses it where no key is set yet. Returns the saved path, or
+// "" if cancelled.
+func (a *App) ExportBundle(includeData, includeServiceKey bool) (string, error) {
+	if a.configRepo == nil || a.db == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "설정 백업 내보내기",
+		DefaultFilename: fmt.Sprintf("bus_history_backup_%s.zip", time.Now().Format("20060102")),
+		Filters:         []runtime.FileFilter{{DisplayName: "ZIP (*.zip)", Pattern: "*.zip"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	configs, err := a.configRepo.FindAll()
+	if err != nil {
+		return "", err
+	}
+	settings := *a.settings
+	if !includeServiceKey {
+		settings.ServiceKey = ""
+	}
+
+	// Write next to the destination and move into place once complete, like
+	// ExportAllConfigsZip, so a failed export leaves no truncated bundle
+	tmpPath := path + ".part"
+	defer os.Remove(tmpPath)
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if err := writeZipJSON(zw, bundleSettingsFile, settings); err != nil {
+		return "", err
+	}
+	if err := writeZipJSON(zw, bundleConfigsFile, configs); err != nil {
+		return "", err
+	}
+	if includeData {
+		if err := a.addDBSnapshot(zw); err != nil {
+			return "", err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to save bundle file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to save bundle file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("failed to save bundle file: %w", err)
+	}
+
+	log.Printf("Exported bundle with %d configs (data: %v, service key: %v) to %s",
+		len(configs), includeData, includeServiceKey, path)
+	return path, nil
+}
+
+// addDBSnapshot copies a consistent snapshot of the database into the zip.
+// VACUUM INTO is used rather than copying the file so that writes by a running
+// collector can't leave a torn copy.
+func (a *App) addDBSnapshot(zw *zip.Writer) error {
+	tmp, err := os.MkdirTemp("", "bus_history_bundle")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	snapshot := filepath.Join(tmp, bundleDBFile)
+	if _, err := a.db.Exec("VACUUM INTO ?", snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	src, err := os.Open(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to open database snapshot: %w", err)
+	}
+	defer src.Close()
+
+	w, err := zw.Create(bundleDBFile)
+	if err != nil {
+		return fmt.Errorf("failed to add database to bundle: %w", err)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("failed to write database to bundle: %w", err)
+	}
+	return nil
+}
+
+// ImportBundle restores a bundle written by ExportBundle. Settings are applied
+// except the storage path and service key, which stay those of this machine.
+// Where this machine has none set, the bundle's are used instead. If the bundle
+// carries a database it replaces the current one (kept next to it as .bak);
+// otherwise its configs are imported like ImportConfigs, skipping ones that
+// already exist.
+func (a *App) ImportBundle(path string) error {
+	if err := a.checkWritable(); err != nil {
+		return err
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	var settings config.AppSettings
+	if err := readZipJSON(&zr.Reader, bundleSettingsFile, &settings); err != nil {
+		return err
+	}
+	var configs []*model.RouteConfig
+	if err := readZipJSON(&zr.Reader, bundleConfigsFile, &configs); err != nil {
+		return err
+	}
+
+	if a.settings != nil {
+		if a.settings.StoragePath != "" {
+			settings.StoragePath = a.settings.StoragePath
+		}
+		if a.settings.ServiceKey != "" {
+			settings.ServiceKey = a.settings.ServiceKey
+		}
+	}
+	if settings.StoragePath == "" {
+		return fmt.Errorf("set a storage path before importing a bundle")
+	}
+	// Check the settings before touching the database, so a bad bundle can't
+	// leave a restored database running with the old settings
+	if err := settings.NormalizeWindows(); err != nil {
+		return fmt.Errorf("invalid bundle settings: %w", err)
+	}
+
+	var dbEntry *zip.File
+	for _, f := range zr.File {
+		if f.Name == bundleDBFile {
+			dbEntry = f
+		}
+	}
+	if dbEntry != nil {
+		if err := a.restoreDB(dbEntry, filepath.Join(settings.StoragePath, bundleDBFile)); err != nil {
+			return err
+		}
+	}
+
+	// Saving the settings reopens the services, on the restored database if any
+	if err := a.SaveSettings(&settings); err != nil {
+		if dbEntry != nil {
+			if reopenErr := a.initializeServices(); reopenErr != nil {
+				log.Printf("Failed to reopen the database after a failed import: %v", reopenErr)
+			}
+		}
+		return fmt.Errorf("failed to apply bundle settings: %w", err)
+	}
+	if dbEntry != nil {
+		log.Printf("Imported bundle %s including its database", path)
+		return nil
+	}
+
+	plan, err := a.configRepo.PlanImport(validConfigs(configs))
+	if err != nil {
+		return err
+	}
+	for _, cfg := range plan.New {
+		cfg.IsActive = true
+		if err := a.configRepo.Create(cfg); err != nil {
+			return fmt.Errorf("failed to import config %s/%s: %w", cfg.RouteName, cfg.StationName, err)
+		}
+	}
+	if len(plan.New) > 0 && a.collector != nil && a.collector.IsRunning() {
+		a.collector.NotifySync()
+	}
+
+	log.Printf("Imported bundle %s: %d new configs, %d existing, %d conflicts",
+		path, len(plan.New), len(plan.Existing), len(plan.Conflicts))
+	return nil
+}
+
+// restoreDB swaps the database file for the bundle's copy. The copy is extracted
+// and checked next to the current file first, so a bad bundle leaves it untouched;
+// the swap then keeps the current file as .bak and puts it back if the rename
+// fails, reopening the services on it. After a successful swap the services stay
+// stopped for the caller to reopen with the bundle's settings.
+func (a *App) restoreDB(entry *zip.File, dbPath string) error {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return err
+	}
+	tmpPath := dbPath + ".restore"
+	defer os.Remove(tmpPath)
+	if err := extractBundleDB(entry, tmpPath); err != nil {
+		return err
+	}
+
+	if err := a.swapDB(tmpPath, dbPath); err != nil {
+		if a.settings != nil && a.settings.StoragePath != "" {
+			if reopenErr := a.initializeServices(); reopenErr != nil {
+				log.Printf("Failed to reopen the database after a failed restore: %v", reopenErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// extractBundleDB writes the bundle's database to path and checks that it opens
+// as a consistent SQLite file
+func extractBundleDB(entry *zip.File, path string) error {
+	src, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read database from bundle: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create database file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open restored database: %w", err)
+	}
+	defer db.Close()
+	problems, err := repository.IntegrityCheck(db)
+	if err != nil {
+		return fmt.Errorf("bundle database is not readable: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("bundle database failed the integrity check: %s", problems[0])
+	}
+	return nil
+}
+
+// swapDB stops the services, closes the database and moves newPath over dbPath,
+// keeping the previous file as .bak
+func (a *App) swapDB(newPath, dbPath string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.collector != nil {
+		a.collector.Stop()
+	}
+	if a.db != nil {
+		a.db.Close()
+	}
+
+	hadDB := false
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := os.Rename(dbPath, dbPath+".bak"); err != nil {
+			return fmt.Errorf("failed to keep the current database: %w", err)
+		}
+		hadDB = true
+	}
+	if err := os.Rename(newPath, dbPath); err != nil {
+		if hadDB {
+			if restoreErr := os.Rename(dbPath+".bak", dbPath); restoreErr != nil {
+				log.Printf("Failed to put the previous database back: %v", restoreErr)
+			}
+		}
+		return fmt.Errorf("failed to replace the database: %w", err)
+	}
+	return nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func readZipJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("bundle has no %s: %w", name, err)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bus_history/internal/collector"
+	"bus_history/internal/model"
+	"bus_history/internal/repository"
+	"bus_history/internal/service"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Diagnostics is a snapshot of the effective configuration and state for support
+// requests. Service keys are redacted to their last 4 characters.
+type Diagnostics struct {
+	GeneratedAt   time.Time                `json:"generatedAt"`
+	IntervalMs    int                      `json:"intervalMs"` // Global polling interval in effect
+	Windows       []model.TimeWindow       `json:"windows"`    // Daily collection windows in effect
+	Timezone      string                   `json:"timezone"`   // This machine's zone; dates in queries are read as Asia/Seoul
+	Retention     string                   `json:"retention"`
+	ServiceKeys   map[string]string        `json:"serviceKeys"` // Region -> redacted key, "" when none is set
+	StoragePath   string                   `json:"storagePath"`
+	DBPath        string                   `json:"dbPath"`
+	TotalConfigs  int                      `json:"totalConfigs"`
+	ActiveConfigs int                      `json:"activeConfigs"`
+	TotalArrivals int64                    `json:"totalArrivals"`
+	SchemaVersion int                      `json:"schemaVersion"`
+	LatestSchema  int                      `json:"latestSchema"` // Highest migration this build knows
+	Collecting    bool                     `json:"collecting"`
+	Configs       []collector.ConfigStatus `json:"configs"`
+	Storage       collector.StorageStatus  `json:"storage"`
+}
+
+// GetDiagnostics assembles the effective settings, database counts, schema version
+// and collector status in one call, to paste into a support thread. Parts that
+// need the database are left empty when it isn't open.
+func (a *App) GetDiagnostics() (*Diagnostics, error) {
+	if a.settings == nil {
+		return nil, fmt.Errorf("settings not loaded")
+	}
+
+	zone, _ := time.Now().Zone()
+	diag := &Diagnostics{
+		GeneratedAt:  time.Now(),
+		IntervalMs:   a.settings.IntervalMs,
+		Windows:      a.settings.CollectionWindows(),
+		Timezone:     fmt.Sprintf("%s (%s)", time.Local, zone),
+		Retention:    "unlimited (arrivals are kept until deleted)",
+		ServiceKeys:  map[string]string{},
+		StoragePath:  a.settings.StoragePath,
+		LatestSchema: len(columnMigrations),
+		Configs:      []collector.ConfigStatus{},
+	}
+	if a.cfg != nil {
+		diag.DBPath = a.cfg.Database.FilePath
+	}
+
+	// Both regional APIs are served by data.go.kr with the same key
+	for _, region := range []service.Region{service.RegionGyeonggi, service.RegionIncheon} {
+		key := ""
+		if a.busService == nil || a.busService.SupportsRegion(string(region)) {
+			key = redactKey(a.settings.ServiceKey)
+		}
+		diag.ServiceKeys[string(region)] = key
+	}
+
+	if a.collector != nil {
+		diag.IntervalMs = a.collector.Interval()
+		diag.Windows = a.collector.Windows()
+		diag.Collecting = a.collector.IsRunning()
+		diag.Configs = a.collector.ConfigStatuses()
+		diag.Storage = a.collector.StorageStatus()
+	}
+
+	if a.db == nil || a.configRepo == nil || a.busRepo == nil {
+		return diag, nil
+	}
+
+	var err error
+	diag.TotalConfigs, diag.ActiveConfigs, err = a.configRepo.CountConfigs()
+	if err != nil {
+		return nil, err
+	}
+	var overview model.SystemOverview
+	if err := a.busRepo.GetArrivalTotals(&overview, time.Now().Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+	diag.TotalArrivals = overview.TotalArrivals
+
+	applied, err := repository.AppliedMigrations(a.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range applied {
+		diag.SchemaVersion = max(diag.SchemaVersion, m.Version)
+	}
+
+	return diag, nil
+}
+
+// redactKey keeps only the last 4 characters of a secret; shorter secrets are fully masked
+func redactKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return "****" + key[len(key)-4:]
+}
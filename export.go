@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"bus_history/internal/model"
+	"bus_history/internal/repository"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
+)
+
+// Plate handling for ExportOptions.PlateMode
+const (
+	PlateKeep = ""     // Export plates as recorded
+	PlateHash = "hash" // Replace plates with a pseudonym that is stable within one export
+	PlateOmit = "omit" // Leave plate columns empty
+)
+
+// ExportOptions make an export fit for publishing
+type ExportOptions struct {
+	RoundMinutes int    `json:"roundMinutes"` // Round arrival_time to the nearest N minutes (0 = exact)
+	PlateMode    string `json:"plateMode"`
+}
+
+// exportAnonymizer applies ExportOptions to arrival rows. Plates are hashed with
+// a key generated per export, so the same plate maps to the same value across all
+// files of one export but can't be matched against other exports or brute-forced.
+type exportAnonymizer struct {
+	round time.Duration
+	mode  string
+	key   []byte
+}
+
+func newExportAnonymizer(opts ExportOptions) (*exportAnonymizer, error) {
+	switch opts.PlateMode {
+	case PlateKeep, PlateHash, PlateOmit:
+	default:
+		return nil, fmt.Errorf("unknown plate mode %q", opts.PlateMode)
+	}
+	if opts.RoundMinutes < 0 {
+		return nil, fmt.Errorf("round minutes must not be negative")
+	}
+
+	an := &exportAnonymizer{round: time.Duration(opts.RoundMinutes) * time.Minute, mode: opts.PlateMode}
+	if an.mode == PlateHash {
+		an.key = make([]byte, 32)
+		if _, err := rand.Read(an.key); err != nil {
+			return nil, fmt.Errorf("failed to generate export key: %w", err)
+		}
+	}
+	return an, nil
+}
+
+func (an *exportAnonymizer) time(t time.Time) time.Time {
+	if an.round <= 0 {
+		return t
+	}
+	return t.Round(an.round)
+}
+
+func (an *exportAnonymizer) plate(plate string) string {
+	switch an.mode {
+	case PlateOmit:
+		return ""
+	case PlateHash:
+		if plate == "" {
+			return ""
+		}
+		mac := hmac.New(sha256.New, an.key)
+		mac.Write([]byte(plate))
+		return hex.EncodeToString(mac.Sum(nil))[:12]
+	}
+	return plate
+}
+
+// arrival returns a copy of a with its plates and times anonymized, for exports
+// that write whole rows
+func (an *exportAnonymizer) arrival(a *model.BusArrivalWithConfig) *model.BusArrivalWithConfig {
+	row := *a
+	row.BusNumber = an.plate(a.BusNumber)
+	row.RawBusNumber = an.plate(a.RawBusNumber)
+	row.ArrivalTime = an.time(a.ArrivalTime)
+	row.CreatedAt = an.time(a.CreatedAt)
+	if len(a.ApproachPath) > 0 {
+		row.ApproachPath = make([]model.ApproachPoint, len(a.ApproachPath))
+		for i, p := range a.ApproachPath {
+			p.Time = an.time(p.Time)
+			row.ApproachPath[i] = p
+		}
+	}
+	return &row
+}
+
+var arrivalCSVHeader = []string{
+	"id", "route_id", "route_name", "station_id", "station_name",
+	"bus_number", "raw_bus_number", "arrival_time", "seats_before", "seats_after", "boarding", "is_suspect", "record_mode",
+}
+
+// writeArrivalsCSV streams every arrival of a config to w as CSV, row by row
+func writeArrivalsCSV(w io.Writer, busRepo repository.BusStore, configID int64, an *exportAnonymizer) (int64, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(arrivalCSVHeader); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	count, err := busRepo.ForEachByConfig(configID, nil, nil, func(a *model.BusArrivalWithConfig) error {
+		return cw.Write([]string{
+			strconv.FormatInt(a.ID, 10),
+			a.RouteID,
+			a.DisplayName,
+			a.StationID,
+			a.StationName,
+			an.plate(a.BusNumber),
+			an.plate(a.RawBusNumber),
+			an.time(a.ArrivalTime).Format("2006-01-02 15:04:05"),
+			csvInt(a.SeatsBefore),
+			csvInt(a.SeatsAfter),
+			csvInt(a.Boarding),
+			strconv.FormatBool(a.IsSuspect),
+			a.RecordMode,
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return count, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return count, nil
+}
+
+func csvInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// ndjsonArrival is one line of an NDJSON export: the arrival with its computed
+// boarding, plus the full config it was recorded for
+type ndjsonArrival struct {
+	*model.BusArrivalWithConfig
+	Config *model.RouteConfig `json:"config"`
+}
+
+// writeArrivalsNDJSON streams a config's arrivals to w as one JSON object per line
+func writeArrivalsNDJSON(w io.Writer, busRepo repository.BusStore, cfg *model.RouteConfig, from, to *time.Time, an *exportAnonymizer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw) // Encode terminates each value with a newline
+	count, err := busRepo.ForEachByConfig(cfg.ID, from, to, func(a *model.BusArrivalWithConfig) error {
+		return enc.Encode(ndjsonArrival{BusArrivalWithConfig: an.arrival(a), Config: cfg})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write NDJSON: %w", err)
+	}
+	return count, nil
+}
+
+// ExportArrivalsNDJSON writes a config's arrivals in a date range ("" = open) as
+// newline-delimited JSON to a file chosen via the save dialog, for jq and log
+// pipelines. opts can round times and hash or drop plates as in ExportAllConfigsZip.
+// Returns the saved path, or "" if cancelled.
+func (a *App) ExportArrivalsNDJSON(configID int64, fromDate, toDate string, opts ExportOptions) (string, error) {
+	if a.busRepo == nil || a.configRepo == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	an, err := newExportAnonymizer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := a.configRepo.FindByID(configID)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		return "", fmt.Errorf("config %d not found", configID)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "NDJSON 내보내기",
+		DefaultFilename: strings.TrimSuffix(exportFileName(cfg), ".csv") + ".ndjson",
+		Filters:         []runtime.FileFilter{{DisplayName: "NDJSON (*.ndjson)", Pattern: "*.ndjson;*.jsonl"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := writeArrivalsNDJSON(f, a.busRepo, cfg, from, to, an)
+	if err != nil {
+		return "", fmt.Errorf("failed to export config %d: %w", cfg.ID, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	log.Printf("Exported %d arrivals of config %d to %s", rows, cfg.ID, path)
+	return path, nil
+}
+
+// writeWorkbook writes a config's arrivals in a date range to w as an XLSX workbook
+// with three sheets: the arrivals as in the CSV export, the daily totals of
+// GetDailyBoarding, and a date × hour grid of average boarding. an applies to the
+// arrivals sheet; the other two only hold per-day and per-hour figures.
+func writeWorkbook(w io.Writer, busRepo repository.BusStore, cfg *model.RouteConfig, from, to *time.Time, an *exportAnonymizer) (int64, error) {
+	wb := excelize.NewFile()
+	defer wb.Close()
+
+	// A new workbook starts with one empty sheet, which becomes the arrivals
+	if err := wb.SetSheetName(wb.GetSheetName(0), "Arrivals"); err != nil {
+		return 0, fmt.Errorf("failed to name sheet: %w", err)
+	}
+	sheet, err := newXLSXSheet(wb, "Arrivals")
+	if err != nil {
+		return 0, err
+	}
+	header := make([]any, len(arrivalCSVHeader))
+	for i, name := range arrivalCSVHeader {
+		header[i] = name
+	}
+	if err := sheet.Row(header...); err != nil {
+		return 0, err
+	}
+
+	// Boarding sum and count per date and hour, for the heatmap
+	type cell struct{ sum, n int }
+	grid := make(map[string]*[24]cell)
+	var dates []string
+
+	count, err := busRepo.ForEachByConfig(cfg.ID, from, to, func(a *model.BusArrivalWithConfig) error {
+		a = an.arrival(a)
+		if a.Boarding != nil {
+			date := a.ArrivalTime.Format("2006-01-02")
+			hours, ok := grid[date]
+			if !ok {
+				hours = &[24]cell{}
+				grid[date] = hours
+				dates = append(dates, date)
+			}
+			hours[a.ArrivalTime.Hour()].sum += *a.Boarding
+			hours[a.ArrivalTime.Hour()].n++
+		}
+		return sheet.Row(a.ID, a.RouteID, a.DisplayName, a.StationID, a.StationName,
+			a.BusNumber, a.RawBusNumber, a.ArrivalTime.Format("2006-01-02 15:04:05"),
+			a.SeatsBefore, a.SeatsAfter, a.Boarding, a.IsSuspect, a.RecordMode)
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := sheet.Flush(); err != nil {
+		return count, err
+	}
+
+	days, err := busRepo.GetDailyBoarding(cfg.ID, from, to)
+	if err != nil {
+		return count, err
+	}
+	if sheet, err = newXLSXSheet(wb, "Daily"); err != nil {
+		return count, err
+	}
+	if err := sheet.Row("date", "total_arrivals", "total_boarding"); err != nil {
+		return count, err
+	}
+	for _, day := range days {
+		if err := sheet.Row(day.Date, day.TotalArrivals, day.TotalBoarding); err != nil {
+			return count, err
+		}
+	}
+	if err := sheet.Flush(); err != nil {
+		return count, err
+	}
+
+	if sheet, err = newXLSXSheet(wb, "Heatmap"); err != nil {
+		return count, err
+	}
+	row := []any{"date"}
+	for hour := range 24 {
+		row = append(row, fmt.Sprintf("%02d", hour))
+	}
+	if err := sheet.Row(row...); err != nil {
+		return count, err
+	}
+	for _, date := range dates {
+		row = []any{date}
+		for _, c := range grid[date] {
+			if c.n == 0 {
+				row = append(row, nil)
+			} else {
+				row = append(row, float64(c.sum)/float64(c.n))
+			}
+		}
+		if err := sheet.Row(row...); err != nil {
+			return count, err
+		}
+	}
+
+	if err := sheet.Flush(); err != nil {
+		return count, err
+	}
+
+	if err := wb.Write(w); err != nil {
+		return count, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return count, nil
+}
+
+// ExportWorkbook writes a config's arrivals in a date range ("" = open) to an XLSX
+// workbook chosen via the save dialog, with sheets for the arrivals, daily totals
+// and an hourly boarding heatmap. opts can round times and hash or drop plates as
+// in ExportAllConfigsZip. Returns the saved path, or "" if cancelled.
+func (a *App) ExportWorkbook(configID int64, fromDate, toDate string, opts ExportOptions) (string, error) {
+	if a.busRepo == nil || a.configRepo == nil {
+		return "", fmt.Errorf("DB not initialized")
+	}
+
+	an, err := newExportAnonymizer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	from, to, err := parseDateBounds(fromDate, toDate)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := a.configRepo.FindByID(configID)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		return "", fmt.Errorf("config %d not found", configID)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "엑셀 내보내기",
+		DefaultFilename: strings.TrimSuffix(exportFileName(cfg), ".csv") + ".xlsx",
+		Filters:         []runtime.FileFilter{{DisplayName: "Excel (*.xlsx)", Pattern: "*.xlsx"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := writeWorkbook(f, a.busRepo, cfg, from, to, an)
+	if err != nil {
+		return "", fmt.Errorf("failed to export config %d: %w", cfg.ID, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	log.Printf("Exported %d arrivals of config %d to %s", rows, cfg.ID, path)
+	return path, nil
+}
+
+// exportFileName builds a file name like "7700_사당역_12.csv" that is safe inside a zip
+func exportFileName(cfg *model.RouteConfig) string {
+	clean := strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_").Replace
+	return fmt.Sprintf("%s_%s_%d.csv", clean(cfg.Label()), clean(cfg.StationName), cfg.ID)
+}
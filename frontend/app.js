@@ -4,10 +4,15 @@ let selectedStation = null;
 let currentMode = 'route-first'; // 'route-first' or 'station-first'
 let currentViewedConfig = null; // Currently viewed config for auto-refresh
 let isCollecting = false;
+let sessionRecordCount = 0; // Arrivals saved since collection started
 
 // Initialization
 document.addEventListener('DOMContentLoaded', async () => {
 	setupEnterKey();
+	window.runtime.EventsOn('session-record-count', (count) => {
+		sessionRecordCount = count;
+		if (isCollecting) renderCollectionTitle();
+	});
 	initApp();
 });
 
@@ -85,20 +90,27 @@ async function updateCollectionStatus() {
 	try {
 		const status = await window.go.main.App.GetCollectionStatus();
 		isCollecting = status;
-		const btn = document.getElementById('main-toggle-btn');
-		const title = btn.querySelector('.menu-title');
-
 		if (status) {
-			btn.classList.add('collecting');
-			title.textContent = '수집 중지 (작동중)';
-		} else {
-			btn.classList.remove('collecting');
-			title.textContent = '수집 시작';
+			sessionRecordCount = await window.go.main.App.GetSessionRecordCount();
 		}
+		renderCollectionTitle();
 		lockSettings(status);
 	} catch (e) { }
 }
 
+function renderCollectionTitle() {
+	const btn = document.getElementById('main-toggle-btn');
+	const title = btn.querySelector('.menu-title');
+
+	if (isCollecting) {
+		btn.classList.add('collecting');
+		title.textContent = `수집 중지 (작동중 · ${sessionRecordCount}건)`;
+	} else {
+		btn.classList.remove('collecting');
+		title.textContent = '수집 시작';
+	}
+}
+
 // Settings
 async function selectStoragePath() {
 	try {
@@ -265,7 +277,7 @@ async function selectStationForStationFirst(idx) {
 	document.getElementById('sf-station-selected').classList.remove('hidden');
 
 	const region = station.regionName?.includes('인천') ? '인천' : '경기';
-	const routes = await window.go.main.App.GetStationRoutes(String(station.stationId), region);
+	const routes = await window.go.main.App.GetStationRoutesWithSeats(String(station.stationId), region);
 
 	const resultsDiv = document.getElementById('sf-route-results');
 	document.getElementById('sf-route-hint').style.display = 'none';
@@ -273,6 +285,7 @@ async function selectStationForStationFirst(idx) {
 	resultsDiv.innerHTML = routes.map((r, idx) => `
         <div class="result-item" onclick="selectRouteForStationFirst(${idx})">
             <div class="result-name">${r.routeName} ${r.direction ? `(${r.direction})` : ''}</div>
+            <div class="result-region">${r.remainSeatCnt != null ? `현재 잔여석 ${r.remainSeatCnt}석 (${r.predictTime}분 후)` : '도착 예정 버스 없음'}</div>
         </div>
     `).join('');
 
@@ -299,15 +312,30 @@ function updateRegisterButton() {
 }
 
 async function registerMonitoring() {
+	const cfg = {
+		route_id: String(selectedRoute.routeId),
+		route_name: selectedRoute.routeName,
+		station_id: String(selectedStation.stationId),
+		station_name: selectedStation.stationName,
+		direction: selectedStation.direction || selectedRoute.direction || '',
+		route_type: selectedRoute.routeTypeName || '',
+		region: selectedRoute.regionName?.includes('인천') ? '인천' : '경기',
+		sta_order: selectedStation.stationSeq || 0
+	};
 	try {
-		await window.go.main.App.CreateConfig({
-			route_id: String(selectedRoute.routeId),
-			route_name: selectedRoute.routeName,
-			station_id: String(selectedStation.stationId),
-			station_name: selectedStation.stationName,
-			direction: selectedStation.direction || selectedRoute.direction || '',
-			sta_order: selectedStation.stationSeq || 0
-		});
+		try {
+			await window.go.main.App.CreateConfig(cfg);
+		} catch (e) {
+			// The route's station list can be incomplete; let the user register anyway.
+			// Any other error (duplicate, read-only, ...) is shown as is.
+			if (!String(e).startsWith('station check failed')) {
+				throw e;
+			}
+			if (!confirm('노선 정류장 확인 실패: ' + e + '\n그래도 등록하시겠습니까?')) {
+				return;
+			}
+			await window.go.main.App.CreateConfigUnchecked(cfg);
+		}
 		showNotification('등록되었습니다!', 'success');
 		showView('list');
 	} catch (e) {
@@ -329,8 +357,8 @@ async function loadConfigs() {
                 <thead><tr><th>노선</th><th>정류장 (방향)</th><th>상태</th><th>작업</th></tr></thead>
                 <tbody>
                     ${configs.map(c => `
-                        <tr class="clickable-row" onclick="viewArrivals(${c.id}, '${c.route_id}', '${c.station_id}', '${c.route_name}', '${c.station_name}')">
-                            <td>${c.route_name}</td>
+                        <tr class="clickable-row" onclick="viewArrivals(${c.id}, '${c.route_id}', '${c.station_id}', '${c.display_name || c.route_name}', '${c.station_name}')">
+                            <td>${c.display_name || c.route_name}</td>
                             <td>${c.station_name} ${c.direction ? `(${c.direction})` : ''}</td>
                             <td>${c.is_active ? '✅' : '❌'}</td>
                             <td>
@@ -373,11 +401,11 @@ async function refreshCurrentArrivals() {
                 <tbody>
                     ${result.data.map(a => `
                         <tr class="clickable-row" onclick="viewTripDetail(${a.id})">
-                            <td>${a.bus_number}</td>
+                            <td>${a.is_scheduled ? '시간표' : a.bus_number}</td>
                             <td>${new Date(a.arrival_time).toLocaleTimeString()}</td>
                             <td>${a.seats_before ?? '-'}</td>
                             <td>${a.seats_after ?? '-'}</td>
-                            <td><strong>${(a.seats_before ?? 0) - (a.seats_after ?? 0)}명</strong></td>
+                            <td><strong>${a.boarding ?? '-'}명</strong></td>
                         </tr>
                     `).join('')}
                 </tbody>
@@ -392,14 +420,14 @@ async function refreshCurrentArrivals() {
 async function viewTripDetail(id) {
 	const div = document.getElementById('trip-detail');
 	try {
-		const trip = await window.go.main.App.GetTrip(id);
+		const trip = await window.go.main.App.GetTrip(id, 0, 0, 0);
 		if (!trip || trip.length === 0) return;
 
 		div.innerHTML = `
             <div class="trip-detail-container">
 				<div class="trip-header">
 					<h4>🚌 회차 상세 (차량: ${trip[0].bus_number})</h4>
-					<p>노선: ${trip[0].route_name} | 수집 시간: ${new Date(trip[0].arrival_time).toLocaleTimeString()}</p>
+					<p>노선: ${trip[0].display_name} | 수집 시간: ${new Date(trip[0].arrival_time).toLocaleTimeString()}</p>
 				</div>
 				<div class="trip-timeline">
 					${trip.map(t => `
@@ -408,7 +436,7 @@ async function viewTripDetail(id) {
 							<div class="timeline-content">
 								<div class="timeline-station">${t.station_name}</div>
 								<div class="timeline-boarding">
-									<strong>${(t.seats_before ?? 0) - (t.seats_after ?? 0)}명</strong> 탑승
+									<strong>${t.boarding ?? '-'}명</strong> 탑승
 									<span class="timeline-seats">(${t.seats_before} ➔ ${t.seats_after})</span>
 								</div>
 							</div>
@@ -428,7 +456,8 @@ async function toggleConfig(id, active) {
 }
 
 async function deleteConfig(id) {
-	if (confirm('삭제하시겠습니까?')) {
+	// Deleted configs are hidden but their arrivals are kept
+	if (confirm('삭제하시겠습니까? (수집된 도착 기록은 보존됩니다)')) {
 		await window.go.main.App.DeleteConfig(id);
 		loadConfigs();
 	}
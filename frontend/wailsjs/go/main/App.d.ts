@@ -1,26 +1,164 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {model} from '../models';
+import {main} from '../models';
+import {collector} from '../models';
 import {config} from '../models';
 import {service} from '../models';
 
+export function ClearRouteStationCache():Promise<void>;
+
+export function CompareToSchedule(arg1:number,arg2:string,arg3:number):Promise<model.ScheduleAdherence>;
+
 export function CreateConfig(arg1:model.RouteConfig):Promise<void>;
 
+export function CreateConfigUnchecked(arg1:model.RouteConfig):Promise<void>;
+
+export function CreateConfigsForRoute(arg1:string,arg2:string,arg3:string):Promise<number>;
+
+export function DeduplicateConfigs():Promise<number>;
+
 export function DeleteConfig(arg1:number):Promise<void>;
 
-export function GetArrivals(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number):Promise<Record<string, any>>;
+export function DetectRegion(arg1:string,arg2:number):Promise<string>;
+
+export function ExportAllConfigsZip(arg1:main.ExportOptions):Promise<string>;
+
+export function ExportArrivalsNDJSON(arg1:number,arg2:string,arg3:string,arg4:main.ExportOptions):Promise<string>;
+
+export function ExportBundle(arg1:boolean,arg2:boolean):Promise<string>;
+
+export function ExportWorkbook(arg1:number,arg2:string,arg3:string,arg4:main.ExportOptions):Promise<string>;
+
+export function GenerateDailyReport(arg1:string):Promise<model.DailyReport>;
+
+export function GetApproachDurationStats(arg1:number):Promise<model.ApproachDurationStats>;
+
+export function GetApproachPath(arg1:number):Promise<Array<model.ApproachPoint>>;
+
+export function GetArrivalAggregates(arg1:number,arg2:string,arg3:string):Promise<Array<model.ArrivalAggregate>>;
+
+export function GetArrivals(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number):Promise<main.ArrivalsPage>;
+
+export function GetArrivalsByPeriods(arg1:string,arg2:string,arg3:Array<model.DatePeriod>,arg4:number,arg5:number):Promise<main.ArrivalsPage>;
+
+export function GetArrivalsCursor(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:number):Promise<main.ArrivalsCursorPage>;
+
+export function GetArrivalsWithContext(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number,arg7:string,arg8:number):Promise<main.ArrivalsWithContextPage>;
+
+export function GetBoardingByRouteType(arg1:string,arg2:string,arg3:boolean):Promise<Record<string, model.BusArrivalStats>>;
+
+export function GetBoardingSeries(arg1:number,arg2:string,arg3:string,arg4:number):Promise<Array<model.SeriesPoint>>;
+
+export function GetBusHistory(arg1:string,arg2:string,arg3:string,arg4:number,arg5:number):Promise<main.ArrivalsPage>;
+
+export function GetBusiestStations(arg1:string,arg2:string,arg3:string):Promise<Array<model.StationRank>>;
+
+export function GetClockDriftReport(arg1:number):Promise<model.ClockDriftReport>;
+
+export function GetCollectionHealth():Promise<Array<collector.ConfigHealth>>;
 
 export function GetCollectionStatus():Promise<boolean>;
 
+export function GetConfigStatus():Promise<Array<collector.ConfigStatus>>;
+
 export function GetConfigs():Promise<Array<model.RouteConfig>>;
 
+export function GetConfigsWithStats():Promise<Array<model.RouteConfigWithStats>>;
+
+export function GetCoverageGaps(arg1:number,arg2:string,arg3:string):Promise<model.CoverageReport>;
+
+export function GetDailyBoarding(arg1:number,arg2:string,arg3:string):Promise<model.DailyBoardingReport>;
+
+export function GetDatesWithData(arg1:number):Promise<Array<string>>;
+
+export function GetDeletedConfigs():Promise<Array<model.RouteConfig>>;
+
+export function GetDiagnostics():Promise<main.Diagnostics>;
+
+export function GetDirectionSplit(arg1:number,arg2:string,arg3:string):Promise<Record<string, number>>;
+
+export function GetGroupArrivals(arg1:string,arg2:string,arg3:string,arg4:number,arg5:number):Promise<main.ArrivalsPage>;
+
+export function GetGroupStatistics(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<model.BusArrivalStats>;
+
+export function GetHeadwayAlerts(arg1:number):Promise<Array<model.HeadwayAlert>>;
+
+export function GetHourBoardingCorrelation(arg1:number,arg2:string,arg3:string):Promise<model.HourBoardingCorrelation>;
+
+export function GetHourlyCountsVsExpected(arg1:number,arg2:number,arg3:string,arg4:string):Promise<Array<model.HourlyComparison>>;
+
+export function GetLiveBusLocations(arg1:string,arg2:string):Promise<Array<model.BusLocation>>;
+
+export function GetRecentLogs(arg1:number,arg2:string):Promise<Array<main.LogLine>>;
+
+export function GetRegularityScore(arg1:number,arg2:string,arg3:string):Promise<model.RegularityScore>;
+
+export function GetRetryStats(arg1:number,arg2:string,arg3:string):Promise<Array<model.RetryBucket>>;
+
+export function GetRoutePath(arg1:string,arg2:string):Promise<Array<model.LatLng>>;
+
+export function GetRouteSnapshot(arg1:string,arg2:string,arg3:number):Promise<model.RouteSnapshot>;
+
 export function GetRouteStations(arg1:string,arg2:string):Promise<Array<model.RouteStation>>;
 
+export function GetRouteStationsAnnotated(arg1:string,arg2:string):Promise<Array<model.AnnotatedStation>>;
+
+export function GetScheduleExceptions(arg1:number):Promise<Array<model.ScheduleException>>;
+
+export function GetScheduleTimes(arg1:number):Promise<Array<string>>;
+
+export function GetSchemaInfo():Promise<model.SchemaInfo>;
+
+export function GetSeatsByApproachDistance(arg1:number,arg2:string,arg3:string):Promise<Record<number, number>>;
+
+export function GetServiceSpan(arg1:number,arg2:string,arg3:string):Promise<model.ServiceSpanReport>;
+
+export function GetSessionRecordCount():Promise<number>;
+
 export function GetSettings():Promise<config.AppSettings>;
 
+export function GetStaOrderConflicts(arg1:string):Promise<Array<model.ConflictPair>>;
+
 export function GetStationRoutes(arg1:string,arg2:string):Promise<Array<service.StationRouteInfo>>;
 
-export function GetTrip(arg1:number):Promise<Array<model.BusArrivalWithConfig>>;
+export function GetStationRoutesWithSeats(arg1:string,arg2:string):Promise<Array<service.StationRouteSeats>>;
+
+export function GetStatsByDirectionHour(arg1:string,arg2:string,arg3:string):Promise<Record<string, Array<number>>>;
+
+export function GetStorageStatus():Promise<collector.StorageStatus>;
+
+export function GetSystemOverview():Promise<model.SystemOverview>;
+
+export function GetTopBoardings(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<Array<model.BusArrivalWithConfig>>;
+
+export function GetTrip(arg1:number,arg2:number,arg3:number,arg4:number):Promise<Array<model.BusArrivalWithConfig>>;
+
+export function ImportBundle(arg1:string):Promise<void>;
+
+export function ImportConfigs(arg1:string):Promise<model.ImportPlan>;
+
+export function ListActiveCollectors():Promise<Array<number>>;
+
+export function PreviewDirection(arg1:string,arg2:string,arg3:string):Promise<service.DirectionPreview>;
+
+export function PreviewImportConfigs(arg1:string):Promise<model.ImportPlan>;
+
+export function PreviewPurge(arg1:number):Promise<model.PurgePreview>;
+
+export function PurgeConfig(arg1:number):Promise<void>;
+
+export function RecomputeBoarding(arg1:number):Promise<number>;
+
+export function RederiveStaOrders(arg1:string,arg2:string):Promise<number>;
+
+export function RemoveScheduleException(arg1:number,arg2:string):Promise<void>;
+
+export function RepairDatabase():Promise<model.RepairReport>;
+
+export function ResetCollectionInterval():Promise<void>;
+
+export function SaveSettings(arg1:config.AppSettings):Promise<void>;
 
 export function SearchRoutes(arg1:string):Promise<Array<model.RouteInfo>>;
 
@@ -28,10 +166,40 @@ export function SearchStations(arg1:string):Promise<Array<model.StationInfo>>;
 
 export function SelectFolder():Promise<string>;
 
+export function SetCollectionInterval(arg1:number):Promise<void>;
+
+export function SetConfigInterval(arg1:number,arg2:number):Promise<void>;
+
+export function SetDisplayName(arg1:number,arg2:string):Promise<void>;
+
+export function SetExpectedHeadway(arg1:number,arg2:number):Promise<void>;
+
+export function SetMinRecordGap(arg1:number,arg2:number):Promise<void>;
+
+export function SetRecordApproach(arg1:number,arg2:boolean):Promise<void>;
+
+export function SetRecordMode(arg1:number,arg2:string):Promise<void>;
+
+export function SetRouteGroup(arg1:number,arg2:string):Promise<void>;
+
+export function SetScheduleException(arg1:number,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetScheduleTimes(arg1:number,arg2:Array<string>):Promise<void>;
+
+export function SetStopType(arg1:number,arg2:string):Promise<void>;
+
 export function StartCollection():Promise<void>;
 
+export function StartCollector(arg1:number):Promise<void>;
+
 export function StopCollection():Promise<void>;
 
+export function StopCollector(arg1:number):Promise<void>;
+
 export function ToggleConfig(arg1:number,arg2:boolean):Promise<void>;
 
 export function UpdateSettings(arg1:string,arg2:string,arg3:number,arg4:number,arg5:number):Promise<void>;
+
+export function ValidateAllConfigs():Promise<Array<main.ConfigValidation>>;
+
+export function ValidateConfig(arg1:number):Promise<main.ConfigValidation>;
@@ -2,40 +2,312 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ClearRouteStationCache() {
+  return window['go']['main']['App']['ClearRouteStationCache']();
+}
+
+export function CompareToSchedule(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareToSchedule'](arg1, arg2, arg3);
+}
+
 export function CreateConfig(arg1) {
   return window['go']['main']['App']['CreateConfig'](arg1);
 }
 
+export function CreateConfigUnchecked(arg1) {
+  return window['go']['main']['App']['CreateConfigUnchecked'](arg1);
+}
+
+export function CreateConfigsForRoute(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateConfigsForRoute'](arg1, arg2, arg3);
+}
+
+export function DeduplicateConfigs() {
+  return window['go']['main']['App']['DeduplicateConfigs']();
+}
+
 export function DeleteConfig(arg1) {
   return window['go']['main']['App']['DeleteConfig'](arg1);
 }
 
+export function DetectRegion(arg1, arg2) {
+  return window['go']['main']['App']['DetectRegion'](arg1, arg2);
+}
+
+export function ExportAllConfigsZip(arg1) {
+  return window['go']['main']['App']['ExportAllConfigsZip'](arg1);
+}
+
+export function ExportArrivalsNDJSON(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportArrivalsNDJSON'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportBundle(arg1, arg2) {
+  return window['go']['main']['App']['ExportBundle'](arg1, arg2);
+}
+
+export function ExportWorkbook(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportWorkbook'](arg1, arg2, arg3, arg4);
+}
+
+export function GenerateDailyReport(arg1) {
+  return window['go']['main']['App']['GenerateDailyReport'](arg1);
+}
+
+export function GetApproachDurationStats(arg1) {
+  return window['go']['main']['App']['GetApproachDurationStats'](arg1);
+}
+
+export function GetApproachPath(arg1) {
+  return window['go']['main']['App']['GetApproachPath'](arg1);
+}
+
+export function GetArrivalAggregates(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetArrivalAggregates'](arg1, arg2, arg3);
+}
+
 export function GetArrivals(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['GetArrivals'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function GetArrivalsByPeriods(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetArrivalsByPeriods'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetArrivalsCursor(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['GetArrivalsCursor'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function GetArrivalsWithContext(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['GetArrivalsWithContext'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GetBoardingByRouteType(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetBoardingByRouteType'](arg1, arg2, arg3);
+}
+
+export function GetBoardingSeries(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetBoardingSeries'](arg1, arg2, arg3, arg4);
+}
+
+export function GetBusHistory(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetBusHistory'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetBusiestStations(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetBusiestStations'](arg1, arg2, arg3);
+}
+
+export function GetClockDriftReport(arg1) {
+  return window['go']['main']['App']['GetClockDriftReport'](arg1);
+}
+
+export function GetCollectionHealth() {
+  return window['go']['main']['App']['GetCollectionHealth']();
+}
+
 export function GetCollectionStatus() {
   return window['go']['main']['App']['GetCollectionStatus']();
 }
 
+export function GetConfigStatus() {
+  return window['go']['main']['App']['GetConfigStatus']();
+}
+
 export function GetConfigs() {
   return window['go']['main']['App']['GetConfigs']();
 }
 
+export function GetConfigsWithStats() {
+  return window['go']['main']['App']['GetConfigsWithStats']();
+}
+
+export function GetCoverageGaps(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetCoverageGaps'](arg1, arg2, arg3);
+}
+
+export function GetDailyBoarding(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetDailyBoarding'](arg1, arg2, arg3);
+}
+
+export function GetDatesWithData(arg1) {
+  return window['go']['main']['App']['GetDatesWithData'](arg1);
+}
+
+export function GetDeletedConfigs() {
+  return window['go']['main']['App']['GetDeletedConfigs']();
+}
+
+export function GetDiagnostics() {
+  return window['go']['main']['App']['GetDiagnostics']();
+}
+
+export function GetDirectionSplit(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetDirectionSplit'](arg1, arg2, arg3);
+}
+
+export function GetGroupArrivals(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetGroupArrivals'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetGroupStatistics(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetGroupStatistics'](arg1, arg2, arg3, arg4);
+}
+
+export function GetHeadwayAlerts(arg1) {
+  return window['go']['main']['App']['GetHeadwayAlerts'](arg1);
+}
+
+export function GetHourBoardingCorrelation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetHourBoardingCorrelation'](arg1, arg2, arg3);
+}
+
+export function GetHourlyCountsVsExpected(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetHourlyCountsVsExpected'](arg1, arg2, arg3, arg4);
+}
+
+export function GetLiveBusLocations(arg1, arg2) {
+  return window['go']['main']['App']['GetLiveBusLocations'](arg1, arg2);
+}
+
+export function GetRecentLogs(arg1, arg2) {
+  return window['go']['main']['App']['GetRecentLogs'](arg1, arg2);
+}
+
+export function GetRegularityScore(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetRegularityScore'](arg1, arg2, arg3);
+}
+
+export function GetRetryStats(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetRetryStats'](arg1, arg2, arg3);
+}
+
+export function GetRoutePath(arg1, arg2) {
+  return window['go']['main']['App']['GetRoutePath'](arg1, arg2);
+}
+
+export function GetRouteSnapshot(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetRouteSnapshot'](arg1, arg2, arg3);
+}
+
 export function GetRouteStations(arg1, arg2) {
   return window['go']['main']['App']['GetRouteStations'](arg1, arg2);
 }
 
+export function GetRouteStationsAnnotated(arg1, arg2) {
+  return window['go']['main']['App']['GetRouteStationsAnnotated'](arg1, arg2);
+}
+
+export function GetScheduleExceptions(arg1) {
+  return window['go']['main']['App']['GetScheduleExceptions'](arg1);
+}
+
+export function GetScheduleTimes(arg1) {
+  return window['go']['main']['App']['GetScheduleTimes'](arg1);
+}
+
+export function GetSchemaInfo() {
+  return window['go']['main']['App']['GetSchemaInfo']();
+}
+
+export function GetSeatsByApproachDistance(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetSeatsByApproachDistance'](arg1, arg2, arg3);
+}
+
+export function GetServiceSpan(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetServiceSpan'](arg1, arg2, arg3);
+}
+
+export function GetSessionRecordCount() {
+  return window['go']['main']['App']['GetSessionRecordCount']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetStaOrderConflicts(arg1) {
+  return window['go']['main']['App']['GetStaOrderConflicts'](arg1);
+}
+
 export function GetStationRoutes(arg1, arg2) {
   return window['go']['main']['App']['GetStationRoutes'](arg1, arg2);
 }
 
-export function GetTrip(arg1) {
-  return window['go']['main']['App']['GetTrip'](arg1);
+export function GetStationRoutesWithSeats(arg1, arg2) {
+  return window['go']['main']['App']['GetStationRoutesWithSeats'](arg1, arg2);
+}
+
+export function GetStatsByDirectionHour(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetStatsByDirectionHour'](arg1, arg2, arg3);
+}
+
+export function GetStorageStatus() {
+  return window['go']['main']['App']['GetStorageStatus']();
+}
+
+export function GetSystemOverview() {
+  return window['go']['main']['App']['GetSystemOverview']();
+}
+
+export function GetTopBoardings(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetTopBoardings'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetTrip(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetTrip'](arg1, arg2, arg3, arg4);
+}
+
+export function ImportBundle(arg1) {
+  return window['go']['main']['App']['ImportBundle'](arg1);
+}
+
+export function ImportConfigs(arg1) {
+  return window['go']['main']['App']['ImportConfigs'](arg1);
+}
+
+export function ListActiveCollectors() {
+  return window['go']['main']['App']['ListActiveCollectors']();
+}
+
+export function PreviewDirection(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewDirection'](arg1, arg2, arg3);
+}
+
+export function PreviewImportConfigs(arg1) {
+  return window['go']['main']['App']['PreviewImportConfigs'](arg1);
+}
+
+export function PreviewPurge(arg1) {
+  return window['go']['main']['App']['PreviewPurge'](arg1);
+}
+
+export function PurgeConfig(arg1) {
+  return window['go']['main']['App']['PurgeConfig'](arg1);
+}
+
+export function RecomputeBoarding(arg1) {
+  return window['go']['main']['App']['RecomputeBoarding'](arg1);
+}
+
+export function RederiveStaOrders(arg1, arg2) {
+  return window['go']['main']['App']['RederiveStaOrders'](arg1, arg2);
+}
+
+export function RemoveScheduleException(arg1, arg2) {
+  return window['go']['main']['App']['RemoveScheduleException'](arg1, arg2);
+}
+
+export function RepairDatabase() {
+  return window['go']['main']['App']['RepairDatabase']();
+}
+
+export function ResetCollectionInterval() {
+  return window['go']['main']['App']['ResetCollectionInterval']();
+}
+
+export function SaveSettings(arg1) {
+  return window['go']['main']['App']['SaveSettings'](arg1);
 }
 
 export function SearchRoutes(arg1) {
@@ -50,14 +322,66 @@ export function SelectFolder() {
   return window['go']['main']['App']['SelectFolder']();
 }
 
+export function SetCollectionInterval(arg1) {
+  return window['go']['main']['App']['SetCollectionInterval'](arg1);
+}
+
+export function SetConfigInterval(arg1, arg2) {
+  return window['go']['main']['App']['SetConfigInterval'](arg1, arg2);
+}
+
+export function SetDisplayName(arg1, arg2) {
+  return window['go']['main']['App']['SetDisplayName'](arg1, arg2);
+}
+
+export function SetExpectedHeadway(arg1, arg2) {
+  return window['go']['main']['App']['SetExpectedHeadway'](arg1, arg2);
+}
+
+export function SetMinRecordGap(arg1, arg2) {
+  return window['go']['main']['App']['SetMinRecordGap'](arg1, arg2);
+}
+
+export function SetRecordApproach(arg1, arg2) {
+  return window['go']['main']['App']['SetRecordApproach'](arg1, arg2);
+}
+
+export function SetRecordMode(arg1, arg2) {
+  return window['go']['main']['App']['SetRecordMode'](arg1, arg2);
+}
+
+export function SetRouteGroup(arg1, arg2) {
+  return window['go']['main']['App']['SetRouteGroup'](arg1, arg2);
+}
+
+export function SetScheduleException(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetScheduleException'](arg1, arg2, arg3);
+}
+
+export function SetScheduleTimes(arg1, arg2) {
+  return window['go']['main']['App']['SetScheduleTimes'](arg1, arg2);
+}
+
+export function SetStopType(arg1, arg2) {
+  return window['go']['main']['App']['SetStopType'](arg1, arg2);
+}
+
 export function StartCollection() {
   return window['go']['main']['App']['StartCollection']();
 }
 
+export function StartCollector(arg1) {
+  return window['go']['main']['App']['StartCollector'](arg1);
+}
+
 export function StopCollection() {
   return window['go']['main']['App']['StopCollection']();
 }
 
+export function StopCollector(arg1) {
+  return window['go']['main']['App']['StopCollector'](arg1);
+}
+
 export function ToggleConfig(arg1, arg2) {
   return window['go']['main']['App']['ToggleConfig'](arg1, arg2);
 }
@@ -65,3 +389,11 @@ export function ToggleConfig(arg1, arg2) {
 export function UpdateSettings(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['UpdateSettings'](arg1, arg2, arg3, arg4, arg5);
 }
+
+export function ValidateAllConfigs() {
+  return window['go']['main']['App']['ValidateAllConfigs']();
+}
+
+export function ValidateConfig(arg1) {
+  return window['go']['main']['App']['ValidateConfig'](arg1);
+}
@@ -1,3 +1,110 @@
+export namespace collector {
+	
+	export class ConfigHealth {
+	    configId: number;
+	    stationName: string;
+	    routeName: string;
+	    polls: number;
+	    withData: number;
+	    empty: number;
+	    errors: number;
+	    skipped: number;
+	    successRate: number;
+	    allErrors: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.configId = source["configId"];
+	        this.stationName = source["stationName"];
+	        this.routeName = source["routeName"];
+	        this.polls = source["polls"];
+	        this.withData = source["withData"];
+	        this.empty = source["empty"];
+	        this.errors = source["errors"];
+	        this.skipped = source["skipped"];
+	        this.successRate = source["successRate"];
+	        this.allErrors = source["allErrors"];
+	    }
+	}
+	export class ConfigStatus {
+	    configId: number;
+	    status: string;
+	    lastError?: string;
+	    // Go type: time
+	    lastErrorAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.configId = source["configId"];
+	        this.status = source["status"];
+	        this.lastError = source["lastError"];
+	        this.lastErrorAt = this.convertValues(source["lastErrorAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StorageStatus {
+	    unavailable: boolean;
+	    error?: string;
+	    // Go type: time
+	    since?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.unavailable = source["unavailable"];
+	        this.error = source["error"];
+	        this.since = this.convertValues(source["since"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace config {
 	
 	export class AppSettings {
@@ -23,8 +130,291 @@ export namespace config {
 
 }
 
+export namespace main {
+	
+	export class ArrivalsCursorPage {
+	    data: model.BusArrivalWithConfig[];
+	    nextCursor: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArrivalsCursorPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = this.convertValues(source["data"], model.BusArrivalWithConfig);
+	        this.nextCursor = source["nextCursor"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArrivalsPage {
+	    data: model.BusArrivalWithConfig[];
+	    total: number;
+	    page: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArrivalsPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = this.convertValues(source["data"], model.BusArrivalWithConfig);
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArrivalsWithContextPage {
+	    data: model.ArrivalWithContext[];
+	    total: number;
+	    page: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArrivalsWithContextPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = this.convertValues(source["data"], model.ArrivalWithContext);
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigValidation {
+	    configId: number;
+	    routeName: string;
+	    stationName: string;
+	    healthy: boolean;
+	    problems: string[];
+	    direction: string;
+	    stationSeq: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigValidation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.configId = source["configId"];
+	        this.routeName = source["routeName"];
+	        this.stationName = source["stationName"];
+	        this.healthy = source["healthy"];
+	        this.problems = source["problems"];
+	        this.direction = source["direction"];
+	        this.stationSeq = source["stationSeq"];
+	    }
+	}
+	export class Diagnostics {
+	    // Go type: time
+	    generatedAt: any;
+	    intervalMs: number;
+	    windows: model.TimeWindow[];
+	    timezone: string;
+	    retention: string;
+	    serviceKeys: Record<string, string>;
+	    storagePath: string;
+	    dbPath: string;
+	    totalConfigs: number;
+	    activeConfigs: number;
+	    totalArrivals: number;
+	    schemaVersion: number;
+	    latestSchema: number;
+	    collecting: boolean;
+	    configs: collector.ConfigStatus[];
+	    storage: collector.StorageStatus;
+	
+	    static createFrom(source: any = {}) {
+	        return new Diagnostics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.generatedAt = this.convertValues(source["generatedAt"], null);
+	        this.intervalMs = source["intervalMs"];
+	        this.windows = this.convertValues(source["windows"], model.TimeWindow);
+	        this.timezone = source["timezone"];
+	        this.retention = source["retention"];
+	        this.serviceKeys = source["serviceKeys"];
+	        this.storagePath = source["storagePath"];
+	        this.dbPath = source["dbPath"];
+	        this.totalConfigs = source["totalConfigs"];
+	        this.activeConfigs = source["activeConfigs"];
+	        this.totalArrivals = source["totalArrivals"];
+	        this.schemaVersion = source["schemaVersion"];
+	        this.latestSchema = source["latestSchema"];
+	        this.collecting = source["collecting"];
+	        this.configs = this.convertValues(source["configs"], collector.ConfigStatus);
+	        this.storage = this.convertValues(source["storage"], collector.StorageStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportOptions {
+	    roundMinutes: number;
+	    plateMode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roundMinutes = source["roundMinutes"];
+	        this.plateMode = source["plateMode"];
+	    }
+	}
+	export class LogLine {
+	    // Go type: time
+	    time: any;
+	    level: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.level = source["level"];
+	        this.message = source["message"];
+	    }
+	}
+
+}
+
 export namespace model {
 	
+	export class ArrivalWithContext {
+	    id: number;
+	    route_config_id: number;
+	    bus_number: string;
+	    // Go type: time
+	    arrival_time: any;
+	    seats_before?: number;
+	    seats_after?: number;
+	    // Go type: time
+	    created_at: any;
+	    route_id: string;
+	    route_name: string;
+	    station_id: string;
+	    station_name: string;
+	    sta_order: number;
+	    context?: ContextSample;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArrivalWithContext(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.route_config_id = source["route_config_id"];
+	        this.bus_number = source["bus_number"];
+	        this.arrival_time = this.convertValues(source["arrival_time"], null);
+	        this.seats_before = source["seats_before"];
+	        this.seats_after = source["seats_after"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.route_id = source["route_id"];
+	        this.route_name = source["route_name"];
+	        this.station_id = source["station_id"];
+	        this.station_name = source["station_name"];
+	        this.sta_order = source["sta_order"];
+	        this.context = this.convertValues(source["context"], ContextSample);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class BusArrivalWithConfig {
 	    id: number;
 	    route_config_id: number;
@@ -79,6 +469,61 @@ export namespace model {
 		    return a;
 		}
 	}
+	export class ContextSample {
+	    // Go type: time
+	    time: any;
+	    values: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContextSample(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.values = source["values"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LatLng {
+	    lat: number;
+	    lng: number;
+	    stationId: number;
+	    stationName: string;
+	    stationSeq: number;
+	    missing?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LatLng(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lat = source["lat"];
+	        this.lng = source["lng"];
+	        this.stationId = source["stationId"];
+	        this.stationName = source["stationName"];
+	        this.stationSeq = source["stationSeq"];
+	        this.missing = source["missing"];
+	    }
+	}
 	export class RouteConfig {
 	    id: number;
 	    route_id: string;
@@ -211,11 +656,47 @@ export namespace model {
 	        this.mobileNo = source["mobileNo"];
 	    }
 	}
+	export class TimeWindow {
+	    startHour: number;
+	    endHour: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startHour = source["startHour"];
+	        this.endHour = source["endHour"];
+	    }
+	}
 
 }
 
 export namespace service {
 	
+	export class StationRouteSeats {
+	    routeId: number;
+	    routeName: string;
+	    routeTypeName: string;
+	    direction: string;
+	    remainSeatCnt?: number;
+	    predictTime?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StationRouteSeats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.routeId = source["routeId"];
+	        this.routeName = source["routeName"];
+	        this.routeTypeName = source["routeTypeName"];
+	        this.direction = source["direction"];
+	        this.remainSeatCnt = source["remainSeatCnt"];
+	        this.predictTime = source["predictTime"];
+	    }
+	}
 	export class StationRouteInfo {
 	    routeId: number;
 	    routeName: string;
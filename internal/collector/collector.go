@@ -5,47 +5,165 @@ import (
 	"bus_history/internal/repository"
 	"bus_history/internal/service"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // BusState tracks the state of a bus approaching/at a station
 type BusState struct {
-	PlateNo     string
+	PlateNo     string // Normalized plate, also the tracking key
+	RawPlateNo  string // Plate as reported by the API
+	RouteName   string // Route name as reported by the API
+	StaOrder    int    // Station order reported by the API, 0 if unknown
 	FirstSeenAt time.Time
 	LastSeenAt  time.Time
-	SeatsBefore int  // Seats when bus was approaching
+	SeatsBefore int  // Seats when bus was approaching, -1 if no source had a valid count
 	LocationNo  int  // Location when first seen
 	Recorded    bool // Whether we've recorded this arrival
+	Warmup      bool // First seen during the warm-up, so the pass isn't recorded
 	// For pending seats_after retry
 	PendingArrivalID int64     // DB ID if saved without seats_after
 	PassedAt         time.Time // When bus passed the station
 	RetryCount       int       // Number of retry attempts
+	PassStationSeq   *int      // Station sequence the location API placed the bus at after passing
+	// Approach readings, only kept for configs with RecordApproach
+	Path []model.ApproachPoint
 }
 
 // configCollector manages collection for a single config
 type configCollector struct {
-	cfg      *model.RouteConfig
-	stopChan chan struct{}
+	cfg             *model.RouteConfig
+	stopChan        chan struct{}
+	intervalChanged chan struct{} // Signalled by SetInterval, buffered so it never blocks
+	status          string        // StatusCollecting or why the config isn't polled; set before publishing
+	lastErr         lastError     // Most recent polling error, kept across restarts of the config
+	schedule        scheduleCache // Owned by the config's goroutine
 }
 
+// Config collector states reported by ConfigStatuses
+const (
+	StatusCollecting = "collecting"
+	StatusNoClient   = "no client for region"
+)
+
+// ConfigStatus is the collection state of one active config
+type ConfigStatus struct {
+	ConfigID    int64      `json:"configId"`
+	Status      string     `json:"status"`
+	LastError   string     `json:"lastError,omitempty"`   // Most recent polling error, cleared an hour after it once polls succeed again
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"` // When LastError happened
+}
+
+// Options holds optional collector tuning beyond the base interval and time window
+type Options struct {
+	// Adaptive polling: while a tracked bus is within ApproachStops stops of the
+	// station, poll every ApproachIntervalMs instead of the base interval.
+	// ApproachStops <= 0 disables it.
+	ApproachStops      int
+	ApproachIntervalMs int
+
+	// Each config waits a random 0..StartJitterMs before its first poll so that
+	// many configs started together don't hit the API at the same instant.
+	StartJitterMs int
+
+	// Recorded arrivals are POSTed to WebhookURL when set (empty = disabled)
+	WebhookURL       string
+	WebhookTimeoutMs int
+
+	// Record a heartbeat per config per minute with a successful poll, so that
+	// "no bus came" can be told apart from "we weren't polling"
+	RecordHeartbeats bool
+
+	// At most MaxTrackedBuses buses are tracked per config; beyond that the
+	// oldest by FirstSeenAt are dropped. Bounds memory when the API churns
+	// plates that never cleanly pass. <= 0 = unlimited.
+	MaxTrackedBuses int
+
+	// For configs with a timetable, a scheduled time with no observed pass within
+	// ScheduleToleranceMin either side is recorded as a scheduled-only arrival
+	ScheduleToleranceMin int
+
+	// Aggregate-only mode: instead of one row per bus, each pass is counted into a
+	// per-config bucket of AggregateBucketMin minutes (count and average boarding),
+	// so plate numbers never reach the disk
+	AggregateOnly      bool
+	AggregateBucketMin int
+
+	// Arrivals saved without seats_after in the last SeatsFillLookbackMin minutes
+	// before Start get one more try from the location API (0 = disabled), so a
+	// restart doesn't lose what the in-memory retry was still waiting for
+	SeatsFillLookbackMin int
+
+	// A bus is not recorded again at a config within MinRecordGapSec seconds of its
+	// last recorded arrival there (0 = no check; configs can override). The last
+	// record is looked up in the database, so it also holds across restarts.
+	// Not applied in aggregate-only mode, which keeps no plates.
+	MinRecordGapSec int
+
+	// Buses first seen within WindowWarmupSec seconds of a config's collection
+	// starting or its time window opening are tracked, but their pass is not
+	// recorded: they were already approaching, so their seats_before is not the
+	// count from upstream (0 = no warm-up)
+	WindowWarmupSec int
+
+	// Daily collection periods; when set they replace the single start/end hour
+	Windows []model.TimeWindow
+
+	// Called with the new session count after each saved arrival (nil = none).
+	// Runs on the collecting goroutine, so it must not block.
+	OnRecordSaved func(count int64)
+
+	// Called when repeated failed saves pause collection and again when a test
+	// write succeeds and it resumes (nil = none). Must not block either.
+	OnStorageChange func(status StorageStatus)
+}
+
+// BusSource is the bus API access the collector needs, routed by each config's region.
+// service.BusService is the implementation.
+type BusSource interface {
+	GetRouteArrivals(ctx context.Context, routeID, stationID string, region string) ([]model.BusArrivalInfo, error)
+	GetBusLocations(ctx context.Context, routeID string, region string) ([]model.BusLocation, error)
+	DirectionAt(ctx context.Context, routeID, region string, staOrder int) (string, error)
+	SupportsRegion(region string) bool
+}
+
+var _ BusSource = (*service.BusService)(nil)
+
 // Collector manages bus data collection
 type Collector struct {
-	configRepo *repository.ConfigRepository
-	busRepo    *repository.BusRepository
-	apiClient  *service.OpenAPIClient
-	gbisClient *service.GBISClient
-	intervalMs int
+	configRepo repository.ConfigStore
+	busRepo    repository.BusStore
+	source     BusSource
+	intervalMs atomic.Int64 // Global polling interval, changeable at runtime via SetInterval
+	opts       Options
 
 	// Track running collectors per config ID
 	mu         sync.RWMutex
 	collectors map[int64]*configCollector
+	paused     map[int64]bool // Stopped by StopCollector; skipped by syncConfigs until StartCollector
 	mainCtx    context.Context
 	mainCancel context.CancelFunc
 	wg         sync.WaitGroup
-	startHour  int
-	endHour    int
+	windows    []model.TimeWindow // Collect when the hour falls in any of them
+	webhook    *webhookSender
+	polls      *pollHistory
+	saved      atomic.Int64 // Arrivals saved since Start
+	storage    storageHealth
+
+	// Closed and replaced by NotifySync so collectors sleeping until the
+	// time window opens re-check their schedule
+	wakeMu sync.Mutex
+	wakeCh chan struct{}
+
+	// Bumped by NotifySync so config collectors reload their cached schedule
+	scheduleGen atomic.Int64
 }
 
 // IsRunning returns true if the collector is started
@@ -55,32 +173,88 @@ func (c *Collector) IsRunning() bool {
 
 // NewCollector creates a new collector
 func NewCollector(
-	configRepo *repository.ConfigRepository,
-	busRepo *repository.BusRepository,
-	apiClient *service.OpenAPIClient,
-	gbisClient *service.GBISClient,
+	configRepo repository.ConfigStore,
+	busRepo repository.BusStore,
+	source BusSource,
 	intervalMs int,
 	startHour int,
 	endHour int,
+	opts Options,
 ) *Collector {
-	return &Collector{
+	var webhook *webhookSender
+	if opts.WebhookURL != "" {
+		webhook = newWebhookSender(opts.WebhookURL, time.Duration(opts.WebhookTimeoutMs)*time.Millisecond)
+	}
+
+	c := &Collector{
 		configRepo: configRepo,
 		busRepo:    busRepo,
-		apiClient:  apiClient,
-		gbisClient: gbisClient,
-		intervalMs: intervalMs,
+		source:     source,
+		opts:       opts,
 		collectors: make(map[int64]*configCollector),
-		startHour:  startHour,
-		endHour:    endHour,
+		paused:     make(map[int64]bool),
+		windows:    opts.Windows,
+		webhook:    webhook,
+		polls:      newPollHistory(),
+		wakeCh:     make(chan struct{}),
+	}
+	if len(c.windows) == 0 {
+		c.windows = []model.TimeWindow{{StartHour: startHour, EndHour: endHour}}
+	}
+	c.intervalMs.Store(int64(intervalMs))
+	return c
+}
+
+// SetInterval changes the global polling interval of running collectors in place,
+// without restarting them. Configs with their own interval keep it.
+func (c *Collector) SetInterval(ms int) {
+	c.intervalMs.Store(int64(ms))
+	log.Printf("[Collector] Global polling interval set to %dms", ms)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, cc := range c.collectors {
+		select {
+		case cc.intervalChanged <- struct{}{}:
+		default:
+		}
 	}
 }
 
+// Interval returns the global polling interval in effect, including a SetInterval override
+func (c *Collector) Interval() int {
+	return int(c.intervalMs.Load())
+}
+
+// Windows returns the daily collection windows in effect
+func (c *Collector) Windows() []model.TimeWindow {
+	return slices.Clone(c.windows)
+}
+
+// SessionRecordCount returns how many arrivals were saved since the collector was last started
+func (c *Collector) SessionRecordCount() int64 {
+	return c.saved.Load()
+}
+
 // Start begins the data collection process
 func (c *Collector) Start(ctx context.Context) error {
 	log.Println("Starting data collector...")
 
+	c.saved.Store(0)
+	if c.opts.OnRecordSaved != nil {
+		c.opts.OnRecordSaved(0)
+	}
+
 	c.mainCtx, c.mainCancel = context.WithCancel(ctx)
 
+	if c.webhook != nil {
+		go c.webhook.run(c.mainCtx)
+	}
+
+	if c.opts.SeatsFillLookbackMin > 0 && !c.opts.AggregateOnly {
+		go c.fillMissingSeatsAfter(c.mainCtx, time.Now().Add(-time.Duration(c.opts.SeatsFillLookbackMin)*time.Minute))
+	}
+
 	// Initial load
 	c.syncConfigs()
 
@@ -124,9 +298,80 @@ func (c *Collector) Stop() {
 
 // NotifySync triggers an immediate sync of configurations
 func (c *Collector) NotifySync() {
+	c.scheduleGen.Add(1)
+
+	c.wakeMu.Lock()
+	close(c.wakeCh)
+	c.wakeCh = make(chan struct{})
+	c.wakeMu.Unlock()
+
 	go c.syncConfigs()
 }
 
+// ConfigStatuses reports the collection state of every config the collector has picked up
+func (c *Collector) ConfigStatuses() []ConfigStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]ConfigStatus, 0, len(c.collectors))
+	for id, cc := range c.collectors {
+		status := ConfigStatus{ConfigID: id, Status: cc.status}
+		status.LastError, status.LastErrorAt = cc.lastErr.get()
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ConfigID < statuses[j].ConfigID })
+	return statuses
+}
+
+// ActiveCollectors returns the IDs of the configs currently being polled
+func (c *Collector) ActiveCollectors() []int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ids := make([]int64, 0, len(c.collectors))
+	for id, cc := range c.collectors {
+		if cc.status == StatusCollecting {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// StopCollector stops polling one config until StartCollector is called, without
+// touching its stored active flag. The pause outlives config reloads but not the
+// Collector itself.
+func (c *Collector) StopCollector(configID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused[configID] = true
+	if cc, exists := c.collectors[configID]; exists {
+		log.Printf("[Collector] Pausing collector for config %d (%s)", configID, cc.cfg.StationName)
+		close(cc.stopChan)
+		delete(c.collectors, configID)
+	}
+}
+
+// StartCollector lifts a StopCollector pause; the config is picked up again right
+// away if the collector is running and the config is active
+func (c *Collector) StartCollector(configID int64) {
+	c.mu.Lock()
+	delete(c.paused, configID)
+	c.mu.Unlock()
+
+	if c.IsRunning() {
+		go c.syncConfigs()
+	}
+}
+
+// wakeChan returns the channel closed on the next NotifySync
+func (c *Collector) wakeChan() <-chan struct{} {
+	c.wakeMu.Lock()
+	defer c.wakeMu.Unlock()
+	return c.wakeCh
+}
+
 // syncConfigs synchronizes running collectors with database configs
 func (c *Collector) syncConfigs() {
 	configs, err := c.configRepo.FindActive()
@@ -138,10 +383,10 @@ func (c *Collector) syncConfigs() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Create a set of active config IDs
+	// Create a set of active config IDs, leaving out ones paused at runtime
 	activeIDs := make(map[int64]bool)
 	for _, cfg := range configs {
-		activeIDs[cfg.ID] = true
+		activeIDs[cfg.ID] = !c.paused[cfg.ID]
 	}
 
 	// Stop collectors for deleted/inactive configs
@@ -153,18 +398,44 @@ func (c *Collector) syncConfigs() {
 		}
 	}
 
+	// Restart collectors whose polling settings changed
+	restarted := make(map[int64]*configCollector)
+	for _, cfg := range configs {
+		if cc, exists := c.collectors[cfg.ID]; exists && c.settingsChanged(cc.cfg, cfg) {
+			log.Printf("[Collector] Restarting collector for config %d (%s): polling settings changed",
+				cfg.ID, cfg.StationName)
+			close(cc.stopChan)
+			delete(c.collectors, cfg.ID)
+			restarted[cfg.ID] = cc
+		}
+	}
+
 	// Start collectors for new configs
 	for _, cfg := range configs {
-		if _, exists := c.collectors[cfg.ID]; !exists {
+		if _, exists := c.collectors[cfg.ID]; !exists && activeIDs[cfg.ID] {
 			log.Printf("[Collector] Starting new collector for config %d: route=%s (%s), station=%s (%s)",
 				cfg.ID, cfg.RouteID, cfg.RouteName, cfg.StationID, cfg.StationName)
 
 			cc := &configCollector{
-				cfg:      cfg,
-				stopChan: make(chan struct{}),
+				cfg:             cfg,
+				stopChan:        make(chan struct{}),
+				intervalChanged: make(chan struct{}, 1),
+				status:          StatusCollecting,
+			}
+			if old, ok := restarted[cfg.ID]; ok {
+				cc.lastErr.copyFrom(&old.lastErr)
 			}
 			c.collectors[cfg.ID] = cc
 
+			// Polling another region's API would only produce confusing empty data.
+			// The entry stays registered so the config isn't retried every sync.
+			if !c.source.SupportsRegion(cfg.Region) {
+				log.Printf("[Collector] ❌ Not collecting config %d (%s): no API client for region %q",
+					cfg.ID, cfg.StationName, cfg.Region)
+				cc.status = StatusNoClient
+				continue
+			}
+
 			c.wg.Add(1)
 			go c.collectForConfig(cc)
 		}
@@ -181,11 +452,29 @@ func (c *Collector) collectForConfig(cc *configCollector) {
 	log.Printf("[Collector] Collection started for route %s (%s) at station %s (%s)",
 		cfg.RouteID, cfg.RouteName, cfg.StationID, cfg.StationName)
 
-	ticker := time.NewTicker(time.Duration(c.intervalMs) * time.Millisecond)
+	// Stagger startup to spread requests from many configs over the interval
+	if c.opts.StartJitterMs > 0 {
+		jitter := time.Duration(rand.Int64N(int64(c.opts.StartJitterMs))) * time.Millisecond
+		select {
+		case <-c.mainCtx.Done():
+			return
+		case <-cc.stopChan:
+			return
+		case <-time.After(jitter):
+		}
+	}
+
+	baseInterval := c.configInterval(cfg)
+	approachInterval := min(time.Duration(c.opts.ApproachIntervalMs)*time.Millisecond, baseInterval)
+	currentInterval := baseInterval
+	ticker := time.NewTicker(currentInterval)
 	defer ticker.Stop()
 
 	// Track buses approaching/at this station
 	busStates := make(map[string]*BusState)
+	var lastHeartbeat time.Time
+	scheduleCheckedAt := time.Now()
+	warmupUntil := c.warmupEnd(time.Now())
 
 	for {
 		select {
@@ -195,29 +484,230 @@ func (c *Collector) collectForConfig(cc *configCollector) {
 			log.Printf("[Collector] Collection stopped for route %s at station %s",
 				cfg.RouteID, cfg.StationName)
 			return
+		case <-cc.intervalChanged:
+			baseInterval = c.configInterval(cfg)
+			approachInterval = min(time.Duration(c.opts.ApproachIntervalMs)*time.Millisecond, baseInterval)
+			next := baseInterval
+			if c.hasApproachingBus(busStates) {
+				next = approachInterval
+			}
+			if next != currentInterval {
+				log.Printf("[Collector] Polling interval for %s: %s -> %s", cfg.StationName, currentInterval, next)
+				currentInterval = next
+				ticker.Reset(currentInterval)
+			}
 		case <-ticker.C:
-			// Check time window
-			if c.isWithinTimeWindow() {
-				c.collectData(cfg, busStates)
+			// Passes seen now couldn't be saved, so don't poll until storage is back
+			if c.storagePaused() {
+				continue
+			}
+			// Check time window, or the config's exception for today
+			if c.shouldCollect(cc, time.Now()) {
+				if err := c.collectData(cfg, busStates, warmupUntil); err != nil {
+					cc.lastErr.set(err)
+				} else {
+					cc.lastErr.pollSucceeded()
+					if c.opts.RecordHeartbeats {
+						c.recordHeartbeat(cfg, &lastHeartbeat)
+					}
+				}
+				c.recordScheduledMisses(cc, &scheduleCheckedAt)
+
+				// Adaptive polling: speed up while a bus is close, relax once it has passed
+				next := baseInterval
+				if c.hasApproachingBus(busStates) {
+					next = approachInterval
+				}
+				if next != currentInterval {
+					log.Printf("[Collector] Polling interval for %s: %s -> %s", cfg.StationName, currentInterval, next)
+					currentInterval = next
+					ticker.Reset(currentInterval)
+				}
 			} else {
-				log.Printf("[Collector] Outside time window (%d-%d), skipping collection for %s",
-					c.startHour, c.endHour, cfg.StationName)
+				c.polls.record(cfg.ID, pollSkipped)
+				if !c.sleepUntilWindow(cc) {
+					return
+				}
+				// Scheduled times passed while asleep weren't watched for
+				scheduleCheckedAt = time.Now()
+				warmupUntil = c.warmupEnd(scheduleCheckedAt)
+				ticker.Reset(currentInterval)
 			}
 		}
 	}
 }
 
-// collectData performs a single data collection cycle
-func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*BusState) {
+// saveArrival stores a pass, as its own row or, in aggregate-only mode, into its time
+// bucket. In aggregate-only mode the plate is also cleared from the arrival so it
+// doesn't leave through the webhook either. Scheduled-only arrivals are not passes,
+// so they don't count towards the session record count.
+func (c *Collector) saveArrival(arrival *model.BusArrival) error {
+	if !c.opts.AggregateOnly {
+		err := c.busRepo.Create(arrival)
+		c.storageWrite(err)
+		if err != nil {
+			return err
+		}
+		if !arrival.IsScheduled {
+			c.countSaved()
+		}
+		return nil
+	}
+
+	var boarding *int
+	if arrival.SeatsBefore != nil && arrival.SeatsAfter != nil && !model.SeatsSuspect(arrival.SeatsBefore, arrival.SeatsAfter) {
+		b := max(*arrival.SeatsBefore-*arrival.SeatsAfter, 0)
+		boarding = &b
+	}
+	bucket := arrival.ArrivalTime.Truncate(time.Duration(c.opts.AggregateBucketMin) * time.Minute)
+	err := c.busRepo.AddToAggregate(arrival.RouteConfigID, bucket, boarding)
+	c.storageWrite(err)
+	if err != nil {
+		return err
+	}
+
+	arrival.BusNumber = ""
+	arrival.RawBusNumber = ""
+	c.countSaved()
+	return nil
+}
+
+// countSaved bumps the session record count and reports it
+func (c *Collector) countSaved() {
+	n := c.saved.Add(1)
+	if c.opts.OnRecordSaved != nil {
+		c.opts.OnRecordSaved(n)
+	}
+}
+
+// notifyWebhook forwards a recorded arrival to the configured webhook, if any
+func (c *Collector) notifyWebhook(cfg *model.RouteConfig, arrival *model.BusArrival) {
+	if c.webhook != nil {
+		c.webhook.enqueue(cfg, arrival)
+	}
+}
+
+// seatDeltaLabel describes a bus's seat change the way the config's stop type reads it
+func seatDeltaLabel(cfg *model.RouteConfig, before, after int) string {
+	if before < 0 {
+		return "passengers=unknown"
+	}
+	switch cfg.StopType {
+	case model.StopTypeBoarding:
+		return fmt.Sprintf("boarded=%d", before-after)
+	case model.StopTypeAlighting:
+		return fmt.Sprintf("net_alighting=%d", after-before)
+	default:
+		return fmt.Sprintf("passengers=%d", before-after)
+	}
+}
+
+// settingsChanged reports whether a running collector must restart to apply an edited config
+func (c *Collector) settingsChanged(running, latest *model.RouteConfig) bool {
+	return c.configInterval(running) != c.configInterval(latest) ||
+		running.RecordApproach != latest.RecordApproach ||
+		running.StopType != latest.StopType ||
+		running.RecordMode != latest.RecordMode ||
+		running.Region != latest.Region ||
+		c.minRecordGap(running) != c.minRecordGap(latest)
+}
+
+// warmupEnd returns when the warm-up that starts at now ends
+func (c *Collector) warmupEnd(now time.Time) time.Time {
+	return now.Add(time.Duration(c.opts.WindowWarmupSec) * time.Second)
+}
+
+// minRecordGap returns the minimum time between records of one bus for a config
+func (c *Collector) minRecordGap(cfg *model.RouteConfig) time.Duration {
+	if cfg.MinRecordGapSec != nil {
+		return time.Duration(*cfg.MinRecordGapSec) * time.Second
+	}
+	return time.Duration(c.opts.MinRecordGapSec) * time.Second
+}
+
+// recordedRecently reports whether the bus already has a record at the config
+// within the minimum gap of at, i.e. a flapping API made it "pass" twice
+func (c *Collector) recordedRecently(cfg *model.RouteConfig, plateNo string, at time.Time) bool {
+	gap := c.minRecordGap(cfg)
+	if gap <= 0 || c.opts.AggregateOnly {
+		return false
+	}
+
+	last, err := c.busRepo.LastRecordedAt(cfg.ID, plateNo)
+	if err != nil {
+		log.Printf("[Collector] Error checking last record of bus %s: %v", plateNo, err)
+		return false
+	}
+	if last == nil || at.Sub(*last) >= gap {
+		return false
+	}
+
+	log.Printf("[Collector] Skipping bus %s at %s: recorded %s ago, within the %s minimum gap",
+		plateNo, cfg.StationName, at.Sub(*last).Round(time.Second), gap)
+	return true
+}
+
+// configInterval returns the polling interval for a config, honoring its override
+func (c *Collector) configInterval(cfg *model.RouteConfig) time.Duration {
+	if cfg.IntervalMs != nil && *cfg.IntervalMs > 0 {
+		return time.Duration(*cfg.IntervalMs) * time.Millisecond
+	}
+	return time.Duration(c.intervalMs.Load()) * time.Millisecond
+}
+
+// pollIntervalMs is the interval stamped on a config's arrivals. It is the base
+// interval; adaptive polling near the station doesn't change it.
+func (c *Collector) pollIntervalMs(cfg *model.RouteConfig) *int {
+	ms := int(c.configInterval(cfg).Milliseconds())
+	return &ms
+}
+
+// sleepUntilWindow blocks until the time window opens instead of waking every
+// interval just to skip. It returns early when NotifySync is called so the
+// caller re-checks the window, and returns false if the collector is stopping.
+func (c *Collector) sleepUntilWindow(cc *configCollector) bool {
+	// Wake at midnight at the latest so the next day's schedule exception is seen
+	now := time.Now()
+	wait := min(c.untilWindowStart(now), untilNextDay(now))
+	log.Printf("[Collector] Outside time window %v, %s sleeping %s until next window",
+		c.windows, cc.cfg.StationName, wait.Round(time.Second))
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-c.mainCtx.Done():
+		return false
+	case <-cc.stopChan:
+		log.Printf("[Collector] Collection stopped for route %s at station %s",
+			cc.cfg.RouteID, cc.cfg.StationName)
+		return false
+	case <-c.wakeChan():
+		return true
+	case <-timer.C:
+		return true
+	}
+}
+
+// collectData performs a single data collection cycle. Buses first seen before
+// warmupUntil are tracked without recording their pass.
+// Returns the error if the arrival API could not be polled.
+func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*BusState, warmupUntil time.Time) error {
 	log.Printf("[Collector] === Collecting data for route %s (%s) at station %s (%s) ===",
 		cfg.RouteID, cfg.RouteName, cfg.StationID, cfg.StationName)
 
 	// Get bus arrival information from API
-	arrivals, err := c.apiClient.GetRouteArrivalList(cfg.RouteID, cfg.StationID)
+	arrivals, err := c.source.GetRouteArrivals(c.mainCtx, cfg.RouteID, cfg.StationID, cfg.Region)
 	if err != nil {
 		log.Printf("[Collector] Error fetching data for route %s at station %s: %v",
 			cfg.RouteID, cfg.StationID, err)
-		return
+		c.polls.record(cfg.ID, pollError)
+		return err
+	}
+	if len(arrivals) > 0 {
+		c.polls.record(cfg.ID, pollData)
+	} else {
+		c.polls.record(cfg.ID, pollEmpty)
 	}
 
 	log.Printf("[Collector] API returned %d arrivals, currently tracking %d buses",
@@ -226,39 +716,60 @@ func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*Bu
 	now := time.Now()
 	currentBuses := make(map[string]bool)
 
-	// Process current API results
+	// Process current API results. Every plate has its own state, so when one of
+	// the two buses an item reports drops out, only that one is seen as passed.
 	for _, arrival := range arrivals {
-		if arrival.PlateNo == "" {
+		plateNo := model.NormalizePlateIn(arrival.PlateNo, cfg.Region)
+		if plateNo == "" {
 			continue
 		}
+		currentBuses[plateNo] = true
 
-		currentBuses[arrival.PlateNo] = true
-
-		state, exists := busStates[arrival.PlateNo]
+		state, exists := busStates[plateNo]
 
 		if !exists {
 			// New bus detected - start tracking
-			busStates[arrival.PlateNo] = &BusState{
-				PlateNo:     arrival.PlateNo,
+			seats := c.approachSeats(cfg, arrival, plateNo)
+			state = &BusState{
+				PlateNo:     plateNo,
+				RawPlateNo:  arrival.PlateNo,
+				RouteName:   arrival.RouteName,
+				StaOrder:    arrival.StationSeq,
 				FirstSeenAt: now,
 				LastSeenAt:  now,
-				SeatsBefore: arrival.RemainSeatCnt,
+				SeatsBefore: seats,
 				LocationNo:  arrival.LocationNo1,
 				Recorded:    false,
+				Warmup:      now.Before(warmupUntil),
 			}
-			log.Printf("[Tracking] New bus %s approaching station %s, location=%d stops away, seats=%d",
-				arrival.PlateNo, cfg.StationName, arrival.LocationNo1, arrival.RemainSeatCnt)
+			busStates[plateNo] = state
+			log.Printf("[Tracking] New bus %s approaching station %s, location=%d stops away, seats=%d, warmup=%v",
+				arrival.PlateNo, cfg.StationName, arrival.LocationNo1, seats, state.Warmup)
 		} else {
 			// Update existing bus state
 			state.LastSeenAt = now
-			// Update seats before if bus is getting closer
-			if arrival.LocationNo1 < state.LocationNo {
-				state.SeatsBefore = arrival.RemainSeatCnt
+			// Update seats before if bus is getting closer, or while it is still
+			// unknown. An unknown count keeps the last valid one seen further upstream.
+			closer := arrival.LocationNo1 < state.LocationNo
+			if closer || state.SeatsBefore < 0 {
+				if seats := c.approachSeats(cfg, arrival, plateNo); seats >= 0 {
+					state.SeatsBefore = seats
+				}
+			}
+			if closer {
 				state.LocationNo = arrival.LocationNo1
 				log.Printf("[Tracking] Bus %s getting closer: location=%d, seats=%d",
-					arrival.PlateNo, arrival.LocationNo1, arrival.RemainSeatCnt)
+					arrival.PlateNo, arrival.LocationNo1, state.SeatsBefore)
 			}
 		}
+
+		if cfg.RecordApproach {
+			state.recordApproach(now, arrival.LocationNo1, arrival.RemainSeatCnt)
+		}
+
+		if cfg.RecordMode == model.RecordModeArrival && !state.Recorded && !state.Warmup && arrival.LocationNo1 <= 1 {
+			c.recordOnArrival(cfg, state)
+		}
 	}
 
 	// Check for buses that have passed the station (no longer in API results)
@@ -270,25 +781,52 @@ func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*Bu
 					state.PassedAt = now
 				}
 
+				if state.Warmup {
+					log.Printf("[Collector] Not recording bus %s at %s: first seen during the warm-up, seats_before was mid-approach",
+						plateNo, cfg.StationName)
+					state.Recorded = true
+					continue
+				}
+				if c.recordedRecently(cfg, plateNo, state.LastSeenAt) {
+					state.Recorded = true
+					continue
+				}
+				if cfg.RecordMode == model.RecordModeArrival {
+					// Passed without being seen 0-1 stops away; its last count is the closest there is
+					c.recordOnArrival(cfg, state)
+					continue
+				}
+
 				// Try to get seats after from bus location API
-				seatsAfter := c.getSeatsAfterFromBusLocation(cfg.RouteID, plateNo)
+				seatsAfter, stationSeq := c.getSeatsFromBusLocation(cfg, plateNo)
+				if stationSeq > 0 {
+					state.PassStationSeq = &stationSeq
+				}
 
 				if seatsAfter != nil {
 					// Got valid seat data - save the record
 					busArrival := &model.BusArrival{
-						RouteConfigID: cfg.ID,
-						BusNumber:     plateNo,
-						ArrivalTime:   state.LastSeenAt,
-						SeatsBefore:   &state.SeatsBefore,
-						SeatsAfter:    seatsAfter,
+						RouteConfigID:     cfg.ID,
+						BusNumber:         plateNo,
+						RawBusNumber:      state.RawPlateNo,
+						ObservedRouteName: state.RouteName,
+						Direction:         c.arrivalDirection(cfg, state),
+						ApproachPath:      state.Path,
+						ArrivalTime:       state.LastSeenAt,
+						SeatsBefore:       validSeats(state.SeatsBefore),
+						SeatsAfter:        seatsAfter,
+						RetryCount:        &state.RetryCount,
+						ApproachSeconds:   approachSeconds(state),
+						PassStationSeq:    state.PassStationSeq,
+						PollIntervalMs:    c.pollIntervalMs(cfg),
 					}
 
-					if err := c.busRepo.Create(busArrival); err != nil {
+					if err := c.saveArrival(busArrival); err != nil {
 						log.Printf("[Collector] ❌ Error saving bus arrival: %v", err)
 					} else {
-						passengersBoarded := state.SeatsBefore - *seatsAfter
-						log.Printf("[Collector] ✅ Recorded arrival: route=%s, station=%s, bus=%s, seats_before=%d, seats_after=%d, passengers=%d",
-							cfg.RouteName, cfg.StationName, plateNo, state.SeatsBefore, *seatsAfter, passengersBoarded)
+						c.notifyWebhook(cfg, busArrival)
+						log.Printf("[Collector] ✅ Recorded arrival: route=%s, station=%s, bus=%s, seats_before=%d, seats_after=%d, %s",
+							cfg.RouteName, cfg.StationName, plateNo, state.SeatsBefore, *seatsAfter, seatDeltaLabel(cfg, state.SeatsBefore, *seatsAfter))
 						state.Recorded = true
 					}
 				} else {
@@ -303,22 +841,7 @@ func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*Bu
 					} else {
 						// Timeout - save without seats_after
 						log.Printf("[Collector] ⚠️ Timeout waiting for seat data for bus %s, saving without seats_after", plateNo)
-
-						busArrival := &model.BusArrival{
-							RouteConfigID: cfg.ID,
-							BusNumber:     plateNo,
-							ArrivalTime:   state.LastSeenAt,
-							SeatsBefore:   &state.SeatsBefore,
-							SeatsAfter:    nil,
-						}
-
-						if err := c.busRepo.Create(busArrival); err != nil {
-							log.Printf("[Collector] ❌ Error saving bus arrival: %v", err)
-						} else {
-							log.Printf("[Collector] ✅ Recorded arrival (no seats_after): route=%s, station=%s, bus=%s, seats_before=%d",
-								cfg.RouteName, cfg.StationName, plateNo, state.SeatsBefore)
-							state.Recorded = true
-						}
+						c.recordWithoutSeatsAfter(cfg, state)
 					}
 				}
 			}
@@ -337,49 +860,325 @@ func (c *Collector) collectData(cfg *model.RouteConfig, busStates map[string]*Bu
 			delete(busStates, plateNo)
 		}
 	}
+
+	c.evictExcessBuses(cfg, busStates)
+
+	return nil
+}
+
+// recordOnArrival saves a bus of a RecordModeArrival config with the seats it had
+// when last seen, without waiting for it to pass or looking up seats_after
+func (c *Collector) recordOnArrival(cfg *model.RouteConfig, state *BusState) {
+	if c.recordedRecently(cfg, state.PlateNo, state.LastSeenAt) {
+		state.Recorded = true
+		return
+	}
+
+	busArrival := &model.BusArrival{
+		RouteConfigID:     cfg.ID,
+		BusNumber:         state.PlateNo,
+		RawBusNumber:      state.RawPlateNo,
+		ObservedRouteName: state.RouteName,
+		Direction:         c.arrivalDirection(cfg, state),
+		ApproachPath:      state.Path,
+		ArrivalTime:       state.LastSeenAt,
+		SeatsBefore:       validSeats(state.SeatsBefore),
+		ApproachSeconds:   approachSeconds(state),
+		RecordMode:        model.RecordModeArrival,
+		PollIntervalMs:    c.pollIntervalMs(cfg),
+	}
+
+	if err := c.saveArrival(busArrival); err != nil {
+		log.Printf("[Collector] ❌ Error saving bus arrival: %v", err)
+		return
+	}
+	c.notifyWebhook(cfg, busArrival)
+	log.Printf("[Collector] ✅ Recorded arrival on approach: route=%s, station=%s, bus=%s, seats=%d",
+		cfg.RouteName, cfg.StationName, state.PlateNo, state.SeatsBefore)
+	state.Recorded = true
+}
+
+// recordWithoutSeatsAfter saves a passed bus that never got a seats_after reading
+func (c *Collector) recordWithoutSeatsAfter(cfg *model.RouteConfig, state *BusState) {
+	busArrival := &model.BusArrival{
+		RouteConfigID:     cfg.ID,
+		BusNumber:         state.PlateNo,
+		RawBusNumber:      state.RawPlateNo,
+		ObservedRouteName: state.RouteName,
+		Direction:         c.arrivalDirection(cfg, state),
+		ApproachPath:      state.Path,
+		ArrivalTime:       state.LastSeenAt,
+		SeatsBefore:       validSeats(state.SeatsBefore),
+		SeatsAfter:        nil,
+		RetryCount:        &state.RetryCount,
+		ApproachSeconds:   approachSeconds(state),
+		PassStationSeq:    state.PassStationSeq,
+		PollIntervalMs:    c.pollIntervalMs(cfg),
+	}
+
+	if err := c.saveArrival(busArrival); err != nil {
+		log.Printf("[Collector] ❌ Error saving bus arrival: %v", err)
+		return
+	}
+	c.notifyWebhook(cfg, busArrival)
+	log.Printf("[Collector] ✅ Recorded arrival (no seats_after): route=%s, station=%s, bus=%s, seats_before=%d",
+		cfg.RouteName, cfg.StationName, state.PlateNo, state.SeatsBefore)
+	state.Recorded = true
 }
 
-// getSeatsAfterFromBusLocation queries the bus location API to get current seat count
-func (c *Collector) getSeatsAfterFromBusLocation(routeID, plateNo string) *int {
-	locations, err := c.gbisClient.GetBusLocations(routeID)
+// pendingPass reports whether the bus has passed the station but is not recorded
+// yet, i.e. it is waiting for a seats_after reading
+func (s *BusState) pendingPass() bool {
+	return !s.Recorded && !s.PassedAt.IsZero()
+}
+
+// evictExcessBuses drops tracked buses until the config is back within
+// Options.MaxTrackedBuses. Buses waiting for seats_after go last, oldest by
+// FirstSeenAt first within each group; one that has to go is recorded without
+// seats_after first so that its pass isn't lost.
+func (c *Collector) evictExcessBuses(cfg *model.RouteConfig, busStates map[string]*BusState) {
+	limit := c.opts.MaxTrackedBuses
+	if limit <= 0 || len(busStates) <= limit {
+		return
+	}
+
+	plates := make([]string, 0, len(busStates))
+	for plateNo := range busStates {
+		plates = append(plates, plateNo)
+	}
+	sort.Slice(plates, func(i, j int) bool {
+		a, b := busStates[plates[i]], busStates[plates[j]]
+		if a.pendingPass() != b.pendingPass() {
+			return !a.pendingPass()
+		}
+		return a.FirstSeenAt.Before(b.FirstSeenAt)
+	})
+
+	for _, plateNo := range plates[:len(plates)-limit] {
+		state := busStates[plateNo]
+		if state.pendingPass() {
+			c.recordWithoutSeatsAfter(cfg, state)
+		}
+		log.Printf("[Cleanup] Evicted bus %s from tracking at %s (cap %d reached, first seen %s)",
+			plateNo, cfg.StationName, limit, state.FirstSeenAt.Format("15:04:05"))
+		delete(busStates, plateNo)
+	}
+}
+
+// recordHeartbeat stores a coverage marker for the current minute, skipping the
+// write if this collector already recorded one for it
+func (c *Collector) recordHeartbeat(cfg *model.RouteConfig, last *time.Time) {
+	minute := time.Now().Truncate(time.Minute)
+	if minute.Equal(*last) {
+		return
+	}
+	if err := c.busRepo.RecordHeartbeat(cfg.ID, minute); err != nil {
+		log.Printf("[Collector] Error recording heartbeat for %s: %v", cfg.StationName, err)
+		return
+	}
+	*last = minute
+}
+
+// recordScheduledMisses records a scheduled-only arrival for each timetable entry whose
+// tolerance window closed since checkedAt without an observed pass. Yesterday's entries
+// are included so that a window spanning midnight isn't lost. Timetable times are
+// Korean time, like the arrivals they are checked against.
+func (c *Collector) recordScheduledMisses(cc *configCollector, checkedAt *time.Time) {
+	// Aggregate-only mode stores no passes individually, so misses can't be told apart
+	if c.opts.AggregateOnly {
+		return
+	}
+
+	cfg := cc.cfg
+	now := time.Now().In(timetableZone)
+	since := *checkedAt
+	*checkedAt = now
+
+	times, err := c.scheduleTimes(cc)
 	if err != nil {
-		log.Printf("[Collector] Error getting bus locations: %v", err)
+		log.Printf("[Collector] Error loading timetable for %s: %v", cfg.StationName, err)
+		return
+	}
+	if len(times) == 0 {
+		return
+	}
+
+	tolerance := time.Duration(c.opts.ScheduleToleranceMin) * time.Minute
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, timetableZone)
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		for _, hhmm := range times {
+			t, err := time.Parse("15:04", hhmm)
+			if err != nil {
+				continue
+			}
+			scheduled := day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+			closes := scheduled.Add(tolerance)
+			if !closes.After(since) || closes.After(now) {
+				continue
+			}
+
+			observed, err := c.busRepo.HasObservedArrival(cfg.ID, scheduled.Add(-tolerance), closes)
+			if err != nil {
+				log.Printf("[Collector] Error checking scheduled %s at %s: %v", hhmm, cfg.StationName, err)
+				continue
+			}
+			if observed {
+				continue
+			}
+
+			arrival := &model.BusArrival{
+				RouteConfigID: cfg.ID,
+				ArrivalTime:   scheduled,
+				Direction:     cfg.Direction,
+				IsScheduled:   true,
+			}
+			if err := c.saveArrival(arrival); err != nil {
+				log.Printf("[Collector] Error recording scheduled arrival %s at %s: %v", hhmm, cfg.StationName, err)
+				continue
+			}
+			log.Printf("[Collector] 🕒 No bus seen around scheduled %s at %s, recorded as scheduled", hhmm, cfg.StationName)
+		}
+	}
+}
+
+// recordApproach appends a reading to the approach path when it differs from the last one
+func (s *BusState) recordApproach(at time.Time, locationNo, seats int) {
+	if n := len(s.Path); n > 0 && s.Path[n-1].LocationNo == locationNo && s.Path[n-1].Seats == seats {
+		return
+	}
+	s.Path = append(s.Path, model.ApproachPoint{Time: at, LocationNo: locationNo, Seats: seats})
+}
+
+// hasApproachingBus reports whether adaptive polling should use the short interval,
+// i.e. a bus that hasn't passed yet is within ApproachStops of the station
+func (c *Collector) hasApproachingBus(busStates map[string]*BusState) bool {
+	if c.opts.ApproachStops <= 0 || c.opts.ApproachIntervalMs <= 0 {
+		return false
+	}
+	for _, state := range busStates {
+		if state.PassedAt.IsZero() && state.LocationNo <= c.opts.ApproachStops {
+			return true
+		}
+	}
+	return false
+}
+
+// arrivalDirection works out which way a recorded bus was going from the station
+// order the API reported, so a station served in both directions is split correctly.
+// Falls back to the config's direction.
+func (c *Collector) arrivalDirection(cfg *model.RouteConfig, state *BusState) string {
+	if state.StaOrder <= 0 {
+		return cfg.Direction
+	}
+	direction, err := c.source.DirectionAt(c.mainCtx, cfg.RouteID, cfg.Region, state.StaOrder)
+	if err != nil {
+		log.Printf("[Collector] Failed to get direction of bus %s: %v", state.PlateNo, err)
+		return cfg.Direction
+	}
+	return direction
+}
+
+// approachSeats returns the seat count of an approaching bus from the arrival API,
+// falling back to the location API when the arrival API reports it as unknown (-1).
+// Returns -1 if neither has a valid count.
+func (c *Collector) approachSeats(cfg *model.RouteConfig, arrival model.BusArrivalInfo, plateNo string) int {
+	if arrival.RemainSeatCnt >= 0 {
+		return arrival.RemainSeatCnt
+	}
+
+	log.Printf("[Collector] Arrival API has no seat count for bus %s, asking location API", plateNo)
+	if seats, _ := c.getSeatsFromBusLocation(cfg, plateNo); seats != nil {
+		return *seats
+	}
+	return -1
+}
+
+// approachSeconds is how long a bus was tracked from first appearing in the
+// arrival list until it was last seen before passing
+func approachSeconds(state *BusState) *int {
+	d := int(state.LastSeenAt.Sub(state.FirstSeenAt).Seconds())
+	return &d
+}
+
+// validSeats turns a seat count into a nullable column value, nil when unknown
+func validSeats(seats int) *int {
+	if seats < 0 {
 		return nil
 	}
+	return &seats
+}
+
+// getSeatsFromBusLocation queries the bus location API to get current seat count,
+// along with the station sequence the bus is at (0 if the bus wasn't found).
+// Regions without a location API have no buses to find, so this returns nil for them.
+func (c *Collector) getSeatsFromBusLocation(cfg *model.RouteConfig, plateNo string) (*int, int) {
+	locations, err := c.source.GetBusLocations(c.mainCtx, cfg.RouteID, cfg.Region)
+	if errors.Is(err, service.ErrUnsupportedRegion) {
+		return nil, 0
+	}
+	if err != nil {
+		log.Printf("[Collector] Error getting bus locations: %v", err)
+		return nil, 0
+	}
 
 	for _, loc := range locations {
-		if loc.PlateNo == plateNo {
+		if model.NormalizePlateIn(loc.PlateNo, cfg.Region) == plateNo {
 			// Validate seat count - API returns -1 when data is unavailable
 			if loc.RemainSeatCnt < 0 {
 				log.Printf("[Collector] Seat data not yet available for bus %s (got %d)", plateNo, loc.RemainSeatCnt)
-				return nil
+				return nil, loc.StationSeq
 			}
 
 			log.Printf("[Collector] Found bus %s at station seq %d, seats=%d",
 				plateNo, loc.StationSeq, loc.RemainSeatCnt)
 			seats := loc.RemainSeatCnt
-			return &seats
+			return &seats, loc.StationSeq
 		}
 	}
 
 	log.Printf("[Collector] Bus %s not found in location API results", plateNo)
-	return nil
+	return nil, 0
 }
 
-func (c *Collector) isWithinTimeWindow() bool {
-	if c.startHour == 0 && c.endHour == 0 {
-		return true // 24 hours
+// shouldCollect reports whether cc's config is collected at now. A schedule
+// exception for the date overrides the time window for the whole day.
+func (c *Collector) shouldCollect(cc *configCollector, now time.Time) bool {
+	exception, err := c.scheduleException(cc, now.Format("2006-01-02"))
+	if err != nil {
+		log.Printf("[Collector] Failed to check schedule exception for %s: %v", cc.cfg.StationName, err)
+	} else if exception != nil {
+		return exception.Enabled
 	}
+	return c.isWithinTimeWindow()
+}
 
-	now := time.Now()
-	hour := now.Hour()
+// untilNextDay returns how long from now until the next local midnight
+func untilNextDay(now time.Time) time.Duration {
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return midnight.Sub(now)
+}
 
-	if c.startHour < c.endHour {
-		return hour >= c.startHour && hour < c.endHour
-	} else if c.startHour > c.endHour {
-		// Cross-day: 22 to 2 means [22, 23, 0, 1]
-		return hour >= c.startHour || hour < c.endHour
+// untilWindowStart returns how long from now until the next start hour of any window
+func (c *Collector) untilWindowStart(now time.Time) time.Duration {
+	var wait time.Duration
+	for i, w := range c.windows {
+		start := time.Date(now.Year(), now.Month(), now.Day(), w.StartHour, 0, 0, 0, now.Location())
+		if !start.After(now) {
+			start = start.AddDate(0, 0, 1)
+		}
+		if d := start.Sub(now); i == 0 || d < wait {
+			wait = d
+		}
 	}
+	return wait
+}
 
-	return hour == c.startHour
+func (c *Collector) isWithinTimeWindow() bool {
+	hour := time.Now().Hour()
+	for _, w := range c.windows {
+		if w.Contains(hour) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,394 @@
+package collector
+
+import (
+	"bus_history/internal/model"
+	"bus_history/internal/repository"
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeSource serves canned API answers: arrivals for the next poll and the
+// current bus locations
+type fakeSource struct {
+	arrivals  []model.BusArrivalInfo
+	locations []model.BusLocation
+}
+
+func (s *fakeSource) GetRouteArrivals(ctx context.Context, routeID, stationID, region string) ([]model.BusArrivalInfo, error) {
+	return s.arrivals, nil
+}
+
+func (s *fakeSource) GetBusLocations(ctx context.Context, routeID, region string) ([]model.BusLocation, error) {
+	return s.locations, nil
+}
+
+func (s *fakeSource) DirectionAt(ctx context.Context, routeID, region string, staOrder int) (string, error) {
+	return "", nil
+}
+
+func (s *fakeSource) SupportsRegion(region string) bool { return true }
+
+// fakeBusStore records created arrivals; other BusStore methods are not expected
+// to be called by these tests and panic through the nil embedded interface
+type fakeBusStore struct {
+	repository.BusStore
+	created []*model.BusArrival
+	updated map[int64]int
+	missing []*model.BusArrival
+}
+
+func (s *fakeBusStore) LastRecordedAt(configID int64, busNumber string) (*time.Time, error) {
+	var last *time.Time
+	for _, a := range s.created {
+		if a.RouteConfigID == configID && a.BusNumber == busNumber && (last == nil || a.ArrivalTime.After(*last)) {
+			t := a.ArrivalTime
+			last = &t
+		}
+	}
+	return last, nil
+}
+
+func (s *fakeBusStore) FindMissingSeatsAfter(since time.Time) ([]*model.BusArrival, error) {
+	return s.missing, nil
+}
+
+func (s *fakeBusStore) Create(arrival *model.BusArrival) error {
+	arrival.ID = int64(len(s.created) + 1)
+	s.created = append(s.created, arrival)
+	return nil
+}
+
+func (s *fakeBusStore) HasObservedArrival(configID int64, from, to time.Time) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeBusStore) UpdateSeatsAfter(id int64, seatsAfter int) error {
+	if s.updated == nil {
+		s.updated = make(map[int64]int)
+	}
+	s.updated[id] = seatsAfter
+	return nil
+}
+
+// fakeConfigStore serves a fixed set of configs by ID, and counts schedule lookups
+type fakeConfigStore struct {
+	repository.ConfigStore
+	configs          map[int64]*model.RouteConfig
+	exceptions       map[string]*model.ScheduleException // By date
+	exceptionLookups int
+	times            []string
+	timesLookups     int
+}
+
+func (s *fakeConfigStore) FindByID(id int64) (*model.RouteConfig, error) {
+	return s.configs[id], nil
+}
+
+func (s *fakeConfigStore) FindActive() ([]*model.RouteConfig, error) {
+	return nil, nil
+}
+
+func (s *fakeConfigStore) FindScheduleTimes(configID int64) ([]string, error) {
+	s.timesLookups++
+	return s.times, nil
+}
+
+func (s *fakeConfigStore) FindScheduleException(configID int64, date string) (*model.ScheduleException, error) {
+	s.exceptionLookups++
+	return s.exceptions[date], nil
+}
+
+func newTestCollector(source *fakeSource, store *fakeBusStore, opts Options) *Collector {
+	cfg := testConfig()
+	configs := &fakeConfigStore{configs: map[int64]*model.RouteConfig{cfg.ID: cfg}}
+	c := NewCollector(configs, store, source, 1000, 0, 24, opts)
+	c.mainCtx = context.Background()
+	return c
+}
+
+func testConfig() *model.RouteConfig {
+	return &model.RouteConfig{ID: 1, RouteID: "200000115", StationID: "228000001", StationName: "test", StaOrder: 10}
+}
+
+func TestSeatsBeforeUnknownThenValid(t *testing.T) {
+	source := &fakeSource{}
+	c := newTestCollector(source, &fakeBusStore{}, Options{})
+	cfg := testConfig()
+	states := make(map[string]*BusState)
+
+	// First seen 3 stops away with no seat count from either API
+	source.arrivals = []model.BusArrivalInfo{{PlateNo: "70아1234", LocationNo1: 3, RemainSeatCnt: -1}}
+	if err := c.collectData(cfg, states, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := states["70아1234"].SeatsBefore; got != -1 {
+		t.Fatalf("seats_before after unknown reading = %d, want -1", got)
+	}
+
+	// Still 3 stops away, now with a valid count: it must replace the unknown one
+	source.arrivals[0].RemainSeatCnt = 20
+	if err := c.collectData(cfg, states, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := states["70아1234"].SeatsBefore; got != 20 {
+		t.Errorf("seats_before after valid reading at the same stop = %d, want 20", got)
+	}
+
+	// A later unknown reading keeps the valid count
+	source.arrivals[0].RemainSeatCnt = -1
+	source.arrivals[0].LocationNo1 = 2
+	if err := c.collectData(cfg, states, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := states["70아1234"].SeatsBefore; got != 20 {
+		t.Errorf("seats_before after a later unknown reading = %d, want 20", got)
+	}
+}
+
+func TestSeatsBeforeFromLocationAPI(t *testing.T) {
+	source := &fakeSource{
+		arrivals:  []model.BusArrivalInfo{{PlateNo: "70아1234", LocationNo1: 3, RemainSeatCnt: -1}},
+		locations: []model.BusLocation{{PlateNo: "경기70아1234", RemainSeatCnt: 17, StationSeq: 7}},
+	}
+	c := newTestCollector(source, &fakeBusStore{}, Options{})
+	states := make(map[string]*BusState)
+
+	if err := c.collectData(testConfig(), states, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := states["70아1234"].SeatsBefore; got != 17 {
+		t.Errorf("seats_before = %d, want 17 from the location API", got)
+	}
+}
+
+func TestMinRecordGapSuppressesFlapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		gapSec int
+		want   int
+	}{
+		{"gap suppresses the second pass", 300, 1},
+		{"no gap records both", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeSource{
+				locations: []model.BusLocation{{PlateNo: "경기70아1234", RemainSeatCnt: 12, StationSeq: 11}},
+			}
+			store := &fakeBusStore{}
+			c := newTestCollector(source, store, Options{MinRecordGapSec: tt.gapSec})
+			cfg := testConfig()
+
+			// The API flaps: the bus drops out, shows up again as a new bus
+			// (its state was lost) and drops out once more, seconds apart
+			for range 2 {
+				states := make(map[string]*BusState)
+				source.arrivals = []model.BusArrivalInfo{{PlateNo: "70아1234", LocationNo1: 1, RemainSeatCnt: 20}}
+				if err := c.collectData(cfg, states, time.Time{}); err != nil {
+					t.Fatal(err)
+				}
+				source.arrivals = nil
+				if err := c.collectData(cfg, states, time.Time{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(store.created) != tt.want {
+				t.Errorf("recorded %d arrivals, want %d", len(store.created), tt.want)
+			}
+		})
+	}
+}
+
+func TestEvictExcessBuses(t *testing.T) {
+	arrival := func(plate string) model.BusArrivalInfo {
+		return model.BusArrivalInfo{PlateNo: plate, LocationNo1: 3, RemainSeatCnt: 20}
+	}
+
+	t.Run("approaching buses go before one waiting for seats_after", func(t *testing.T) {
+		source := &fakeSource{}
+		store := &fakeBusStore{}
+		c := newTestCollector(source, store, Options{MaxTrackedBuses: 2})
+		cfg := testConfig()
+		states := make(map[string]*BusState)
+
+		// A passes while B and C approach; no seat data yet, so A waits for seats_after
+		for _, poll := range [][]model.BusArrivalInfo{
+			{arrival("70아0001")},
+			{arrival("70아0001"), arrival("70아0002")},
+			{arrival("70아0002"), arrival("70아0003")},
+		} {
+			source.arrivals = poll
+			if err := c.collectData(cfg, states, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(states) != 2 {
+			t.Fatalf("tracking %d buses, want 2", len(states))
+		}
+		if _, ok := states["70아0001"]; !ok {
+			t.Error("bus waiting for seats_after was evicted")
+		}
+		if _, ok := states["70아0002"]; ok {
+			t.Error("oldest approaching bus was kept")
+		}
+		if len(store.created) != 0 {
+			t.Errorf("recorded %d arrivals, want 0", len(store.created))
+		}
+	})
+
+	t.Run("evicted pending bus is recorded without seats_after", func(t *testing.T) {
+		source := &fakeSource{}
+		store := &fakeBusStore{}
+		c := newTestCollector(source, store, Options{MaxTrackedBuses: 1})
+		cfg := testConfig()
+
+		// Two buses waiting for seats_after, one more than the cap
+		now := time.Now()
+		states := map[string]*BusState{
+			"70아0001": {PlateNo: "70아0001", FirstSeenAt: now.Add(-2 * time.Minute), LastSeenAt: now, PassedAt: now, SeatsBefore: 20},
+			"70아0002": {PlateNo: "70아0002", FirstSeenAt: now.Add(-time.Minute), LastSeenAt: now, PassedAt: now, SeatsBefore: 15},
+		}
+		c.evictExcessBuses(cfg, states)
+
+		if _, ok := states["70아0002"]; len(states) != 1 || !ok {
+			t.Fatalf("tracking %v, want only the newer bus", states)
+		}
+		if len(store.created) != 1 {
+			t.Fatalf("recorded %d arrivals, want 1", len(store.created))
+		}
+		saved := store.created[0]
+		if saved.BusNumber != "70아0001" {
+			t.Errorf("recorded bus %s, want the evicted 70아0001", saved.BusNumber)
+		}
+		if saved.SeatsAfter != nil {
+			t.Errorf("seats_after = %d, want nil", *saved.SeatsAfter)
+		}
+	})
+}
+
+func TestTwoBusesPass(t *testing.T) {
+	a := model.BusArrivalInfo{PlateNo: "70아0001", LocationNo1: 1, RemainSeatCnt: 20}
+	b := model.BusArrivalInfo{PlateNo: "70아0002", LocationNo1: 4, RemainSeatCnt: 30}
+
+	tests := []struct {
+		name  string
+		polls [][]model.BusArrivalInfo
+		want  [][]string // Plates recorded after each poll
+	}{
+		{
+			name:  "both pass in the same poll",
+			polls: [][]model.BusArrivalInfo{{a, b}, {}},
+			want:  [][]string{{}, {"70아0001", "70아0002"}},
+		},
+		{
+			name:  "second bus passes first",
+			polls: [][]model.BusArrivalInfo{{a, b}, {a}, {}},
+			want:  [][]string{{}, {"70아0002"}, {"70아0001", "70아0002"}},
+		},
+		{
+			name:  "first bus passes first",
+			polls: [][]model.BusArrivalInfo{{a, b}, {b}, {}},
+			want:  [][]string{{}, {"70아0001"}, {"70아0001", "70아0002"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeSource{locations: []model.BusLocation{
+				{PlateNo: "경기70아0001", StationSeq: 11, RemainSeatCnt: 18},
+				{PlateNo: "경기70아0002", StationSeq: 11, RemainSeatCnt: 25},
+			}}
+			store := &fakeBusStore{}
+			c := newTestCollector(source, store, Options{})
+			cfg := testConfig()
+			states := make(map[string]*BusState)
+
+			for i, poll := range tt.polls {
+				source.arrivals = poll
+				if err := c.collectData(cfg, states, time.Time{}); err != nil {
+					t.Fatal(err)
+				}
+
+				var got []string
+				for _, arrival := range store.created {
+					got = append(got, arrival.BusNumber)
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, tt.want[i]) {
+					t.Fatalf("after poll %d recorded %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleExceptionCached(t *testing.T) {
+	c := newTestCollector(&fakeSource{}, &fakeBusStore{}, Options{})
+	configs := c.configRepo.(*fakeConfigStore)
+	cc := &configCollector{cfg: testConfig()}
+	day := time.Date(2026, 10, 16, 8, 0, 0, 0, time.Local)
+
+	for range 3 {
+		if !c.shouldCollect(cc, day) {
+			t.Fatal("shouldCollect() = false without an exception, want true")
+		}
+	}
+	if configs.exceptionLookups != 1 {
+		t.Errorf("3 polls on one day looked up the exception %d times, want 1", configs.exceptionLookups)
+	}
+
+	// An edit is picked up after NotifySync, and a new day is looked up again
+	configs.exceptions = map[string]*model.ScheduleException{"2026-10-16": {Date: "2026-10-16", Enabled: false}}
+	c.NotifySync()
+	if c.shouldCollect(cc, day) {
+		t.Error("shouldCollect() = true after disabling the day, want false")
+	}
+	if !c.shouldCollect(cc, day.AddDate(0, 0, 1)) {
+		t.Error("shouldCollect() = false the next day, want true")
+	}
+	if configs.exceptionLookups != 3 {
+		t.Errorf("looked up the exception %d times, want 3", configs.exceptionLookups)
+	}
+}
+
+func TestScheduledMissesInKoreanTime(t *testing.T) {
+	store := &fakeBusStore{}
+	c := newTestCollector(&fakeSource{}, store, Options{ScheduleToleranceMin: 5})
+	configs := c.configRepo.(*fakeConfigStore)
+	configs.times = []string{"08:00"}
+	cc := &configCollector{cfg: testConfig()}
+
+	// Yesterday's 08:00 has closed whatever the time now, and nothing was observed
+	checkedAt := time.Now().Add(-48 * time.Hour)
+	c.recordScheduledMisses(cc, &checkedAt)
+	c.recordScheduledMisses(cc, &checkedAt)
+
+	if configs.timesLookups != 1 {
+		t.Errorf("2 polls looked up the timetable %d times, want 1", configs.timesLookups)
+	}
+	if len(store.created) == 0 {
+		t.Fatal("no scheduled arrival recorded")
+	}
+	for _, a := range store.created {
+		if at := a.ArrivalTime.In(timetableZone); !a.IsScheduled || at.Hour() != 8 || at.Minute() != 0 {
+			t.Errorf("recorded %s (scheduled %v), want 08:00 Korean time", at, a.IsScheduled)
+		}
+	}
+}
+
+func TestScheduledMissesSkippedInAggregateMode(t *testing.T) {
+	store := &fakeBusStore{}
+	c := newTestCollector(&fakeSource{}, store, Options{ScheduleToleranceMin: 5, AggregateOnly: true, AggregateBucketMin: 15})
+	c.configRepo.(*fakeConfigStore).times = []string{"08:00"}
+	cc := &configCollector{cfg: testConfig()}
+
+	checkedAt := time.Now().Add(-48 * time.Hour)
+	c.recordScheduledMisses(cc, &checkedAt)
+	if len(store.created) != 0 || c.SessionRecordCount() != 0 {
+		t.Errorf("aggregate-only mode recorded %d scheduled arrivals (session count %d), want none",
+			len(store.created), c.SessionRecordCount())
+	}
+}
@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// healthWindow is how far back GetCollectionHealth looks
+const healthWindow = time.Hour
+
+// Outcomes of one polling cycle
+const (
+	pollData    = iota // The API answered with buses
+	pollEmpty          // The API answered, but no bus was approaching
+	pollError          // The API call failed
+	pollSkipped        // Outside the time window or disabled for the day
+)
+
+// ConfigHealth counts a config's polling cycles over the last hour
+type ConfigHealth struct {
+	ConfigID    int64   `json:"configId"`
+	StationName string  `json:"stationName"`
+	RouteName   string  `json:"routeName"`
+	Polls       int     `json:"polls"`       // Cycles that called the API (data + empty + errors)
+	WithData    int     `json:"withData"`    // Cycles that returned buses
+	Empty       int     `json:"empty"`       // Cycles that succeeded without buses
+	Errors      int     `json:"errors"`      // Cycles whose API call failed
+	Skipped     int     `json:"skipped"`     // Cycles skipped by the time window or a schedule exception
+	SuccessRate float64 `json:"successRate"` // (withData + empty) / polls, 0 when nothing was polled
+	AllErrors   bool    `json:"allErrors"`   // Polled, and every poll failed
+}
+
+type pollEvent struct {
+	at      time.Time
+	outcome int
+}
+
+// pollHistory keeps each config's polling outcomes for the last healthWindow.
+// It outlives config collector restarts so a restart doesn't hide a failing config.
+type pollHistory struct {
+	mu     sync.Mutex
+	events map[int64][]pollEvent
+}
+
+func newPollHistory() *pollHistory {
+	return &pollHistory{events: make(map[int64][]pollEvent)}
+}
+
+func (h *pollHistory) record(configID int64, outcome int) {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events[configID] = append(prune(h.events[configID], now), pollEvent{at: now, outcome: outcome})
+}
+
+// prune drops events older than healthWindow; events are in time order
+func prune(events []pollEvent, now time.Time) []pollEvent {
+	cutoff := now.Add(-healthWindow)
+	i := sort.Search(len(events), func(i int) bool { return events[i].at.After(cutoff) })
+	return events[i:]
+}
+
+// summary counts a config's recent outcomes into a ConfigHealth
+func (h *pollHistory) summary(configID int64) ConfigHealth {
+	h.mu.Lock()
+	events := prune(h.events[configID], time.Now())
+	h.events[configID] = events
+	h.mu.Unlock()
+
+	health := ConfigHealth{ConfigID: configID}
+	for _, e := range events {
+		switch e.outcome {
+		case pollData:
+			health.WithData++
+		case pollEmpty:
+			health.Empty++
+		case pollError:
+			health.Errors++
+		case pollSkipped:
+			health.Skipped++
+		}
+	}
+	health.Polls = health.WithData + health.Empty + health.Errors
+	if health.Polls > 0 {
+		health.SuccessRate = float64(health.WithData+health.Empty) / float64(health.Polls)
+		health.AllErrors = health.Errors == health.Polls
+	}
+	return health
+}
+
+// forget drops the history of configs that are no longer collected
+func (h *pollHistory) forget(keep map[int64]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id := range h.events {
+		if !keep[id] {
+			delete(h.events, id)
+		}
+	}
+}
+
+// CollectionHealth reports each collected config's polling outcomes over the last hour,
+// failing configs first
+func (c *Collector) CollectionHealth() []ConfigHealth {
+	c.mu.RLock()
+	result := make([]ConfigHealth, 0, len(c.collectors))
+	keep := make(map[int64]bool, len(c.collectors))
+	for id, cc := range c.collectors {
+		health := c.polls.summary(id)
+		health.StationName = cc.cfg.StationName
+		health.RouteName = cc.cfg.Label()
+		result = append(result, health)
+		keep[id] = true
+	}
+	c.mu.RUnlock()
+	c.polls.forget(keep)
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AllErrors != result[j].AllErrors {
+			return result[i].AllErrors
+		}
+		if result[i].Errors != result[j].Errors {
+			return result[i].Errors > result[j].Errors
+		}
+		return result[i].ConfigID < result[j].ConfigID
+	})
+	return result
+}
@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// staleErrorAge is how old a config's last error must be before a successful
+// poll clears it
+const staleErrorAge = time.Hour
+
+// lastError is the most recent error a config collector hit and when
+type lastError struct {
+	mu  sync.Mutex
+	msg string
+	at  time.Time
+}
+
+func (e *lastError) set(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.msg = err.Error()
+	e.at = time.Now()
+}
+
+// pollSucceeded drops the error once it is older than staleErrorAge, so a
+// config that recovered long ago no longer shows it
+func (e *lastError) pollSucceeded() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.msg != "" && time.Since(e.at) > staleErrorAge {
+		e.msg = ""
+		e.at = time.Time{}
+	}
+}
+
+// get returns the error message and time, "" and nil if there is none
+func (e *lastError) get() (string, *time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.msg == "" {
+		return "", nil
+	}
+	at := e.at
+	return e.msg, &at
+}
+
+// copyFrom takes over the error of the collector a config's collector replaces
+func (e *lastError) copyFrom(old *lastError) {
+	msg, at := old.get()
+	if at == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.msg = msg
+	e.at = *at
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"bus_history/internal/model"
+	"time"
+)
+
+// timetableZone is the zone timetable times are read in, whatever zone the host
+// is in: the buses run on Korean time, and arrivals are stored in it
+var timetableZone = loadTimetableZone()
+
+func loadTimetableZone() *time.Location {
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		return time.FixedZone("KST", 9*60*60)
+	}
+	return loc
+}
+
+// scheduleCache keeps what a config collector looked up about its schedule, so
+// the polling loop doesn't query the database on every tick. An entry is reloaded
+// once its date passes or NotifySync bumps the collector's scheduleGen, which the
+// bindings editing a schedule call.
+type scheduleCache struct {
+	exceptionGen  int64
+	exceptionDate string                   // Date the exception was looked up for, "" = not yet
+	exception     *model.ScheduleException // nil if the date has none
+
+	timesGen    int64
+	timesLoaded bool
+	times       []string // "HH:MM" timetable, empty for most configs
+}
+
+// scheduleException returns the schedule exception of cc's config on date
+func (c *Collector) scheduleException(cc *configCollector, date string) (*model.ScheduleException, error) {
+	// Read the generation first, so an edit made during the lookup reloads it next time
+	gen := c.scheduleGen.Load()
+	s := &cc.schedule
+	if s.exceptionDate == date && s.exceptionGen == gen {
+		return s.exception, nil
+	}
+
+	exception, err := c.configRepo.FindScheduleException(cc.cfg.ID, date)
+	if err != nil {
+		return nil, err
+	}
+	s.exceptionGen, s.exceptionDate, s.exception = gen, date, exception
+	return exception, nil
+}
+
+// scheduleTimes returns the timetable of cc's config
+func (c *Collector) scheduleTimes(cc *configCollector) ([]string, error) {
+	gen := c.scheduleGen.Load()
+	s := &cc.schedule
+	if s.timesLoaded && s.timesGen == gen {
+		return s.times, nil
+	}
+
+	times, err := c.configRepo.FindScheduleTimes(cc.cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.timesGen, s.timesLoaded, s.times = gen, true, times
+	return times, nil
+}
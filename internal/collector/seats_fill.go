@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"bus_history/internal/model"
+	"bus_history/internal/service"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// maxFillStationsPast is how far past the configured station a bus may be for its
+// current seat count to still stand for seats_after. Further on, passengers have
+// boarded and alighted at other stops and the count says nothing about this one.
+const maxFillStationsPast = 2
+
+// fillMissingSeatsAfter gives arrivals saved without seats_after since the given
+// time one more lookup in the location API. Those are rows whose in-memory retry
+// was cut short by a restart, or that timed out just before it. Only buses 1 to
+// maxFillStationsPast stops past the station are used; buses elsewhere on the
+// route (or without seat data) are left as they are.
+func (c *Collector) fillMissingSeatsAfter(ctx context.Context, since time.Time) {
+	arrivals, err := c.busRepo.FindMissingSeatsAfter(since)
+	if err != nil {
+		log.Printf("[Collector] Error finding arrivals missing seats_after: %v", err)
+		return
+	}
+	if len(arrivals) == 0 {
+		return
+	}
+
+	byConfig := make(map[int64][]*model.BusArrival)
+	for _, a := range arrivals {
+		byConfig[a.RouteConfigID] = append(byConfig[a.RouteConfigID], a)
+	}
+
+	filled := 0
+	for configID, pending := range byConfig {
+		if ctx.Err() != nil {
+			return
+		}
+		cfg, err := c.configRepo.FindByID(configID)
+		if err != nil || cfg == nil || cfg.StaOrder <= 0 || !c.source.SupportsRegion(cfg.Region) {
+			continue
+		}
+
+		// One location call per config, matched against all of its pending plates
+		locations, err := c.source.GetBusLocations(ctx, cfg.RouteID, cfg.Region)
+		if errors.Is(err, service.ErrUnsupportedRegion) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[Collector] Error getting bus locations for config %d: %v", configID, err)
+			continue
+		}
+		seats := make(map[string]int, len(locations))
+		for _, loc := range locations {
+			past := loc.StationSeq - cfg.StaOrder
+			if loc.RemainSeatCnt >= 0 && past >= 1 && past <= maxFillStationsPast {
+				seats[model.NormalizePlateIn(loc.PlateNo, cfg.Region)] = loc.RemainSeatCnt
+			}
+		}
+
+		for _, a := range pending {
+			n, ok := seats[a.BusNumber]
+			if !ok {
+				continue
+			}
+			if err := c.busRepo.UpdateSeatsAfter(a.ID, n); err != nil {
+				log.Printf("[Collector] Error filling seats_after of arrival %d: %v", a.ID, err)
+				continue
+			}
+			filled++
+		}
+	}
+
+	log.Printf("[Collector] Filled seats_after of %d/%d arrivals saved without it since %s",
+		filled, len(arrivals), since.Format("15:04:05"))
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"bus_history/internal/model"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFillMissingSeatsAfter(t *testing.T) {
+	// The test config's station is at sequence 10
+	tests := []struct {
+		name       string
+		stationSeq int
+		seats      int
+		want       int // -1 = left unfilled
+	}{
+		{"one stop past", 11, 20, 20},
+		{"two stops past", 12, 18, 18},
+		{"three stops past", 13, 15, -1},
+		{"still at the station", 10, 22, -1},
+		{"before the station (next trip)", 4, 40, -1},
+		{"no seat data", 11, -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeSource{locations: []model.BusLocation{
+				{PlateNo: "경기70아1234", StationSeq: tt.stationSeq, RemainSeatCnt: tt.seats},
+			}}
+			store := &fakeBusStore{missing: []*model.BusArrival{
+				{ID: 7, RouteConfigID: testConfig().ID, BusNumber: "70아1234"},
+			}}
+			c := newTestCollector(source, store, Options{})
+
+			c.fillMissingSeatsAfter(context.Background(), time.Now().Add(-time.Hour))
+
+			got, ok := store.updated[7]
+			if !ok {
+				got = -1
+			}
+			if got != tt.want {
+				t.Errorf("seats_after = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
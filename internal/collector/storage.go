@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"bus_history/internal/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// After storageFailureLimit consecutive failed saves the database is treated as
+// unavailable (disk full, read-only or detached drive) and polling pauses. While
+// paused, a test write is tried every storageProbeInterval; collection resumes once
+// one succeeds.
+const (
+	storageFailureLimit  = 3
+	storageProbeInterval = 30 * time.Second
+)
+
+// StorageStatus tells whether arrivals can currently be saved
+type StorageStatus struct {
+	Unavailable bool       `json:"unavailable"`
+	Error       string     `json:"error,omitempty"` // Last write error while unavailable
+	Since       *time.Time `json:"since,omitempty"` // When collection was paused
+}
+
+// storageHealth counts consecutive save failures across all configs, since they
+// share one database
+type storageHealth struct {
+	mu          sync.Mutex
+	failures    int
+	unavailable bool
+	since       time.Time
+	lastErr     string
+	lastProbe   time.Time
+}
+
+// writeFailed counts a failed save and reports whether it made storage unavailable
+func (s *storageHealth) writeFailed(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	s.lastErr = err.Error()
+	if s.unavailable || s.failures < storageFailureLimit {
+		return false
+	}
+	s.unavailable = true
+	s.since = time.Now()
+	return true
+}
+
+// writeSucceeded resets the failure count and reports whether storage had been unavailable
+func (s *storageHealth) writeSucceeded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recovered := s.unavailable
+	s.failures = 0
+	s.unavailable = false
+	s.lastErr = ""
+	s.since = time.Time{}
+	return recovered
+}
+
+// probeDue reports whether storage is unavailable and no config has probed it within
+// storageProbeInterval; the caller that gets true does the probe
+func (s *storageHealth) probeDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.unavailable || now.Sub(s.lastProbe) < storageProbeInterval {
+		return false
+	}
+	s.lastProbe = now
+	return true
+}
+
+func (s *storageHealth) isUnavailable() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unavailable
+}
+
+func (s *storageHealth) status() StorageStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.unavailable {
+		return StorageStatus{}
+	}
+	since := s.since
+	return StorageStatus{Unavailable: true, Error: s.lastErr, Since: &since}
+}
+
+// StorageStatus reports whether collection is paused because arrivals can't be saved
+func (c *Collector) StorageStatus() StorageStatus {
+	return c.storage.status()
+}
+
+// storageWrite records the outcome of a save, pausing or resuming collection when
+// the database becomes unavailable or writable again. Only errors that mean the
+// file can't be written count towards pausing; others (a constraint, a busy lock)
+// fail that one save, which the caller logs, and the next poll tries again.
+func (c *Collector) storageWrite(err error) {
+	if err != nil {
+		if !repository.IsStorageUnavailable(err) {
+			return
+		}
+		if c.storage.writeFailed(err) {
+			log.Printf("[Collector] ⚠️ Storage unavailable after %d failed saves, pausing collection: %v", storageFailureLimit, err)
+			c.notifyStorage()
+		}
+		return
+	}
+	if c.storage.writeSucceeded() {
+		log.Printf("[Collector] Storage writable again, resuming collection")
+		c.notifyStorage()
+	}
+}
+
+// storagePaused reports whether polling should be skipped because storage is
+// unavailable, probing it first if a probe is due
+func (c *Collector) storagePaused() bool {
+	if !c.storage.probeDue(time.Now()) {
+		return c.storage.isUnavailable()
+	}
+	c.storageWrite(c.busRepo.ProbeWrite())
+	return c.storage.isUnavailable()
+}
+
+func (c *Collector) notifyStorage() {
+	if c.opts.OnStorageChange != nil {
+		c.opts.OnStorageChange(c.storage.status())
+	}
+}
@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"bus_history/internal/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	webhookQueueSize   = 100
+	webhookMaxAttempts = 3
+	webhookBackoff     = 2 * time.Second
+)
+
+// WebhookPayload is the JSON body POSTed for each recorded arrival
+type WebhookPayload struct {
+	RouteID     string    `json:"route_id"`
+	RouteName   string    `json:"route_name"`
+	StationID   string    `json:"station_id"`
+	StationName string    `json:"station_name"`
+	BusNumber   string    `json:"bus_number"`
+	SeatsBefore *int      `json:"seats_before"`
+	SeatsAfter  *int      `json:"seats_after"`
+	ArrivalTime time.Time `json:"arrival_time"`
+}
+
+// webhookSender delivers payloads from a queue in the background so a slow or
+// unreachable endpoint never holds up collection
+type webhookSender struct {
+	url    string
+	client *http.Client
+	queue  chan WebhookPayload
+}
+
+func newWebhookSender(url string, timeout time.Duration) *webhookSender {
+	return &webhookSender{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan WebhookPayload, webhookQueueSize),
+	}
+}
+
+// enqueue schedules a payload for delivery, dropping it if the queue is full
+func (w *webhookSender) enqueue(cfg *model.RouteConfig, arrival *model.BusArrival) {
+	routeName := arrival.ObservedRouteName
+	if routeName == "" {
+		routeName = cfg.RouteName
+	}
+
+	payload := WebhookPayload{
+		RouteID:     cfg.RouteID,
+		RouteName:   routeName,
+		StationID:   cfg.StationID,
+		StationName: cfg.StationName,
+		BusNumber:   arrival.BusNumber,
+		SeatsBefore: copyInt(arrival.SeatsBefore),
+		SeatsAfter:  copyInt(arrival.SeatsAfter),
+		ArrivalTime: arrival.ArrivalTime,
+	}
+
+	select {
+	case w.queue <- payload:
+	default:
+		log.Printf("[Webhook] Queue full, dropping arrival of bus %s", arrival.BusNumber)
+	}
+}
+
+// copyInt detaches a seat value from collector state that keeps changing after enqueue
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	n := *v
+	return &n
+}
+
+// run delivers queued payloads until ctx is done
+func (w *webhookSender) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-w.queue:
+			w.deliver(ctx, payload)
+		}
+	}
+}
+
+func (w *webhookSender) deliver(ctx context.Context, payload WebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("[Webhook] Failed to encode payload: %v", err)
+		return
+	}
+
+	backoff := webhookBackoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		err = w.post(ctx, body)
+		if err == nil {
+			return
+		}
+		log.Printf("[Webhook] Attempt %d/%d failed for bus %s: %v", attempt, webhookMaxAttempts, payload.BusNumber, err)
+
+		if attempt < webhookMaxAttempts {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
+
+func (w *webhookSender) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"bus_history/internal/service"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -37,15 +39,32 @@ type DatabaseConfig struct {
 
 // OpenAPIConfig represents the external API configuration
 type OpenAPIConfig struct {
-	BaseURL    string
-	ServiceKey string
+	BaseURL           string // Gyeonggi arrival service used by the collector
+	GBISBaseURL       string // Root of all Gyeonggi services
+	IncheonBaseURL    string // Root of all Incheon services
+	ServiceKey        string
+	MaxResponseBytes  int64 // 0 = client default
+	MaxRequestsPerSec int   // Shared by all clients, 0 = unlimited
 }
 
 // CollectorConfig represents the data collector configuration
 type CollectorConfig struct {
-	IntervalMs       int
-	RetryMaxAttempts int
-	RetryBackoffMs   int
+	IntervalMs           int
+	RetryMaxAttempts     int
+	RetryBackoffMs       int
+	ApproachStops        int // Poll faster while a bus is within this many stops (0 = disabled)
+	ApproachIntervalMs   int
+	StartJitterMs        int // Max random delay before each config's first poll (0 = disabled)
+	WebhookURL           string
+	WebhookTimeoutMs     int
+	RecordHeartbeats     bool
+	MaxTrackedBuses      int // Per-config cap on tracked buses (0 = unlimited)
+	ScheduleToleranceMin int
+	AggregateOnly        bool
+	AggregateBucketMin   int
+	SeatsFillLookbackMin int // 0 = disabled
+	MinRecordGapSec      int // 0 = disabled
+	WindowWarmupSec      int // 0 = disabled
 }
 
 // LoggingConfig represents the logging configuration
@@ -81,19 +100,85 @@ func LoadFromSettings(settings *AppSettings) *Config {
 		interval = 30000 // Default 30s
 	}
 
+	approachInterval := settings.ApproachIntervalMs
+	if approachInterval <= 0 {
+		approachInterval = 5000 // Default 5s
+	}
+	if approachInterval > interval {
+		approachInterval = interval
+	}
+
+	gbisBaseURL := strings.TrimRight(settings.GBISBaseURL, "/")
+	if gbisBaseURL == "" {
+		gbisBaseURL = service.DefaultGBISBaseURL
+	}
+	incheonBaseURL := strings.TrimRight(settings.IncheonBaseURL, "/")
+	if incheonBaseURL == "" {
+		incheonBaseURL = service.DefaultIncheonBaseURL
+	}
+
+	maxTracked := settings.MaxTrackedBuses
+	if maxTracked == 0 {
+		maxTracked = 50 // Far above what a single stop sees in practice
+	} else if maxTracked < 0 {
+		maxTracked = 0
+	}
+
+	scheduleTolerance := settings.ScheduleToleranceMin
+	if scheduleTolerance <= 0 {
+		scheduleTolerance = 10
+	}
+
+	aggregateBucket := settings.AggregateBucketMin
+	if aggregateBucket <= 0 {
+		aggregateBucket = 10
+	}
+
+	// A bus's seats drift further from the stop's value the longer ago it passed,
+	// so the lookback is kept short
+	seatsFillLookback := settings.SeatsFillLookbackMin
+	if seatsFillLookback == 0 {
+		seatsFillLookback = 10
+	} else if seatsFillLookback < 0 {
+		seatsFillLookback = 0
+	}
+	seatsFillLookback = min(seatsFillLookback, 30)
+
+	webhookTimeout := settings.WebhookTimeoutMs
+	if webhookTimeout <= 0 {
+		webhookTimeout = 5000 // Default 5s
+	}
+
 	return &Config{
 		Database: DatabaseConfig{
 			Type:     "sqlite",
 			FilePath: dbPath,
 		},
 		OpenAPI: OpenAPIConfig{
-			BaseURL:    "https://apis.data.go.kr/6410000/busarrivalservice/v2",
-			ServiceKey: settings.ServiceKey,
+			BaseURL:           gbisBaseURL + service.GBISArrivalServicePath,
+			GBISBaseURL:       gbisBaseURL,
+			IncheonBaseURL:    incheonBaseURL,
+			ServiceKey:        settings.ServiceKey,
+			MaxResponseBytes:  int64(settings.MaxResponseKB) * 1024,
+			MaxRequestsPerSec: settings.MaxRequestsPerSec,
 		},
 		Collector: CollectorConfig{
-			IntervalMs:       interval,
-			RetryMaxAttempts: 3,
-			RetryBackoffMs:   1000,
+			IntervalMs:           interval,
+			RetryMaxAttempts:     3,
+			RetryBackoffMs:       1000,
+			ApproachStops:        settings.ApproachStops,
+			ApproachIntervalMs:   approachInterval,
+			StartJitterMs:        max(settings.StartJitterMs, 0),
+			WebhookURL:           strings.TrimSpace(settings.WebhookURL),
+			WebhookTimeoutMs:     webhookTimeout,
+			RecordHeartbeats:     settings.RecordHeartbeats,
+			MaxTrackedBuses:      maxTracked,
+			ScheduleToleranceMin: scheduleTolerance,
+			AggregateOnly:        settings.AggregateOnly,
+			AggregateBucketMin:   aggregateBucket,
+			SeatsFillLookbackMin: seatsFillLookback,
+			MinRecordGapSec:      max(settings.MinRecordGapSec, 0),
+			WindowWarmupSec:      max(settings.WindowWarmupSec, 0),
 		},
 		Logging: LoggingConfig{
 			Level:  "debug",
@@ -127,8 +212,10 @@ func Load(envPath string) (*Config, error) {
 			Database: getEnv("DB_DATABASE", "bus_history"),
 		},
 		OpenAPI: OpenAPIConfig{
-			BaseURL:    getEnv("API_BASE_URL", "https://apis.data.go.kr/6410000/busarrivalservice/v2"),
-			ServiceKey: getEnv("API_SERVICE_KEY", ""),
+			BaseURL:        getEnv("API_BASE_URL", service.DefaultGBISBaseURL+service.GBISArrivalServicePath),
+			GBISBaseURL:    getEnv("GBIS_BASE_URL", service.DefaultGBISBaseURL),
+			IncheonBaseURL: getEnv("INCHEON_BASE_URL", service.DefaultIncheonBaseURL),
+			ServiceKey:     getEnv("API_SERVICE_KEY", ""),
 		},
 		Collector: CollectorConfig{
 			IntervalMs:       getEnvAsInt("COLLECTOR_INTERVAL_MS", 30000),
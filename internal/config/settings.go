@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bus_history/internal/model"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 type AppSettings struct {
@@ -12,6 +15,100 @@ type AppSettings struct {
 	StartHour   int    `json:"startHour"`  // 0-23
 	EndHour     int    `json:"endHour"`    // 0-23
 	IntervalMs  int    `json:"intervalMs"` // ms
+
+	// Several daily collection periods (e.g. morning and evening peaks);
+	// when set they replace StartHour/EndHour, which are then cleared
+	Windows []model.TimeWindow `json:"windows"`
+
+	// Adaptive polling (0 stops = disabled)
+	ApproachStops      int `json:"approachStops"`
+	ApproachIntervalMs int `json:"approachIntervalMs"` // ms
+
+	// Background refresh of bus positions for the live map (0 = on demand only)
+	LiveLocationIntervalMs int `json:"liveLocationIntervalMs"` // ms
+
+	// Max random delay before a config's first poll, to spread many configs started
+	// together over time (0 = disabled, polls start at once)
+	StartJitterMs int `json:"startJitterMs"`
+
+	// API root overrides for mirrors/mock servers (empty = production)
+	GBISBaseURL    string `json:"gbisBaseUrl"`
+	IncheonBaseURL string `json:"incheonBaseUrl"`
+
+	// Unit of Incheon arrival estimates: "minutes" for endpoints that report them so;
+	// anything else (including "") reads the documented seconds
+	IncheonArrivalUnit string `json:"incheonArrivalUnit"`
+
+	// Upper bound on a single API response body (0 = default 10MB)
+	MaxResponseKB int `json:"maxResponseKB"`
+
+	// POST each recorded arrival to this URL (empty = disabled)
+	WebhookURL       string `json:"webhookUrl"`
+	WebhookTimeoutMs int    `json:"webhookTimeoutMs"` // ms, 0 = default 5s
+
+	// Store a per-minute marker for each successfully polled config (coverage proof)
+	RecordHeartbeats bool `json:"recordHeartbeats"`
+
+	// Max buses tracked at once per config (0 = default 50, < 0 = unlimited)
+	MaxTrackedBuses int `json:"maxTrackedBuses"`
+
+	// Merge configs with the same route, station and direction when the DB is opened
+	DedupeConfigsOnStartup bool `json:"dedupeConfigsOnStartup"`
+
+	// How far from a timetable entry an observed pass still counts for it (0 = default 10)
+	ScheduleToleranceMin int `json:"scheduleToleranceMin"`
+
+	// Store only per-bucket counts and average boarding, never per-bus rows or plates
+	AggregateOnly      bool `json:"aggregateOnly"`
+	AggregateBucketMin int  `json:"aggregateBucketMin"` // 0 = default 10
+
+	// On start, try to fill seats_after of arrivals saved without it this many minutes
+	// back (0 = default 10, < 0 = disabled, at most 30)
+	SeatsFillLookbackMin int `json:"seatsFillLookbackMin"`
+
+	// Skip recording a bus again at a config within this many seconds of its last
+	// record, against a flapping API (0 = disabled; configs can override)
+	MinRecordGapSec int `json:"minRecordGapSec"`
+
+	// For this many seconds after a config's collection starts or its time window
+	// opens, newly seen buses are tracked but their pass isn't recorded, since their
+	// seats_before was caught mid-approach (0 = disabled)
+	WindowWarmupSec int `json:"windowWarmupSec"`
+
+	// Cap on API requests per second across all regions and configs; polls over it
+	// wait for their turn (0 = unlimited)
+	MaxRequestsPerSec int `json:"maxRequestsPerSec"`
+
+	// Use the fixed 30s API timeout instead of adapting it to observed latency
+	FixedTimeout bool `json:"fixedTimeout"`
+
+	// Viewer mode for shared displays: bindings that change configs, settings,
+	// data or collection fail, see App.checkWritable. Turned off in the file only.
+	ReadOnly bool `json:"readOnly"`
+}
+
+// CollectionWindows returns the daily collection windows in effect: Windows when
+// set, otherwise the single StartHour-EndHour window
+func (s *AppSettings) CollectionWindows() []model.TimeWindow {
+	if len(s.Windows) > 0 {
+		return slices.Clone(s.Windows)
+	}
+	return []model.TimeWindow{{StartHour: s.StartHour, EndHour: s.EndHour}}
+}
+
+// NormalizeWindows checks that every window hour is 0-23 and, when Windows are
+// used, clears StartHour/EndHour so that no saved hour is silently ignored
+func (s *AppSettings) NormalizeWindows() error {
+	for _, w := range s.CollectionWindows() {
+		if w.StartHour < 0 || w.StartHour > 23 || w.EndHour < 0 || w.EndHour > 23 {
+			return fmt.Errorf("invalid collection window %s: hours must be 0-23", w)
+		}
+	}
+	if len(s.Windows) > 0 {
+		s.StartHour = 0
+		s.EndHour = 0
+	}
+	return nil
 }
 
 func GetSettingsPath() string {
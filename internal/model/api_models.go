@@ -71,6 +71,25 @@ type RouteStation struct {
 	RegionName  string  `json:"regionName"`
 }
 
+// LatLng is a point of a route's path: one station, in route order. Missing is set,
+// and Lat/Lng left 0, when the API gave no coordinates for the station.
+type LatLng struct {
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	StationID   int     `json:"stationId"`
+	StationName string  `json:"stationName"`
+	StationSeq  int     `json:"stationSeq"`
+	Missing     bool    `json:"missing,omitempty"`
+}
+
+// AnnotatedStation is a route station marked with its monitoring config, if any
+type AnnotatedStation struct {
+	RouteStation
+	Monitored bool   `json:"monitored"`
+	ConfigID  *int64 `json:"configId,omitempty"`
+	IsActive  bool   `json:"isActive"`
+}
+
 // BusLocation represents current bus location
 type BusLocation struct {
 	RouteID       int    `json:"routeId"`
@@ -100,6 +119,7 @@ type APIBusArrival struct {
 // BusArrivalInfo represents bus arrival information from the OpenAPI
 type BusArrivalInfo struct {
 	RouteID       int    `json:"routeId"`
+	RouteName     string `json:"routeName"`
 	StationID     int    `json:"stationId"`
 	StationSeq    int    `json:"staOrder"`
 	PlateNo       string `json:"plateNo"`
@@ -4,13 +4,45 @@ import "time"
 
 // BusArrival represents a bus arrival record
 type BusArrival struct {
-	ID            int64     `json:"id" db:"id"`
-	RouteConfigID int64     `json:"route_config_id" db:"route_config_id"`
-	BusNumber     string    `json:"bus_number" db:"bus_number"`
-	ArrivalTime   time.Time `json:"arrival_time" db:"arrival_time"`
-	SeatsBefore   *int      `json:"seats_before" db:"seats_before"`
-	SeatsAfter    *int      `json:"seats_after" db:"seats_after"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ID                int64           `json:"id" db:"id"`
+	RouteConfigID     int64           `json:"route_config_id" db:"route_config_id"`
+	BusNumber         string          `json:"bus_number" db:"bus_number"`          // Normalized plate, see NormalizePlate
+	RawBusNumber      string          `json:"raw_bus_number" db:"raw_bus_number"`  // Plate exactly as reported by the API
+	ObservedRouteName string          `json:"observed_route_name" db:"route_name"` // Route name reported by the API, empty if unknown
+	Direction         string          `json:"direction" db:"direction"`            // Direction of this pass (상행, 하행, 회차), empty if unknown
+	ArrivalTime       time.Time       `json:"arrival_time" db:"arrival_time"`
+	SeatsBefore       *int            `json:"seats_before" db:"seats_before"`
+	SeatsAfter        *int            `json:"seats_after" db:"seats_after"`
+	IsSuspect         bool            `json:"is_suspect" db:"is_suspect"`                       // Seat values are impossible, excluded from stats
+	ApproachPath      []ApproachPoint `json:"approach_path,omitempty" db:"approach_path"`       // Only stored for configs with RecordApproach
+	RetryCount        *int            `json:"retry_count,omitempty" db:"retry_count"`           // Polls spent waiting for seats_after, nil for older rows
+	ApproachSeconds   *int            `json:"approach_seconds,omitempty" db:"approach_seconds"` // From first appearing in the arrival list to passing, nil for older rows
+	IsScheduled       bool            `json:"is_scheduled" db:"is_scheduled"`                   // Expected from the config's timetable, not observed
+	RecordMode        string          `json:"record_mode" db:"record_mode"`                     // RecordModeArrival rows hold the seats on arrival and no seats_after
+	PassStationSeq    *int            `json:"pass_station_seq" db:"pass_station_seq"`           // Route station sequence the bus was at after passing, nil if unknown
+	PollIntervalMs    *int            `json:"poll_interval_ms" db:"poll_interval_ms"`           // Config's base polling interval when recorded, nil for older rows
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+}
+
+// ApproachPoint is one reading of a bus while it approached the station
+type ApproachPoint struct {
+	Time       time.Time `json:"t"`
+	LocationNo int       `json:"loc"` // Stops away from the station
+	Seats      int       `json:"seats"`
+}
+
+// MaxPlausibleSeats is the largest remaining-seat count a real bus can report
+const MaxPlausibleSeats = 100
+
+// SeatsSuspect reports whether recorded seat counts are impossible
+// (the API uses -1 for "unknown", and nothing has more than MaxPlausibleSeats)
+func SeatsSuspect(before, after *int) bool {
+	for _, v := range []*int{before, after} {
+		if v != nil && (*v < 0 || *v > MaxPlausibleSeats) {
+			return true
+		}
+	}
+	return false
 }
 
 // BusArrivalWithConfig represents a bus arrival with route config information
@@ -18,19 +50,55 @@ type BusArrivalWithConfig struct {
 	BusArrival
 	RouteID     string `json:"route_id" db:"route_id"`
 	RouteName   string `json:"route_name" db:"route_name"`
+	DisplayName string `json:"display_name" db:"display_name"` // The config's display name, falling back to RouteName
 	StationID   string `json:"station_id" db:"station_id"`
 	StationName string `json:"station_name" db:"station_name"`
 	StaOrder    int    `json:"sta_order" db:"sta_order"`
+	Boarding    *int   `json:"boarding" db:"boarding"` // Seats taken at the stop (>= 0), nil if a seat count is missing or suspect
+}
+
+// ContextSample is one timestamped row of an external series (weather, events, ...)
+// attached to arrivals by GetArrivalsWithContext
+type ContextSample struct {
+	Time   time.Time         `json:"time"`
+	Values map[string]string `json:"values"`
+}
+
+// ArrivalWithContext is an arrival with the context sample in effect when it
+// arrived, nil if no sample precedes it closely enough
+type ArrivalWithContext struct {
+	*BusArrivalWithConfig
+	Context *ContextSample `json:"context"`
 }
 
 // BusArrivalFilter represents filters for querying bus arrivals
 type BusArrivalFilter struct {
-	RouteID   string
-	StationID string
-	FromDate  *time.Time
-	ToDate    *time.Time
-	Page      int
-	Limit     int
+	ConfigID   int64 // Optional, matches one config; 0 = any
+	RouteID    string
+	StationID  string
+	RouteGroup string // Optional, matches configs sharing this group label
+	FromDate   *time.Time
+	ToDate     *time.Time
+	Ranges     []DateRange // Optional, OR'd together and combined with FromDate/ToDate
+	Page       int
+	Limit      int
+	// Also match timetable-only rows (is_scheduled); off for everything that counts passes
+	IncludeScheduled bool
+	// Weight averages by each row's poll_interval_ms, so a period polled at 10s
+	// doesn't outweigh one polled at 60s; rows without an interval are left out
+	WeightByInterval bool
+}
+
+// DateRange is an inclusive time period used to build non-contiguous filters (e.g. weekdays only)
+type DateRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// DatePeriod is a DateRange as sent by the frontend, with "2006-01-02" dates
+type DatePeriod struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 // BusArrivalStats represents statistics for bus arrivals
@@ -43,7 +111,266 @@ type BusArrivalStats struct {
 	AvgBefore     float64  `json:"avg_seats_before"`
 	AvgAfter      float64  `json:"avg_seats_after"`
 	AvgBoarding   float64  `json:"avg_boarding"`
+	AvgAlighting  float64  `json:"avg_net_alighting"` // Mean seat gain, unclamped; the useful figure at alighting stops
+	StopType      string   `json:"stop_type"`         // Shared stop type of the matched configs, else mixed
+	PrimaryMetric string   `json:"primary_metric"`    // "boarding" or "net_alighting", per StopType
 	BusiestHours  []string `json:"busiest_hours"`
+	Sampling
+}
+
+// Sampling describes how densely the arrivals behind a set of stats were polled,
+// to judge whether periods collected at different intervals are comparable
+type Sampling struct {
+	Weighted          bool    `json:"weighted"`             // Averages weighted by poll interval
+	StampedArrivals   int     `json:"stamped_arrivals"`     // Arrivals recorded with their poll interval
+	AvgPollIntervalMs float64 `json:"avg_poll_interval_ms"` // Over the stamped arrivals, 0 if none
+	MinPollIntervalMs int     `json:"min_poll_interval_ms"`
+	MaxPollIntervalMs int     `json:"max_poll_interval_ms"`
+}
+
+// TripOptions tunes how GetTripByArrivalID groups a bus's arrivals into one trip.
+// Zero values use the defaults.
+type TripOptions struct {
+	WindowHours    int // Search at most this many hours either side of the arrival (default 6)
+	OrderTolerance int // Allow sta_order to drop by up to this much within a trip (default 0 = strictly increasing)
+	MaxGapMinutes  int // Consecutive arrivals further apart than this start a new trip (default 60)
+}
+
+// HeadwayAlert reports a gap between consecutive arrivals that exceeds the expected headway
+type HeadwayAlert struct {
+	RouteConfigID      int64     `json:"route_config_id"`
+	RouteName          string    `json:"route_name"`
+	StationName        string    `json:"station_name"`
+	ExpectedHeadwayMin int       `json:"expected_headway_min"`
+	ObservedGapMin     float64   `json:"observed_gap_min"`
+	GapStart           time.Time `json:"gap_start"`
+	GapEnd             time.Time `json:"gap_end"`
+	Ongoing            bool      `json:"ongoing"` // No bus has arrived since GapStart
+}
+
+// DailySpan is the earliest and latest observed arrival on one local date
+type DailySpan struct {
+	Date          string    `json:"date"` // 2006-01-02
+	FirstArrival  time.Time `json:"first_arrival"`
+	LastArrival   time.Time `json:"last_arrival"`
+	TotalArrivals int       `json:"total_arrivals"`
+}
+
+// ServiceSpanReport wraps daily spans with the caveat needed to read them correctly
+type ServiceSpanReport struct {
+	Spans []DailySpan `json:"spans"`
+	// Spans are observed, not scheduled: they are clipped by the collection
+	// time window and miss any bus that passed while collection was stopped.
+	Note    string       `json:"note"`
+	Windows []TimeWindow `json:"windows"` // Collection windows in effect
+}
+
+// CoverageGap is a period in which a config was not polled
+type CoverageGap struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes int       `json:"minutes"`
+}
+
+// CoverageReport lists the periods a config went without collection heartbeats
+type CoverageReport struct {
+	Gaps       []CoverageGap `json:"gaps"`
+	Heartbeats int           `json:"heartbeats"` // Minutes with a heartbeat in the range
+	// Heartbeats are only written while recordHeartbeats is on; with it off,
+	// every minute since it was turned off shows up as a gap.
+	Recording bool         `json:"recording"`
+	Windows   []TimeWindow `json:"windows"` // Collection windows in effect; gaps outside them are expected
+}
+
+// DailyBoarding is the total number of passengers boarding on one local date
+type DailyBoarding struct {
+	Date          string `json:"date"` // 2006-01-02
+	TotalBoarding int    `json:"total_boarding"`
+	TotalArrivals int    `json:"total_arrivals"`
+}
+
+// DailyBoardingReport wraps daily boarding totals with the caveat needed to read them
+type DailyBoardingReport struct {
+	Days []DailyBoarding `json:"days"`
+	// Totals only cover buses recorded inside the collection time window
+	// and while collection was running, so they are a lower bound.
+	Note    string       `json:"note"`
+	Windows []TimeWindow `json:"windows"` // Collection windows in effect
+}
+
+// DailyReport is the printable one-day summary across active configs
+type DailyReport struct {
+	Date          string             `json:"date"` // 2006-01-02
+	TotalArrivals int                `json:"total_arrivals"`
+	Configs       []DailyReportEntry `json:"configs"` // Only configs with arrivals that day
+	Windows       []TimeWindow       `json:"windows"` // Collection windows in effect
+}
+
+// DailyReportEntry is one config's line in a DailyReport
+type DailyReportEntry struct {
+	ConfigID      int64     `json:"config_id"`
+	RouteName     string    `json:"route_name"`
+	StationName   string    `json:"station_name"`
+	Direction     string    `json:"direction"`
+	StopType      string    `json:"stop_type"`
+	TotalArrivals int       `json:"total_arrivals"`
+	AvgBoarding   float64   `json:"avg_boarding"`
+	BusiestHour   string    `json:"busiest_hour"` // e.g. 08:00-09:00
+	FirstArrival  time.Time `json:"first_arrival"`
+	LastArrival   time.Time `json:"last_arrival"`
+}
+
+// RetryBucket counts the arrivals of a config that needed a given number of
+// seats_after retries. TimedOut rows gave up and were saved without seats_after.
+type RetryBucket struct {
+	RetryCount int  `json:"retry_count"`
+	TimedOut   bool `json:"timed_out"`
+	Count      int  `json:"count"`
+}
+
+// ApproachDurationStats is the distribution of how long a config's buses took
+// from first appearing in the arrival list to passing the station
+type ApproachDurationStats struct {
+	Count         int         `json:"count"`
+	MinSeconds    int         `json:"min_seconds"`
+	P25Seconds    int         `json:"p25_seconds"`
+	MedianSeconds int         `json:"median_seconds"`
+	P75Seconds    int         `json:"p75_seconds"`
+	P90Seconds    int         `json:"p90_seconds"`
+	MaxSeconds    int         `json:"max_seconds"`
+	MeanSeconds   float64     `json:"mean_seconds"`
+	ByMinute      map[int]int `json:"by_minute"` // Whole minutes -> arrivals
+}
+
+// StationRank is one monitored station of a route in a GetBusiestStations ranking
+type StationRank struct {
+	ConfigID      int64   `json:"config_id"`
+	StationID     string  `json:"station_id"`
+	StationName   string  `json:"station_name"`
+	StaOrder      int     `json:"sta_order"`
+	Direction     string  `json:"direction"`
+	TotalBoarding int     `json:"total_boarding"`
+	TotalArrivals int     `json:"total_arrivals"`
+	AvgBoarding   float64 `json:"avg_boarding"`
+}
+
+// RouteSnapshot is a route's monitored stations at a moment: each station with
+// the arrivals recorded there within a window around that time
+type RouteSnapshot struct {
+	RouteID       string            `json:"route_id"`
+	At            time.Time         `json:"at"`
+	WindowMinutes int               `json:"window_minutes"` // Arrivals within ±window of At are included
+	Stations      []SnapshotStation `json:"stations"`       // By sta_order
+}
+
+// SnapshotStation is one row of a RouteSnapshot
+type SnapshotStation struct {
+	ConfigID    int64          `json:"config_id"`
+	StationID   string         `json:"station_id"`
+	StationName string         `json:"station_name"`
+	StaOrder    int            `json:"sta_order"`
+	Direction   string         `json:"direction"`
+	Arrivals    []SnapshotCell `json:"arrivals"` // Oldest first, empty when no bus passed in the window
+}
+
+// SnapshotCell is one arrival in a RouteSnapshot
+type SnapshotCell struct {
+	ArrivalID   int64     `json:"arrival_id"`
+	BusNumber   string    `json:"bus_number"`
+	ArrivalTime time.Time `json:"arrival_time"`
+	SeatsBefore *int      `json:"seats_before"`
+	SeatsAfter  *int      `json:"seats_after"`
+	Boarding    *int      `json:"boarding"`
+}
+
+// PurgePreview describes the arrivals a retention purge of Days days would delete:
+// those before Cutoff. OldestDate/NewestDate are "2006-01-02", empty when nothing matches.
+type PurgePreview struct {
+	Days       int                `json:"days"`
+	Cutoff     time.Time          `json:"cutoff"`
+	TotalRows  int64              `json:"total_rows"`
+	OldestDate string             `json:"oldest_date"`
+	NewestDate string             `json:"newest_date"`
+	Configs    []PurgeConfigCount `json:"configs"` // Most affected rows first
+}
+
+// PurgeConfigCount is one config's share of a PurgePreview
+type PurgeConfigCount struct {
+	ConfigID    int64  `json:"config_id"`
+	RouteName   string `json:"route_name"`
+	StationName string `json:"station_name"`
+	Rows        int64  `json:"rows"`
+	OldestDate  string `json:"oldest_date"`
+	NewestDate  string `json:"newest_date"`
+}
+
+// ScheduleAdherence scores a config's observed passes against a reference timetable.
+// Each scheduled time is matched to the nearest unmatched observed arrival within
+// the tolerance; deviations are observed minus scheduled, so early is negative.
+type ScheduleAdherence struct {
+	Scheduled       int     `json:"scheduled"`         // Scheduled times compared
+	OnTime          int     `json:"on_time"`           // Matched within EarlyMin early to LateMin late
+	Early           int     `json:"early"`             // Matched, more than EarlyMin early
+	Late            int     `json:"late"`              // Matched, more than LateMin late
+	Missed          int     `json:"missed"`            // No observed arrival within the tolerance
+	AvgDeviationMin float64 `json:"avg_deviation_min"` // Mean deviation of matched times, 0 if none matched
+	OnTimeRate      float64 `json:"on_time_rate"`      // OnTime / Scheduled, 0 if nothing was scheduled
+	ToleranceMin    int     `json:"tolerance_min"`
+	EarlyMin        int     `json:"early_min"`
+	LateMin         int     `json:"late_min"`
+	Days            int     `json:"days"` // Dates a daily timetable was applied to
+}
+
+// HourBoardingCorrelation tells how strongly boarding follows the hour of day at a stop
+type HourBoardingCorrelation struct {
+	Coefficient float64 `json:"coefficient"` // Pearson r in [-1, 1], 0 when undefined
+	SampleSize  int     `json:"sample_size"` // Arrivals with usable seat values
+}
+
+// RegularityScore summarizes how evenly buses are spaced at a stop
+type RegularityScore struct {
+	CoefficientOfVariation float64 `json:"coefficient_of_variation"` // Std dev / mean of headways; low = even, high = bunching
+	SampleSize             int     `json:"sample_size"`              // Headways used
+}
+
+// ArrivalAggregate is one time bucket of a config recorded in aggregate-only mode,
+// where no per-bus rows (and so no plate numbers) are stored
+type ArrivalAggregate struct {
+	RouteConfigID   int64     `json:"route_config_id"`
+	Bucket          time.Time `json:"bucket"` // Start of the bucket
+	ArrivalCount    int       `json:"arrival_count"`
+	AvgBoarding     *float64  `json:"avg_boarding"`     // nil if no arrival in the bucket had usable seats
+	BoardingSamples int       `json:"boarding_samples"` // Arrivals that AvgBoarding is over
+}
+
+// SeriesPoint is one point of a charted time series. For raw points Time is the
+// arrival and Count 1; for downsampled points Time is the start of the bucket,
+// Value the mean over it and Count the number of arrivals averaged.
+type SeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Count int       `json:"count"`
+}
+
+// HourlyComparison is the observed arrival count of one clock hour against the expected count
+type HourlyComparison struct {
+	Hour      string  `json:"hour"` // Local "YYYY-MM-DD HH"
+	Actual    int     `json:"actual"`
+	Expected  float64 `json:"expected"`
+	Shortfall bool    `json:"shortfall"` // Actual is below ShortfallRatio of Expected
+}
+
+// ShortfallRatio is the share of expected arrivals below which an hour counts as a shortfall
+const ShortfallRatio = 0.5
+
+// SystemOverview is the landing-page summary across all configs
+type SystemOverview struct {
+	TotalConfigs    int        `json:"total_configs"`
+	ActiveConfigs   int        `json:"active_configs"`
+	TotalArrivals   int64      `json:"total_arrivals"`
+	ArrivalsLast24h int64      `json:"arrivals_last_24h"`
+	EarliestArrival *time.Time `json:"earliest_arrival"`
+	LatestArrival   *time.Time `json:"latest_arrival"`
 }
 
 // APIResponse is a generic API response wrapper
@@ -55,3 +382,56 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 	Message string      `json:"message,omitempty"`
 }
+
+// RepairReport describes what RepairDatabase found and fixed
+type RepairReport struct {
+	IntegrityOK          bool     `json:"integrity_ok"`
+	IntegrityErrors      []string `json:"integrity_errors"`
+	MissingIndexes       []string `json:"missing_indexes"`        // Before repair
+	RecreatedIndexes     []string `json:"recreated_indexes"`      // Missing ones that exist after repair
+	OrphansRemoved       int64    `json:"orphans_removed"`        // Arrivals whose config row was gone
+	OrphanCleanupSkipped bool     `json:"orphan_cleanup_skipped"` // Orphans kept because the integrity check failed
+}
+
+// ClockDriftReport flags signs that the host clock or timezone was off while collecting.
+// created_at is stamped in UTC by the database, arrival_time by the collector in the
+// host's zone with its offset, so the two should stay within a few minutes.
+type ClockDriftReport struct {
+	Offsets     []OffsetSpan `json:"offsets"`      // UTC offsets arrivals were recorded under, oldest first
+	Consistent  bool         `json:"consistent"`   // At most one offset was ever in effect
+	SkewedCount int64        `json:"skewed_count"` // Arrivals whose arrival_time and created_at diverge beyond the threshold
+	Skewed      []ClockSkew  `json:"skewed"`       // The most recent of them
+	MaxSkewMin  int          `json:"max_skew_min"` // Threshold used
+}
+
+// OffsetSpan is a UTC offset arrivals were recorded under and when
+type OffsetSpan struct {
+	OffsetMin int       `json:"offset_min"` // e.g. 540 for +09:00
+	Count     int64     `json:"count"`
+	First     time.Time `json:"first"` // created_at, UTC
+	Last      time.Time `json:"last"`
+}
+
+// ClockSkew is an arrival whose arrival_time is implausibly far from when it was stored
+type ClockSkew struct {
+	ArrivalID   int64     `json:"arrival_id"`
+	ConfigID    int64     `json:"config_id"`
+	ArrivalTime time.Time `json:"arrival_time"`
+	CreatedAt   time.Time `json:"created_at"`
+	SkewMinutes float64   `json:"skew_minutes"` // created_at - arrival_time
+}
+
+// SchemaInfo describes the migration state of the database
+type SchemaInfo struct {
+	Version       int                `json:"version"`        // Highest applied migration
+	LatestVersion int                `json:"latest_version"` // Highest migration this build knows
+	Applied       []AppliedMigration `json:"applied"`
+	Pending       []int              `json:"pending"` // Versions not applied yet, normally empty
+}
+
+// AppliedMigration is one row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Statement string    `json:"statement"`
+	AppliedAt time.Time `json:"applied_at"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// plateRegionPrefixes are the region names that may precede a plate number.
+// The same vehicle is reported as both "경기70아1234" and "70아1234" depending on the API.
+var plateRegionPrefixes = []string{
+	"서울", "경기", "인천", "부산", "대구", "광주", "대전", "울산", "세종",
+	"강원", "충북", "충남", "전북", "전남", "경북", "경남", "제주",
+}
+
+// NormalizePlate canonicalizes the spelling of a plate number: whitespace and
+// hyphens are removed. A region prefix is kept, since "경기70아1234" and
+// "서울70아1234" are different vehicles; see NormalizePlateIn.
+func NormalizePlate(plate string) string {
+	var b strings.Builder
+	for _, r := range plate {
+		if unicode.IsSpace(r) || r == '-' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizePlateIn normalizes a plate reported for a config of the given region, so
+// one vehicle maps to one string: a prefix naming the config's own region is stripped,
+// as the APIs report a local bus both with and without it. Plates of other regions
+// keep their prefix.
+func NormalizePlateIn(plate, region string) string {
+	normalized := NormalizePlate(plate)
+	if rest, ok := strings.CutPrefix(normalized, PlateRegion(region)); ok && rest != "" {
+		return rest
+	}
+	return normalized
+}
+
+// PlateRegion returns the region name plates of a config region are prefixed with.
+// Configs without a region are collected as 경기.
+func PlateRegion(region string) string {
+	if region == "인천" || region == "incheon" {
+		return "인천"
+	}
+	return "경기"
+}
+
+// PlateConfigRegions lists the config region values whose plates are stored with
+// the given prefix stripped
+func PlateConfigRegions(prefix string) []string {
+	switch prefix {
+	case "인천":
+		return []string{"인천", "incheon"}
+	case "경기":
+		return []string{"경기", ""}
+	}
+	return nil
+}
+
+// SplitPlateRegion splits a normalized plate into its known region prefix and the
+// rest; prefix is "" when the plate has none
+func SplitPlateRegion(plate string) (prefix, rest string) {
+	for _, p := range plateRegionPrefixes {
+		if r, ok := strings.CutPrefix(plate, p); ok && r != "" {
+			return p, r
+		}
+	}
+	return "", plate
+}
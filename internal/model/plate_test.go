@@ -0,0 +1,68 @@
+package model
+
+import "testing"
+
+func TestNormalizePlate(t *testing.T) {
+	tests := []struct {
+		plate string
+		want  string
+	}{
+		{"70아1234", "70아1234"},
+		{" 70아 1234 ", "70아1234"},
+		{"70아-1234", "70아1234"},
+		{"경기70아1234", "경기70아1234"},
+		{"경기 70아 1234", "경기70아1234"},
+		{"서울70아1234", "서울70아1234"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizePlate(tt.plate); got != tt.want {
+			t.Errorf("NormalizePlate(%q) = %q, want %q", tt.plate, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePlateIn(t *testing.T) {
+	tests := []struct {
+		plate  string
+		region string
+		want   string
+	}{
+		{"경기70아1234", "경기", "70아1234"},
+		{"경기 70아-1234", "경기", "70아1234"},
+		{"70아1234", "경기", "70아1234"},
+		{"경기70아1234", "", "70아1234"}, // Configs without a region are 경기
+		{"서울70아1234", "경기", "서울70아1234"},
+		{"인천70아1234", "인천", "70아1234"},
+		{"인천70아1234", "incheon", "70아1234"},
+		{"경기70아1234", "인천", "경기70아1234"},
+		{"경기", "경기", "경기"}, // Nothing left after the prefix
+	}
+	for _, tt := range tests {
+		if got := NormalizePlateIn(tt.plate, tt.region); got != tt.want {
+			t.Errorf("NormalizePlateIn(%q, %q) = %q, want %q", tt.plate, tt.region, got, tt.want)
+		}
+	}
+
+	// Same digits in different regions must stay different vehicles
+	if NormalizePlateIn("경기70아1234", "경기") == NormalizePlateIn("서울70아1234", "경기") {
+		t.Error("경기 and 서울 plates with the same number were merged")
+	}
+}
+
+func TestSplitPlateRegion(t *testing.T) {
+	tests := []struct {
+		plate, prefix, rest string
+	}{
+		{"경기70아1234", "경기", "70아1234"},
+		{"서울70아1234", "서울", "70아1234"},
+		{"70아1234", "", "70아1234"},
+		{"경기", "", "경기"},
+	}
+	for _, tt := range tests {
+		prefix, rest := SplitPlateRegion(tt.plate)
+		if prefix != tt.prefix || rest != tt.rest {
+			t.Errorf("SplitPlateRegion(%q) = %q, %q, want %q, %q", tt.plate, prefix, rest, tt.prefix, tt.rest)
+		}
+	}
+}
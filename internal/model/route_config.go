@@ -9,16 +9,127 @@ import (
 
 // RouteConfig represents a monitoring configuration for a bus route at a station
 type RouteConfig struct {
-	ID          int64     `json:"id" db:"id"`
-	RouteID     string    `json:"route_id" db:"route_id"`
-	RouteName   string    `json:"route_name" db:"route_name"`
-	StationID   string    `json:"station_id" db:"station_id"`
-	StationName string    `json:"station_name" db:"station_name"`
-	Direction   string    `json:"direction" db:"direction"`
-	StaOrder    int       `json:"sta_order" db:"sta_order"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int64     `json:"id" db:"id"`
+	RouteID            string    `json:"route_id" db:"route_id"`
+	RouteName          string    `json:"route_name" db:"route_name"`
+	DisplayName        string    `json:"display_name" db:"display_name"` // Optional nickname shown instead of RouteName, "" = none
+	StationID          string    `json:"station_id" db:"station_id"`
+	StationName        string    `json:"station_name" db:"station_name"`
+	Direction          string    `json:"direction" db:"direction"`
+	RouteType          string    `json:"route_type" db:"route_type"`   // Route type name from the search API (e.g. 직행좌석형시내버스)
+	RouteGroup         string    `json:"route_group" db:"route_group"` // Optional label shared by sibling routes (e.g. 1000번대)
+	StaOrder           int       `json:"sta_order" db:"sta_order"`
+	IsActive           bool      `json:"is_active" db:"is_active"`
+	ExpectedHeadwayMin int       `json:"expected_headway_min" db:"expected_headway_min"` // Scheduled minutes between buses, 0 = no alerts
+	IntervalMs         *int      `json:"interval_ms" db:"interval_ms"`                   // Polling interval override, nil = global interval
+	MinRecordGapSec    *int      `json:"min_record_gap_sec" db:"min_record_gap_sec"`     // Min seconds between records of one bus, nil = global setting
+	RecordApproach     bool      `json:"record_approach" db:"record_approach"`           // Store each arrival's approach path (larger rows)
+	StopType           string    `json:"stop_type" db:"stop_type"`                       // StopTypeBoarding, StopTypeAlighting or StopTypeMixed
+	RecordMode         string    `json:"record_mode" db:"record_mode"`                   // RecordModePass or RecordModeArrival
+	Region             string    `json:"region" db:"region"`                             // 경기 or 인천; "" (older configs) is collected as 경기
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Label is the name to show for the config's route: DisplayName if set, else RouteName
+func (c *RouteConfig) Label() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return c.RouteName
+}
+
+// Stop types decide how a config's seat delta is read
+const (
+	StopTypeBoarding  = "boarding"  // Passengers mostly get on; seat drop = boarding
+	StopTypeAlighting = "alighting" // Near a terminal; seat gain = net alighting
+	StopTypeMixed     = "mixed"     // Both; reported as boarding like before stop types existed
+)
+
+// Record modes decide when a config's arrivals are recorded and what their seats mean
+const (
+	RecordModePass    = "pass"    // Once the bus has passed, with seats before and after: how many boarded
+	RecordModeArrival = "arrival" // When the bus is first 0-1 stops away, with the seats then: will I get a seat
+)
+
+// ValidRecordMode reports whether s is one of the record mode constants
+func ValidRecordMode(s string) bool {
+	return s == RecordModePass || s == RecordModeArrival
+}
+
+// TimeWindow is a daily collection period from StartHour up to (not including) EndHour.
+// A window with StartHour > EndHour crosses midnight; 0-0 means all day.
+type TimeWindow struct {
+	StartHour int `json:"startHour"` // 0-23
+	EndHour   int `json:"endHour"`   // 0-23
+}
+
+// Contains reports whether the hour of day falls inside the window
+func (w TimeWindow) Contains(hour int) bool {
+	switch {
+	case w.StartHour == 0 && w.EndHour == 0:
+		return true // 24 hours
+	case w.StartHour < w.EndHour:
+		return hour >= w.StartHour && hour < w.EndHour
+	case w.StartHour > w.EndHour:
+		// Cross-day: 22 to 2 means [22, 23, 0, 1]
+		return hour >= w.StartHour || hour < w.EndHour
+	}
+	return hour == w.StartHour
+}
+
+func (w TimeWindow) String() string {
+	return fmt.Sprintf("%d-%d", w.StartHour, w.EndHour)
+}
+
+// ValidStopType reports whether s is one of the stop type constants
+func ValidStopType(s string) bool {
+	return s == StopTypeBoarding || s == StopTypeAlighting || s == StopTypeMixed
+}
+
+// PrimaryMetric names the statistic shown first for a stop type
+func PrimaryMetric(stopType string) string {
+	if stopType == StopTypeAlighting {
+		return "net_alighting"
+	}
+	return "boarding"
+}
+
+// ScheduleException overrides a config's collection window for one date
+type ScheduleException struct {
+	ConfigID int64  `json:"config_id"`
+	Date     string `json:"date"`    // YYYY-MM-DD, local time
+	Enabled  bool   `json:"enabled"` // true = collect all day, false = skip the day
+}
+
+// RouteConfigWithStats is a config with a summary of what it has recorded
+type RouteConfigWithStats struct {
+	RouteConfig
+	ArrivalCount  int64      `json:"arrival_count"`
+	LastArrivalAt *time.Time `json:"last_arrival_at"` // nil if nothing recorded yet
+	LastError     string     `json:"last_error"`      // Most recent collection error, "" if none or not collecting
+	LastErrorAt   *time.Time `json:"last_error_at"`
+}
+
+// ImportPlan is the dry-run result of importing configs, matched on route and station
+type ImportPlan struct {
+	New       []*RouteConfig   `json:"new"`       // Not present yet; will be created
+	Existing  []*RouteConfig   `json:"existing"`  // Already present with the same direction; skipped
+	Conflicts []ImportConflict `json:"conflicts"` // Present with a different direction; skipped
+}
+
+// ImportConflict pairs an imported config with the existing one it disagrees with
+type ImportConflict struct {
+	Imported *RouteConfig `json:"imported"`
+	Existing *RouteConfig `json:"existing"`
+}
+
+// ConflictPair reports configs of one route whose sta_order can't be right.
+// B is nil when the problem is with A alone (a missing sta_order).
+type ConflictPair struct {
+	A      *RouteConfig `json:"a"`
+	B      *RouteConfig `json:"b"`
+	Reason string       `json:"reason"` // "duplicate" or "missing"
 }
 
 // CreateRouteConfigRequest represents the request to create a new route config
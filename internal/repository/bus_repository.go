@@ -3,7 +3,10 @@ package repository
 import (
 	"bus_history/internal/model"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 )
@@ -18,13 +21,72 @@ func NewBusRepository(db *sql.DB) *BusRepository {
 	return &BusRepository{db: db}
 }
 
+// arrivalWithConfigColumns is the column list matched by scanArrivalWithConfig.
+// Queries must alias bus_arrivals as ba and route_configs as rc.
+// The route name observed by the API wins over the one typed into the config, and
+// the direction recorded for the pass over the config's direction. The display
+// name is the config's override, falling back to that route name.
+// Boarding is computed here so every reader shares one definition.
+const arrivalWithConfigColumns = `ba.id, ba.route_config_id, ba.bus_number, COALESCE(ba.raw_bus_number, ba.bus_number),
+	COALESCE(ba.route_name, ''), COALESCE(NULLIF(ba.direction, ''), rc.direction),
+	ba.arrival_time, ba.seats_before, ba.seats_after, ba.is_suspect, ba.is_scheduled, ba.record_mode, ba.pass_station_seq, ba.poll_interval_ms, ba.created_at,
+	rc.route_id, COALESCE(NULLIF(ba.route_name, ''), rc.route_name),
+	COALESCE(NULLIF(rc.display_name, ''), NULLIF(ba.route_name, ''), rc.route_name), rc.station_id, rc.station_name, rc.sta_order,
+	` + boardingExpr
+
+// boardingExpr is the passengers boarding at one arrival: the seat drop, clamped at 0,
+// and NULL when either seat count is missing or the row is suspect
+const boardingExpr = `CASE WHEN ba.is_suspect = 0 THEN MAX(ba.seats_before - ba.seats_after, 0) END`
+
+// netAlightingExpr is the seat gain at one arrival, unclamped so that stops where
+// more people get off than on average out positive; NULL like boardingExpr
+const netAlightingExpr = `CASE WHEN ba.is_suspect = 0 THEN ba.seats_after - ba.seats_before END`
+
+// scanArrivalWithConfig scans a row selected with arrivalWithConfigColumns
+func scanArrivalWithConfig(row rowScanner) (*model.BusArrivalWithConfig, error) {
+	var a model.BusArrivalWithConfig
+	err := row.Scan(
+		&a.ID, &a.RouteConfigID, &a.BusNumber, &a.RawBusNumber,
+		&a.ObservedRouteName, &a.Direction, &a.ArrivalTime, &a.SeatsBefore, &a.SeatsAfter, &a.IsSuspect, &a.IsScheduled, &a.RecordMode, &a.PassStationSeq, &a.PollIntervalMs, &a.CreatedAt,
+		&a.RouteID, &a.RouteName, &a.DisplayName, &a.StationID, &a.StationName, &a.StaOrder, &a.Boarding,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
 // Create creates a new bus arrival record
 func (r *BusRepository) Create(arrival *model.BusArrival) error {
-	query := `INSERT INTO bus_arrivals (route_config_id, bus_number, arrival_time, seats_before, seats_after) 
-			  VALUES (?, ?, ?, ?, ?)`
+	arrival.IsSuspect = model.SeatsSuspect(arrival.SeatsBefore, arrival.SeatsAfter)
+	if arrival.RawBusNumber == "" {
+		arrival.RawBusNumber = arrival.BusNumber
+	}
+	arrival.BusNumber = model.NormalizePlate(arrival.BusNumber)
+	if arrival.RecordMode == "" {
+		arrival.RecordMode = model.RecordModePass
+	}
+
+	var approachPath interface{}
+	if len(arrival.ApproachPath) > 0 {
+		data, err := json.Marshal(arrival.ApproachPath)
+		if err != nil {
+			return fmt.Errorf("failed to encode approach path: %w", err)
+		}
+		approachPath = string(data)
+	}
+
+	// The offset arrival_time was taken in, so a timezone change on the host shows up.
+	// The time itself is stored in displayZone so that range filters compare like with like.
+	_, offset := arrival.ArrivalTime.Zone()
+	arrivalTime := dbTime(arrival.ArrivalTime)
 
-	result, err := r.db.Exec(query, arrival.RouteConfigID, arrival.BusNumber,
-		arrival.ArrivalTime, arrival.SeatsBefore, arrival.SeatsAfter)
+	query := `INSERT INTO bus_arrivals (route_config_id, bus_number, raw_bus_number, route_name, arrival_time, seats_before, seats_after, is_suspect, approach_path, retry_count, direction, is_scheduled, approach_seconds, utc_offset_min, record_mode, pass_station_seq, poll_interval_ms) 
+			  VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.Exec(query, arrival.RouteConfigID, arrival.BusNumber, arrival.RawBusNumber, arrival.ObservedRouteName,
+		arrivalTime, arrival.SeatsBefore, arrival.SeatsAfter, arrival.IsSuspect, approachPath, arrival.RetryCount, arrival.Direction,
+		arrival.IsScheduled, arrival.ApproachSeconds, offset/60, arrival.RecordMode, arrival.PassStationSeq, arrival.PollIntervalMs)
 	if err != nil {
 		return fmt.Errorf("failed to create bus arrival: %w", err)
 	}
@@ -45,24 +107,64 @@ func (r *BusRepository) UpdateSeatsAfter(id int64, seatsAfter int) error {
 	if err != nil {
 		return fmt.Errorf("failed to update seats after: %w", err)
 	}
+
+	flagQuery := "UPDATE bus_arrivals SET is_suspect = (CASE WHEN " + suspectSeatsCondition + " THEN 1 ELSE 0 END) WHERE id = ?"
+	if _, err := r.db.Exec(flagQuery, id); err != nil {
+		return fmt.Errorf("failed to update suspect flag: %w", err)
+	}
 	return nil
 }
 
+// LastRecordedAt returns the arrival_time of the latest observed arrival of a bus at
+// a config, nil if it was never recorded there
+func (r *BusRepository) LastRecordedAt(configID int64, busNumber string) (*time.Time, error) {
+	var last time.Time
+	err := r.db.QueryRow(`SELECT arrival_time FROM bus_arrivals
+			  WHERE route_config_id = ? AND bus_number = ? AND is_scheduled = 0
+			  ORDER BY arrival_time DESC LIMIT 1`, configID, model.NormalizePlate(busNumber)).Scan(&last)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last arrival of bus: %w", err)
+	}
+	return &last, nil
+}
+
+// FindMissingSeatsAfter lists observed arrivals since the given time that were
+// saved without seats_after, oldest first. Rows recorded on arrival never have one.
+func (r *BusRepository) FindMissingSeatsAfter(since time.Time) ([]*model.BusArrival, error) {
+	query := `SELECT id, route_config_id, bus_number, arrival_time FROM bus_arrivals
+			  WHERE seats_after IS NULL AND is_scheduled = 0 AND bus_number != '' AND record_mode = 'pass'
+			  AND arrival_time >= ?
+			  ORDER BY arrival_time ASC`
+
+	rows, err := r.db.Query(query, dbTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query arrivals missing seats_after: %w", err)
+	}
+	defer rows.Close()
+
+	arrivals := []*model.BusArrival{}
+	for rows.Next() {
+		var a model.BusArrival
+		if err := rows.Scan(&a.ID, &a.RouteConfigID, &a.BusNumber, &a.ArrivalTime); err != nil {
+			return nil, fmt.Errorf("failed to scan arrival: %w", err)
+		}
+		arrivals = append(arrivals, &a)
+	}
+
+	return arrivals, rows.Err()
+}
+
 // FindByID retrieves a bus arrival by ID with config info
 func (r *BusRepository) FindByID(id int64) (*model.BusArrivalWithConfig, error) {
-	query := `SELECT ba.id, ba.route_config_id, ba.bus_number, ba.arrival_time, 
-					 ba.seats_before, ba.seats_after, ba.created_at,
-					 rc.route_id, rc.route_name, rc.station_id, rc.station_name, rc.sta_order
+	query := `SELECT ` + arrivalWithConfigColumns + `
 			  FROM bus_arrivals ba
 			  JOIN route_configs rc ON ba.route_config_id = rc.id
 			  WHERE ba.id = ?`
 
-	var arrival model.BusArrivalWithConfig
-	err := r.db.QueryRow(query, id).Scan(
-		&arrival.ID, &arrival.RouteConfigID, &arrival.BusNumber, &arrival.ArrivalTime,
-		&arrival.SeatsBefore, &arrival.SeatsAfter, &arrival.CreatedAt,
-		&arrival.RouteID, &arrival.RouteName, &arrival.StationID, &arrival.StationName, &arrival.StaOrder,
-	)
+	arrival, err := scanArrivalWithConfig(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -70,16 +172,21 @@ func (r *BusRepository) FindByID(id int64) (*model.BusArrivalWithConfig, error)
 		return nil, fmt.Errorf("failed to query bus arrival: %w", err)
 	}
 
-	return &arrival, nil
+	return arrival, nil
 }
 
-// FindByFilter retrieves bus arrivals with filters
-func (r *BusRepository) FindByFilter(filter model.BusArrivalFilter) ([]*model.BusArrivalWithConfig, int64, error) {
-	// Build query
-	baseQuery := `FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id`
+// filterWhereClause builds the WHERE clause (including " WHERE ") for a filter
+func filterWhereClause(filter model.BusArrivalFilter) (string, []interface{}) {
 	where := []string{}
 	args := []interface{}{}
 
+	if !filter.IncludeScheduled {
+		where = append(where, "ba.is_scheduled = 0")
+	}
+	if filter.ConfigID != 0 {
+		where = append(where, "ba.route_config_id = ?")
+		args = append(args, filter.ConfigID)
+	}
 	if filter.RouteID != "" {
 		where = append(where, "rc.route_id = ?")
 		args = append(args, filter.RouteID)
@@ -88,19 +195,35 @@ func (r *BusRepository) FindByFilter(filter model.BusArrivalFilter) ([]*model.Bu
 		where = append(where, "rc.station_id = ?")
 		args = append(args, filter.StationID)
 	}
+	if filter.RouteGroup != "" {
+		where = append(where, "rc.route_group = ?")
+		args = append(args, filter.RouteGroup)
+	}
 	if filter.FromDate != nil {
 		where = append(where, "ba.arrival_time >= ?")
-		args = append(args, filter.FromDate)
+		args = append(args, dbTime(*filter.FromDate))
 	}
 	if filter.ToDate != nil {
 		where = append(where, "ba.arrival_time <= ?")
-		args = append(args, filter.ToDate)
+		args = append(args, dbTime(*filter.ToDate))
+	}
+	if len(filter.Ranges) > 0 {
+		clause, rangeArgs := dateRangesClause(filter.Ranges)
+		where = append(where, clause)
+		args = append(args, rangeArgs...)
 	}
 
-	whereClause := ""
-	if len(where) > 0 {
-		whereClause = " WHERE " + strings.Join(where, " AND ")
+	if len(where) == 0 {
+		return "", args
 	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// FindByFilter retrieves bus arrivals with filters
+func (r *BusRepository) FindByFilter(filter model.BusArrivalFilter) ([]*model.BusArrivalWithConfig, int64, error) {
+	// Build query
+	baseQuery := `FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id`
+	whereClause, args := filterWhereClause(filter)
 
 	// Get total count
 	countQuery := "SELECT COUNT(*) " + baseQuery + whereClause
@@ -119,10 +242,8 @@ func (r *BusRepository) FindByFilter(filter model.BusArrivalFilter) ([]*model.Bu
 	}
 	offset := (filter.Page - 1) * filter.Limit
 
-	selectQuery := `SELECT ba.id, ba.route_config_id, ba.bus_number, ba.arrival_time, 
-						   ba.seats_before, ba.seats_after, ba.created_at,
-						   rc.route_id, rc.route_name, rc.station_id, rc.station_name, rc.sta_order ` +
-		baseQuery + whereClause + " ORDER BY ba.arrival_time DESC LIMIT ? OFFSET ?"
+	selectQuery := "SELECT " + arrivalWithConfigColumns + " " +
+		baseQuery + whereClause + " ORDER BY ba.arrival_time DESC, ba.id DESC LIMIT ? OFFSET ?"
 
 	args = append(args, filter.Limit, offset)
 	rows, err := r.db.Query(selectQuery, args...)
@@ -133,59 +254,197 @@ func (r *BusRepository) FindByFilter(filter model.BusArrivalFilter) ([]*model.Bu
 
 	var arrivals []*model.BusArrivalWithConfig
 	for rows.Next() {
-		var arrival model.BusArrivalWithConfig
-		if err := rows.Scan(
-			&arrival.ID, &arrival.RouteConfigID, &arrival.BusNumber, &arrival.ArrivalTime,
-			&arrival.SeatsBefore, &arrival.SeatsAfter, &arrival.CreatedAt,
-			&arrival.RouteID, &arrival.RouteName, &arrival.StationID, &arrival.StationName, &arrival.StaOrder,
-		); err != nil {
+		arrival, err := scanArrivalWithConfig(rows)
+		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan bus arrival: %w", err)
 		}
-		arrivals = append(arrivals, &arrival)
+		arrivals = append(arrivals, arrival)
 	}
 
 	return arrivals, total, rows.Err()
 }
 
-// GetStatistics retrieves statistics for a route/station combination
-func (r *BusRepository) GetStatistics(routeID, stationID string, fromDate, toDate *time.Time) (*model.BusArrivalStats, error) {
-	query := `SELECT 
-				rc.route_id,
-				rc.station_name,
-				COUNT(*) as total_arrivals,
-				AVG(ba.seats_before) as avg_before,
-				AVG(ba.seats_after) as avg_after,
-				AVG(ba.seats_before - ba.seats_after) as avg_boarding
-			  FROM bus_arrivals ba
-			  JOIN route_configs rc ON ba.route_config_id = rc.id
-			  WHERE rc.route_id = ? AND rc.station_id = ?`
+// FindByFilterCursor is the keyset-paginated variant of FindByFilter for infinite scroll.
+// It returns up to limit arrivals older than (afterTime, afterID) in the same newest-first
+// order; a zero afterTime starts from the newest. Pagination stays stable while new rows arrive.
+func (r *BusRepository) FindByFilterCursor(filter model.BusArrivalFilter, afterTime time.Time, afterID int64, limit int) ([]*model.BusArrivalWithConfig, error) {
+	whereClause, args := filterWhereClause(filter)
 
-	args := []interface{}{routeID, stationID}
+	if !afterTime.IsZero() {
+		cursorClause := "(ba.arrival_time < ? OR (ba.arrival_time = ? AND ba.id < ?))"
+		if whereClause == "" {
+			whereClause = " WHERE " + cursorClause
+		} else {
+			whereClause += " AND " + cursorClause
+		}
+		args = append(args, dbTime(afterTime), dbTime(afterTime), afterID)
+	}
 
-	if fromDate != nil {
-		query += " AND ba.arrival_time >= ?"
-		args = append(args, fromDate)
+	if limit < 1 {
+		limit = 20
 	}
-	if toDate != nil {
-		query += " AND ba.arrival_time <= ?"
-		args = append(args, toDate)
+
+	query := "SELECT " + arrivalWithConfigColumns +
+		" FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id" +
+		whereClause + " ORDER BY ba.arrival_time DESC, ba.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bus arrivals: %w", err)
+	}
+	defer rows.Close()
+
+	var arrivals []*model.BusArrivalWithConfig
+	for rows.Next() {
+		arrival, err := scanArrivalWithConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bus arrival: %w", err)
+		}
+		arrivals = append(arrivals, arrival)
+	}
+
+	return arrivals, rows.Err()
+}
+
+// FindByBusNumber retrieves one vehicle's chronological pass history across all configs.
+// The plate is normalized, so any reported format of it matches: with a region prefix
+// it also matches rows of that region's configs, which store the plate without it.
+func (r *BusRepository) FindByBusNumber(busNumber string, from, to *time.Time, page, limit int) ([]*model.BusArrivalWithConfig, int64, error) {
+	plate := model.NormalizePlate(busNumber)
+	baseQuery := `FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id WHERE (ba.bus_number = ?`
+	args := []interface{}{plate}
+	if prefix, rest := model.SplitPlateRegion(plate); prefix != "" {
+		if regions := model.PlateConfigRegions(prefix); len(regions) > 0 {
+			baseQuery += " OR (ba.bus_number = ? AND rc.region IN (?" + strings.Repeat(", ?", len(regions)-1) + "))"
+			args = append(args, rest)
+			for _, region := range regions {
+				args = append(args, region)
+			}
+		}
+	}
+	baseQuery += ")"
+
+	if from != nil {
+		baseQuery += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		baseQuery += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+
+	var total int64
+	if err := r.db.QueryRow("SELECT COUNT(*) "+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bus history: %w", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	query := "SELECT " + arrivalWithConfigColumns + " " + baseQuery + " ORDER BY ba.arrival_time ASC, ba.id ASC LIMIT ? OFFSET ?"
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query bus history: %w", err)
+	}
+	defer rows.Close()
+
+	var arrivals []*model.BusArrivalWithConfig
+	for rows.Next() {
+		arrival, err := scanArrivalWithConfig(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bus history: %w", err)
+		}
+		arrivals = append(arrivals, arrival)
+	}
+
+	return arrivals, total, rows.Err()
+}
+
+// samplingSQL selects the stamped row count and the average, lowest and highest
+// poll interval of the rows, for scanning into model.Sampling via sampling
+const samplingSQL = `COUNT(ba.poll_interval_ms), AVG(ba.poll_interval_ms), MIN(ba.poll_interval_ms), MAX(ba.poll_interval_ms)`
+
+// seatAveragesSQL selects the average seats before, seats after, boarding
+// (boardingExpr) and net alighting (netAlightingExpr) of non-suspect rows,
+// weighted by poll_interval_ms when weighted is set
+func seatAveragesSQL(weighted bool) string {
+	exprs := []string{
+		"CASE WHEN ba.is_suspect = 0 THEN ba.seats_before END",
+		"CASE WHEN ba.is_suspect = 0 THEN ba.seats_after END",
+		boardingExpr,
+		netAlightingExpr,
+	}
+	cols := make([]string, len(exprs))
+	for i, expr := range exprs {
+		if weighted {
+			cols[i] = "SUM((" + expr + ") * ba.poll_interval_ms) * 1.0 / " +
+				"SUM(CASE WHEN (" + expr + ") IS NOT NULL THEN ba.poll_interval_ms END)"
+		} else {
+			cols[i] = "AVG(" + expr + ")"
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+func sampling(weighted bool, stamped int, avg sql.NullFloat64, lo, hi sql.NullInt64) model.Sampling {
+	return model.Sampling{
+		Weighted:          weighted,
+		StampedArrivals:   stamped,
+		AvgPollIntervalMs: avg.Float64,
+		MinPollIntervalMs: int(lo.Int64),
+		MaxPollIntervalMs: int(hi.Int64),
+	}
+}
+
+// dateRangesClause builds an OR'd arrival_time condition matching any of the ranges
+func dateRangesClause(ranges []model.DateRange) (string, []interface{}) {
+	parts := make([]string, 0, len(ranges))
+	args := make([]interface{}, 0, len(ranges)*2)
+	for _, dr := range ranges {
+		parts = append(parts, "ba.arrival_time BETWEEN ? AND ?")
+		args = append(args, dbTime(dr.From), dbTime(dr.To))
 	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// GetStatistics retrieves statistics over the arrivals matched by filter, e.g. one
+// route/station combination or a whole route group. Page and Limit are ignored.
+func (r *BusRepository) GetStatistics(filter model.BusArrivalFilter) (*model.BusArrivalStats, error) {
+	baseQuery := ` FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id`
+	whereClause, args := filterWhereClause(filter)
 
-	query += " GROUP BY rc.route_id, rc.station_name"
+	query := `SELECT 
+				COALESCE(MIN(rc.route_id), ''),
+				COALESCE(MIN(rc.station_name), ''),
+				COUNT(*) as total_arrivals,
+				` + seatAveragesSQL(filter.WeightByInterval) + `,
+				CASE WHEN MIN(rc.stop_type) = MAX(rc.stop_type) THEN MIN(rc.stop_type) ELSE '` + model.StopTypeMixed + `' END,
+				` + samplingSQL +
+		baseQuery + whereClause
 
 	var stats model.BusArrivalStats
-	var avgBefore, avgAfter, avgBoarding sql.NullFloat64
+	var avgBefore, avgAfter, avgBoarding, avgAlighting, avgInterval sql.NullFloat64
+	var stopType sql.NullString
+	var minInterval, maxInterval sql.NullInt64
 
 	err := r.db.QueryRow(query, args...).Scan(
 		&stats.RouteID, &stats.StationName, &stats.TotalArrivals,
-		&avgBefore, &avgAfter, &avgBoarding,
+		&avgBefore, &avgAfter, &avgBoarding, &avgAlighting, &stopType,
+		&stats.StampedArrivals, &avgInterval, &minInterval, &maxInterval,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("failed to get statistics: %w", err)
 	}
+	if stats.TotalArrivals == 0 {
+		return nil, nil
+	}
 
 	if avgBefore.Valid {
 		stats.AvgBefore = avgBefore.Float64
@@ -196,26 +455,18 @@ func (r *BusRepository) GetStatistics(routeID, stationID string, fromDate, toDat
 	if avgBoarding.Valid {
 		stats.AvgBoarding = avgBoarding.Float64
 	}
-
-	// Get busiest hours
-	hourQuery := `SELECT HOUR(ba.arrival_time) as hour, COUNT(*) as count
-				  FROM bus_arrivals ba
-				  JOIN route_configs rc ON ba.route_config_id = rc.id
-				  WHERE rc.route_id = ? AND rc.station_id = ?`
-
-	hourArgs := []interface{}{routeID, stationID}
-	if fromDate != nil {
-		hourQuery += " AND ba.arrival_time >= ?"
-		hourArgs = append(hourArgs, fromDate)
-	}
-	if toDate != nil {
-		hourQuery += " AND ba.arrival_time <= ?"
-		hourArgs = append(hourArgs, toDate)
+	if avgAlighting.Valid {
+		stats.AvgAlighting = avgAlighting.Float64
 	}
+	stats.StopType = stopType.String
+	stats.PrimaryMetric = model.PrimaryMetric(stats.StopType)
+	stats.Sampling = sampling(filter.WeightByInterval, stats.StampedArrivals, avgInterval, minInterval, maxInterval)
 
-	hourQuery += " GROUP BY HOUR(ba.arrival_time) ORDER BY count DESC LIMIT 3"
+	// Get busiest hours
+	hourQuery := `SELECT ` + localHourSQL("ba.arrival_time") + ` as hour, COUNT(*) as count` +
+		baseQuery + whereClause + " GROUP BY hour ORDER BY count DESC LIMIT 3"
 
-	rows, err := r.db.Query(hourQuery, hourArgs...)
+	rows, err := r.db.Query(hourQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get busiest hours: %w", err)
 	}
@@ -232,18 +483,55 @@ func (r *BusRepository) GetStatistics(routeID, stationID string, fromDate, toDat
 	}
 
 	// Set period
-	if fromDate != nil {
-		stats.PeriodFrom = fromDate.Format("2006-01-02")
+	if filter.FromDate != nil {
+		stats.PeriodFrom = filter.FromDate.Format("2006-01-02")
+	}
+	if filter.ToDate != nil {
+		stats.PeriodTo = filter.ToDate.Format("2006-01-02")
+	}
+
+	return &stats, rows.Err()
+}
+
+// sameTrip reports whether next can follow prev on one trip: its station order must be
+// higher, or lower by no more than OrderTolerance (for loop routes and inconsistent orders),
+// and it must come within MaxGapMinutes, so back-to-back runs aren't stitched together
+func sameTrip(prev, next *model.BusArrivalWithConfig, opts model.TripOptions) bool {
+	if next.ArrivalTime.Sub(prev.ArrivalTime) > time.Duration(opts.MaxGapMinutes)*time.Minute {
+		return false
 	}
-	if toDate != nil {
-		stats.PeriodTo = toDate.Format("2006-01-02")
+	if opts.OrderTolerance == 0 {
+		return next.StaOrder > prev.StaOrder
 	}
+	return next.StaOrder >= prev.StaOrder-opts.OrderTolerance
+}
+
+// Trip grouping defaults. A bus takes well under an hour between two monitored
+// stations even on sparsely monitored routes, while the layover before its next run
+// is usually longer, so 60 minutes separates trips without cutting sparse ones.
+const (
+	defaultTripWindowHours = 6
+	defaultTripMaxGapMin   = 60
+)
 
-	return &stats, nil
+// tripOptionDefaults fills the unset fields of opts with the defaults
+func tripOptionDefaults(opts model.TripOptions) model.TripOptions {
+	if opts.WindowHours <= 0 {
+		opts.WindowHours = defaultTripWindowHours
+	}
+	if opts.OrderTolerance < 0 {
+		opts.OrderTolerance = 0
+	}
+	if opts.MaxGapMinutes <= 0 {
+		opts.MaxGapMinutes = defaultTripMaxGapMin
+	}
+	return opts
 }
 
 // GetTripByArrivalID identifies and returns the full trip sequence for a given arrival record
-func (r *BusRepository) GetTripByArrivalID(id int64) ([]*model.BusArrivalWithConfig, error) {
+func (r *BusRepository) GetTripByArrivalID(id int64, opts model.TripOptions) ([]*model.BusArrivalWithConfig, error) {
+	opts = tripOptionDefaults(opts)
+
 	// 1. Get the target arrival to know busNumber and routeID
 	target, err := r.FindByID(id)
 	if err != nil {
@@ -253,57 +541,80 @@ func (r *BusRepository) GetTripByArrivalID(id int64) ([]*model.BusArrivalWithCon
 		return nil, nil
 	}
 
-	// 2. Fetch all arrivals for this bus and route within a 12-hour window (to avoid loading too much history)
-	// We use the target's arrival time as the center.
-	startTime := target.ArrivalTime.Add(-6 * time.Hour)
-	endTime := target.ArrivalTime.Add(6 * time.Hour)
+	// 2. Fetch this bus's arrivals on the route around the target, starting narrow and
+	// widening only while the trip reaches the edge of what was fetched, so a busy
+	// route doesn't scan half a day of data for a trip that lasts an hour
+	maxWindow := time.Duration(opts.WindowHours) * time.Hour
+	window := min(tripInitialWindow, maxWindow)
+	for {
+		startTime := target.ArrivalTime.Add(-window)
+		endTime := target.ArrivalTime.Add(window)
+
+		arrivals, err := r.findBusArrivals(target.BusNumber, target.RouteID, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+
+		targetIndex := slices.IndexFunc(arrivals, func(a *model.BusArrivalWithConfig) bool { return a.ID == id })
+		if targetIndex == -1 {
+			return nil, nil
+		}
+
+		// 3. Find the contiguous trip segment
+		startIdx, endIdx := tripSegment(arrivals, targetIndex, opts)
+
+		// An arrival just outside the window could still belong to the trip
+		maxGap := time.Duration(opts.MaxGapMinutes) * time.Minute
+		open := (startIdx == 0 && arrivals[0].ArrivalTime.Sub(startTime) <= maxGap) ||
+			(endIdx == len(arrivals)-1 && endTime.Sub(arrivals[endIdx].ArrivalTime) <= maxGap)
+		if !open || window >= maxWindow {
+			return arrivals[startIdx : endIdx+1], nil
+		}
+		window = min(window*2, maxWindow)
+	}
+}
+
+// tripInitialWindow is how far either side of the arrival GetTripByArrivalID searches
+// first; most trips fit, and longer ones widen the search
+const tripInitialWindow = 2 * time.Hour
 
-	query := `SELECT ba.id, ba.route_config_id, ba.bus_number, ba.arrival_time, 
-					 ba.seats_before, ba.seats_after, ba.created_at,
-					 rc.route_id, rc.route_name, rc.station_id, rc.station_name, rc.sta_order
+// findBusArrivals returns a bus's arrivals on a route between start and end, oldest first
+func (r *BusRepository) findBusArrivals(busNumber, routeID string, start, end time.Time) ([]*model.BusArrivalWithConfig, error) {
+	query := `SELECT ` + arrivalWithConfigColumns + `
 			  FROM bus_arrivals ba
 			  JOIN route_configs rc ON ba.route_config_id = rc.id
 			  WHERE ba.bus_number = ? AND rc.route_id = ?
 			  AND ba.arrival_time BETWEEN ? AND ?
 			  ORDER BY ba.arrival_time ASC`
 
-	rows, err := r.db.Query(query, target.BusNumber, target.RouteID, startTime, endTime)
+	rows, err := r.db.Query(query, busNumber, routeID, dbTime(start), dbTime(end))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query trip: %w", err)
 	}
 	defer rows.Close()
 
-	var allArrivals []*model.BusArrivalWithConfig
-	targetIndex := -1
-
+	var arrivals []*model.BusArrivalWithConfig
 	for rows.Next() {
-		var a model.BusArrivalWithConfig
-		err := rows.Scan(
-			&a.ID, &a.RouteConfigID, &a.BusNumber, &a.ArrivalTime,
-			&a.SeatsBefore, &a.SeatsAfter, &a.CreatedAt,
-			&a.RouteID, &a.RouteName, &a.StationID, &a.StationName, &a.StaOrder,
-		)
+		a, err := scanArrivalWithConfig(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trip arrival: %w", err)
 		}
-		if a.ID == id {
-			targetIndex = len(allArrivals)
-		}
-		allArrivals = append(allArrivals, &a)
+		arrivals = append(arrivals, a)
 	}
 
-	if targetIndex == -1 {
-		return nil, nil
-	}
+	return arrivals, rows.Err()
+}
 
-	// 3. Find the contiguous trip segment
+// tripSegment returns the bounds of the run of arrivals around targetIndex that
+// sameTrip links together
+func tripSegment(arrivals []*model.BusArrivalWithConfig, targetIndex int, opts model.TripOptions) (int, int) {
 	// Go backwards from targetIndex
 	startIdx := targetIndex
 	for i := targetIndex - 1; i >= 0; i-- {
 		// If the previous station order is less than current, it's the same trip
 		// Note: We might miss some gap if the bus skipped a monitored station,
 		// but as long as it's increasing, we assume it's the same trip.
-		if allArrivals[i].StaOrder < allArrivals[i+1].StaOrder {
+		if sameTrip(arrivals[i], arrivals[i+1], opts) {
 			startIdx = i
 		} else {
 			break
@@ -312,13 +623,1344 @@ func (r *BusRepository) GetTripByArrivalID(id int64) ([]*model.BusArrivalWithCon
 
 	// Go forwards from targetIndex
 	endIdx := targetIndex
-	for i := targetIndex + 1; i < len(allArrivals); i++ {
-		if allArrivals[i].StaOrder > allArrivals[i-1].StaOrder {
+	for i := targetIndex + 1; i < len(arrivals); i++ {
+		if sameTrip(arrivals[i-1], arrivals[i], opts) {
 			endIdx = i
 		} else {
 			break
 		}
 	}
 
-	return allArrivals[startIdx : endIdx+1], nil
+	return startIdx, endIdx
+}
+
+// FindHeadwayGaps returns gaps between consecutive arrivals at a config from the given time
+// until to (nil for no end) that exceed factor × the config's expected headway. When the
+// window is still open (to is nil or in the future), the time since the last arrival is
+// also checked and reported as an ongoing gap. A window without arrivals reports nothing.
+func (r *BusRepository) FindHeadwayGaps(cfg *model.RouteConfig, from time.Time, to *time.Time, factor float64) ([]model.HeadwayAlert, error) {
+	alerts := []model.HeadwayAlert{}
+	if cfg.ExpectedHeadwayMin <= 0 {
+		return alerts, nil
+	}
+
+	query := `SELECT arrival_time FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0 AND arrival_time >= ?`
+	args := []interface{}{cfg.ID, dbTime(from)}
+
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " ORDER BY arrival_time ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query arrival times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan arrival time: %w", err)
+		}
+		times = append(times, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	threshold := time.Duration(float64(cfg.ExpectedHeadwayMin)*factor) * time.Minute
+	newAlert := func(start, end time.Time, ongoing bool) model.HeadwayAlert {
+		return model.HeadwayAlert{
+			RouteConfigID:      cfg.ID,
+			RouteName:          cfg.Label(),
+			StationName:        cfg.StationName,
+			ExpectedHeadwayMin: cfg.ExpectedHeadwayMin,
+			ObservedGapMin:     end.Sub(start).Minutes(),
+			GapStart:           start,
+			GapEnd:             end,
+			Ongoing:            ongoing,
+		}
+	}
+
+	for i := 1; i < len(times); i++ {
+		if times[i].Sub(times[i-1]) > threshold {
+			alerts = append(alerts, newAlert(times[i-1], times[i], false))
+		}
+	}
+
+	if len(times) == 0 {
+		return alerts, nil
+	}
+
+	now := time.Now()
+	if to == nil || to.After(now) {
+		last := times[len(times)-1]
+		if now.Sub(last) > threshold {
+			alerts = append(alerts, newAlert(last, now, true))
+		}
+	}
+
+	return alerts, nil
+}
+
+// serviceBreakGap is the longest gap between consecutive arrivals still counted as a
+// headway; longer ones are service breaks (or collection pauses), as are gaps across dates
+const serviceBreakGap = 2 * time.Hour
+
+// headways returns the minutes between consecutive observed arrivals of a config
+// on the same local date, leaving out service breaks
+func (r *BusRepository) headways(configID int64, from, to *time.Time) ([]float64, error) {
+	query := `SELECT ` + localDaySQL("arrival_time") + `, arrival_time FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " ORDER BY arrival_time ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query arrival times: %w", err)
+	}
+	defer rows.Close()
+
+	var gaps []float64
+	var prevDay string
+	var prev time.Time
+	for rows.Next() {
+		var day string
+		var t time.Time
+		if err := rows.Scan(&day, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan arrival time: %w", err)
+		}
+		if day == prevDay {
+			if gap := t.Sub(prev); gap <= serviceBreakGap {
+				gaps = append(gaps, gap.Minutes())
+			}
+		}
+		prevDay, prev = day, t
+	}
+
+	return gaps, rows.Err()
+}
+
+// GetRegularityScore returns the coefficient of variation (std dev / mean) of a
+// config's headways, excluding service breaks, with the number of headways used.
+// It is 0 when undefined (fewer than two headways or a zero mean).
+func (r *BusRepository) GetRegularityScore(configID int64, from, to *time.Time) (float64, int, error) {
+	gaps, err := r.headways(configID, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n := len(gaps)
+	if n < 2 {
+		return 0, n, nil
+	}
+	var sum float64
+	for _, g := range gaps {
+		sum += g
+	}
+	mean := sum / float64(n)
+	if mean <= 0 {
+		return 0, n, nil
+	}
+	var sq float64
+	for _, g := range gaps {
+		sq += (g - mean) * (g - mean)
+	}
+
+	return math.Sqrt(sq/float64(n)) / mean, n, nil
+}
+
+// GetDatesWithData returns the dates in loc ("2006-01-02", ascending) with at least one
+// arrival for a config. Arrivals are grouped by UTC hour in SQL, which stays correct
+// whatever offset each row was stored with; an hour spanning midnight in loc
+// contributes the dates of both its first and last arrival.
+func (r *BusRepository) GetDatesWithData(configID int64, loc *time.Location) ([]string, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	query := `SELECT MIN(julianday(arrival_time)), MAX(julianday(arrival_time))
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0
+			  GROUP BY CAST(julianday(arrival_time) * 24 AS INTEGER)`
+
+	rows, err := r.db.Query(query, configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dates with data: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var first, last float64
+		if err := rows.Scan(&first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan dates with data: %w", err)
+		}
+		seen[julianToTime(first).In(loc).Format("2006-01-02")] = true
+		seen[julianToTime(last).In(loc).Format("2006-01-02")] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(seen))
+	for date := range seen {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+	return dates, nil
+}
+
+// GetServiceSpan returns, per local date, the first and last recorded arrival for a config.
+// These are observed spans and are limited by when the collector was running.
+func (r *BusRepository) GetServiceSpan(configID int64, from, to *time.Time) ([]model.DailySpan, error) {
+	query := `SELECT ` + localDaySQL("arrival_time") + ` AS day, MIN(arrival_time), MAX(arrival_time), COUNT(*)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY day ORDER BY day ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service span: %w", err)
+	}
+	defer rows.Close()
+
+	spans := []model.DailySpan{}
+	for rows.Next() {
+		var span model.DailySpan
+		var first, last string
+		if err := rows.Scan(&span.Date, &first, &last, &span.TotalArrivals); err != nil {
+			return nil, fmt.Errorf("failed to scan service span: %w", err)
+		}
+		if span.FirstArrival, err = parseDBTime(first); err != nil {
+			return nil, err
+		}
+		if span.LastArrival, err = parseDBTime(last); err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+
+	return spans, rows.Err()
+}
+
+// GetDailyBoarding sums positive boarding per local date for a config as a ridership proxy.
+// Rows without both seat values or flagged suspect add nothing to the total but still count as arrivals.
+func (r *BusRepository) GetDailyBoarding(configID int64, from, to *time.Time) ([]model.DailyBoarding, error) {
+	query := `SELECT ` + localDaySQL("arrival_time") + ` AS day,
+				COALESCE(SUM(CASE WHEN is_suspect = 0 AND seats_before > seats_after
+					THEN seats_before - seats_after END), 0),
+				COUNT(*)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY day ORDER BY day ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily boarding: %w", err)
+	}
+	defer rows.Close()
+
+	days := []model.DailyBoarding{}
+	for rows.Next() {
+		var day model.DailyBoarding
+		if err := rows.Scan(&day.Date, &day.TotalBoarding, &day.TotalArrivals); err != nil {
+			return nil, fmt.Errorf("failed to scan daily boarding: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// GetHourlyCountsVsExpected counts observed arrivals per local clock hour and compares each
+// with expectedPerHour. Hours without any arrival are included between the first and last
+// observed hour, but only at hours of day the config has seen service in the period,
+// so that nights don't all read as shortfalls.
+func (r *BusRepository) GetHourlyCountsVsExpected(configID int64, expectedPerHour float64, from, to *time.Time) ([]model.HourlyComparison, error) {
+	query := `SELECT ` + localDayHourSQL("arrival_time") + ` AS hour, COUNT(*)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY hour ORDER BY hour ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hourly counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	serviceHours := make(map[int]bool)
+	var first, last time.Time
+	for rows.Next() {
+		var hour string
+		var count int
+		if err := rows.Scan(&hour, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly counts: %w", err)
+		}
+		t, err := time.Parse("2006-01-02 15", hour)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected arrival time %q: %w", hour, err)
+		}
+		if first.IsZero() {
+			first = t
+		}
+		last = t
+		counts[t.Format("2006-01-02 15")] = count
+		serviceHours[t.Hour()] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := []model.HourlyComparison{}
+	if first.IsZero() {
+		return result, nil
+	}
+	for t := first; !t.After(last); t = t.Add(time.Hour) {
+		if !serviceHours[t.Hour()] {
+			continue
+		}
+		key := t.Format("2006-01-02 15")
+		actual := counts[key]
+		result = append(result, model.HourlyComparison{
+			Hour:      key,
+			Actual:    actual,
+			Expected:  expectedPerHour,
+			Shortfall: float64(actual) < expectedPerHour*model.ShortfallRatio,
+		})
+	}
+	return result, nil
+}
+
+// GetBoardingSeries returns a config's boarding per arrival over time for charting,
+// using arrivals with plausible seat values. With more than maxPoints arrivals the
+// period from the first to the last arrival is cut into maxPoints equal buckets and
+// each non-empty bucket becomes one averaged point stamped with the bucket's start,
+// so a point's time marks the left edge of the span it covers.
+func (r *BusRepository) GetBoardingSeries(configID int64, from, to *time.Time, maxPoints int) ([]model.SeriesPoint, error) {
+	query := `SELECT arrival_time, MAX(seats_before - seats_after, 0)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_suspect = 0 AND is_scheduled = 0
+				AND seats_before IS NOT NULL AND seats_after IS NOT NULL`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " ORDER BY arrival_time ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query boarding series: %w", err)
+	}
+	defer rows.Close()
+
+	points := []model.SeriesPoint{}
+	for rows.Next() {
+		p := model.SeriesPoint{Count: 1}
+		var boarding int
+		if err := rows.Scan(&p.Time, &boarding); err != nil {
+			return nil, fmt.Errorf("failed to scan boarding series: %w", err)
+		}
+		p.Value = float64(boarding)
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return points, nil
+	}
+	return downsample(points, maxPoints), nil
+}
+
+// downsample averages time-ordered raw points into at most n equal time buckets
+func downsample(points []model.SeriesPoint, n int) []model.SeriesPoint {
+	start := points[0].Time
+	span := points[len(points)-1].Time.Sub(start)
+	width := span / time.Duration(n)
+	if width <= 0 {
+		width = 1
+	}
+
+	out := []model.SeriesPoint{}
+	var sum float64
+	for _, p := range points {
+		// The last arrival sits exactly on the end; keep it in the final bucket
+		idx := min(int(p.Time.Sub(start)/width), n-1)
+		bucketStart := start.Add(time.Duration(idx) * width)
+		if len(out) == 0 || !out[len(out)-1].Time.Equal(bucketStart) {
+			if len(out) > 0 {
+				last := &out[len(out)-1]
+				last.Value = sum / float64(last.Count)
+			}
+			out = append(out, model.SeriesPoint{Time: bucketStart})
+			sum = 0
+		}
+		out[len(out)-1].Count++
+		sum += p.Value
+	}
+	last := &out[len(out)-1]
+	last.Value = sum / float64(last.Count)
+	return out
+}
+
+// GetHourBoardingCorrelation returns the Pearson correlation between the local hour of
+// day and the number of passengers boarding, over arrivals with plausible seat values,
+// together with the number of arrivals used. The coefficient is 0 when it is undefined
+// (fewer than two arrivals, or no variation in hour or boarding).
+func (r *BusRepository) GetHourBoardingCorrelation(configID int64, from, to *time.Time) (float64, int, error) {
+	query := `SELECT CAST(` + localHourSQL("arrival_time") + ` AS INTEGER),
+				MAX(seats_before - seats_after, 0)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_suspect = 0 AND is_scheduled = 0
+				AND seats_before IS NOT NULL AND seats_after IS NOT NULL`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query hourly boarding: %w", err)
+	}
+	defer rows.Close()
+
+	var n int
+	var sumX, sumY, sumXX, sumYY, sumXY float64
+	for rows.Next() {
+		var hour, boarding int
+		if err := rows.Scan(&hour, &boarding); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan hourly boarding: %w", err)
+		}
+		x, y := float64(hour), float64(boarding)
+		n++
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumYY += y * y
+		sumXY += x * y
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	if n < 2 {
+		return 0, n, nil
+	}
+	fn := float64(n)
+	cov := sumXY - sumX*sumY/fn
+	varX := sumXX - sumX*sumX/fn
+	varY := sumYY - sumY*sumY/fn
+	if varX <= 0 || varY <= 0 {
+		return 0, n, nil
+	}
+
+	return cov / math.Sqrt(varX*varY), n, nil
+}
+
+// GetDirectionSplit counts a config's arrivals by the direction recorded for each pass.
+// Arrivals without a recorded direction (older rows) take the config's direction,
+// or are counted under "unknown" if it has none.
+func (r *BusRepository) GetDirectionSplit(configID int64, from, to *time.Time) (map[string]int, error) {
+	query := `SELECT COALESCE(NULLIF(ba.direction, ''), NULLIF(rc.direction, ''), 'unknown') AS dir, COUNT(*)
+			  FROM bus_arrivals ba
+			  JOIN route_configs rc ON ba.route_config_id = rc.id
+			  WHERE ba.route_config_id = ? AND ba.is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY dir"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query direction split: %w", err)
+	}
+	defer rows.Close()
+
+	split := make(map[string]int)
+	for rows.Next() {
+		var direction string
+		var count int
+		if err := rows.Scan(&direction, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan direction split: %w", err)
+		}
+		split[direction] = count
+	}
+
+	return split, rows.Err()
+}
+
+// GetStatsByDirectionHour returns the average boarding per local hour of day at a
+// station, across every route monitored there, for each direction arrivals were
+// recorded in. Hours without a usable arrival are 0.
+func (r *BusRepository) GetStatsByDirectionHour(stationID string, from, to *time.Time) (map[string][24]float64, error) {
+	query := `SELECT COALESCE(NULLIF(ba.direction, ''), NULLIF(rc.direction, ''), 'unknown') AS dir,
+				CAST(` + localHourSQL("ba.arrival_time") + ` AS INTEGER) AS hour,
+				AVG(` + boardingExpr + `)
+			  FROM bus_arrivals ba
+			  JOIN route_configs rc ON ba.route_config_id = rc.id
+			  WHERE rc.station_id = ? AND ba.is_scheduled = 0`
+	args := []interface{}{stationID}
+
+	if from != nil {
+		query += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY dir, hour"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query boarding by direction and hour: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string][24]float64)
+	for rows.Next() {
+		var direction string
+		var hour int
+		var avg sql.NullFloat64
+		if err := rows.Scan(&direction, &hour, &avg); err != nil {
+			return nil, fmt.Errorf("failed to scan boarding by direction and hour: %w", err)
+		}
+		byHour := stats[direction]
+		if hour >= 0 && hour < 24 {
+			byHour[hour] = avg.Float64
+		}
+		stats[direction] = byHour
+	}
+
+	return stats, rows.Err()
+}
+
+// GetBusiestStations ranks the monitored stations of a route by total boarding, then by
+// arrival count. Every config on the route is listed, including ones without arrivals.
+func (r *BusRepository) GetBusiestStations(routeID string, from, to *time.Time) ([]model.StationRank, error) {
+	join := "ba.route_config_id = rc.id AND ba.is_scheduled = 0"
+	args := []interface{}{}
+	if from != nil {
+		join += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		join += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	args = append(args, routeID)
+
+	query := `SELECT rc.id, rc.station_id, rc.station_name, rc.sta_order, rc.direction,
+				COALESCE(SUM(` + boardingExpr + `), 0) AS total_boarding,
+				COUNT(ba.id) AS total_arrivals,
+				AVG(` + boardingExpr + `)
+			  FROM route_configs rc
+			  LEFT JOIN bus_arrivals ba ON ` + join + `
+			  WHERE rc.route_id = ? AND rc.deleted_at IS NULL
+			  GROUP BY rc.id
+			  ORDER BY total_boarding DESC, total_arrivals DESC, rc.sta_order ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query busiest stations: %w", err)
+	}
+	defer rows.Close()
+
+	ranks := []model.StationRank{}
+	for rows.Next() {
+		var rank model.StationRank
+		var avgBoarding sql.NullFloat64
+		if err := rows.Scan(&rank.ConfigID, &rank.StationID, &rank.StationName, &rank.StaOrder, &rank.Direction,
+			&rank.TotalBoarding, &rank.TotalArrivals, &avgBoarding); err != nil {
+			return nil, fmt.Errorf("failed to scan station rank: %w", err)
+		}
+		rank.AvgBoarding = avgBoarding.Float64
+		ranks = append(ranks, rank)
+	}
+
+	return ranks, rows.Err()
+}
+
+// PreviewPurge counts the arrivals before cutoff per config, without deleting anything.
+// Arrivals of deleted configs and timetable-only rows are included, as a purge deletes
+// them too. Dates are the local day of the rows.
+func (r *BusRepository) PreviewPurge(cutoff time.Time) (*model.PurgePreview, error) {
+	query := `SELECT ba.route_config_id, COALESCE(rc.route_name, ''), COALESCE(rc.station_name, ''),
+				COUNT(*) AS row_count, MIN(` + localDaySQL("ba.arrival_time") + `), MAX(` + localDaySQL("ba.arrival_time") + `)
+			  FROM bus_arrivals ba
+			  LEFT JOIN route_configs rc ON ba.route_config_id = rc.id
+			  WHERE ba.arrival_time < ?
+			  GROUP BY ba.route_config_id
+			  ORDER BY row_count DESC, ba.route_config_id ASC`
+
+	rows, err := r.db.Query(query, dbTime(cutoff))
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview purge: %w", err)
+	}
+	defer rows.Close()
+
+	preview := &model.PurgePreview{Cutoff: cutoff, Configs: []model.PurgeConfigCount{}}
+	for rows.Next() {
+		var cc model.PurgeConfigCount
+		if err := rows.Scan(&cc.ConfigID, &cc.RouteName, &cc.StationName, &cc.Rows, &cc.OldestDate, &cc.NewestDate); err != nil {
+			return nil, fmt.Errorf("failed to scan purge preview: %w", err)
+		}
+		preview.TotalRows += cc.Rows
+		if preview.OldestDate == "" || cc.OldestDate < preview.OldestDate {
+			preview.OldestDate = cc.OldestDate
+		}
+		if cc.NewestDate > preview.NewestDate {
+			preview.NewestDate = cc.NewestDate
+		}
+		preview.Configs = append(preview.Configs, cc)
+	}
+
+	return preview, rows.Err()
+}
+
+// GetRouteSnapshot gathers the arrivals within window of at at every monitored station
+// of a route, organized by station. Stations without arrivals in the window are kept
+// so the snapshot covers the whole monitored route.
+func (r *BusRepository) GetRouteSnapshot(routeID string, at time.Time, window time.Duration) (*model.RouteSnapshot, error) {
+	snapshot := &model.RouteSnapshot{
+		RouteID:       routeID,
+		At:            at,
+		WindowMinutes: int(window / time.Minute),
+		Stations:      []model.SnapshotStation{},
+	}
+
+	configRows, err := r.db.Query(`SELECT id, station_id, station_name, sta_order, direction
+		FROM route_configs WHERE route_id = ? AND deleted_at IS NULL
+		ORDER BY sta_order ASC, id ASC`, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route stations: %w", err)
+	}
+	defer configRows.Close()
+
+	index := make(map[int64]int)
+	for configRows.Next() {
+		var station model.SnapshotStation
+		if err := configRows.Scan(&station.ConfigID, &station.StationID, &station.StationName,
+			&station.StaOrder, &station.Direction); err != nil {
+			return nil, fmt.Errorf("failed to scan route station: %w", err)
+		}
+		station.Arrivals = []model.SnapshotCell{}
+		index[station.ConfigID] = len(snapshot.Stations)
+		snapshot.Stations = append(snapshot.Stations, station)
+	}
+	if err := configRows.Err(); err != nil {
+		return nil, err
+	}
+	if len(snapshot.Stations) == 0 {
+		return snapshot, nil
+	}
+
+	rows, err := r.db.Query(`SELECT ba.id, ba.route_config_id, ba.bus_number, ba.arrival_time,
+			ba.seats_before, ba.seats_after, `+boardingExpr+`
+		FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id
+		WHERE rc.route_id = ? AND rc.deleted_at IS NULL AND ba.is_scheduled = 0
+		AND ba.arrival_time >= ? AND ba.arrival_time <= ?
+		ORDER BY ba.arrival_time ASC, ba.id ASC`,
+		routeID, dbTime(at.Add(-window)), dbTime(at.Add(window)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cell model.SnapshotCell
+		var configID int64
+		if err := rows.Scan(&cell.ArrivalID, &configID, &cell.BusNumber, &cell.ArrivalTime,
+			&cell.SeatsBefore, &cell.SeatsAfter, &cell.Boarding); err != nil {
+			return nil, fmt.Errorf("failed to scan route snapshot: %w", err)
+		}
+		if i, ok := index[configID]; ok {
+			snapshot.Stations[i].Arrivals = append(snapshot.Stations[i].Arrivals, cell)
+		}
+	}
+
+	return snapshot, rows.Err()
+}
+
+// GetRetryStats breaks down a config's arrivals by how many polls were needed to get
+// seats_after, separating the ones that timed out. Rows recorded before retries were
+// stored are left out.
+func (r *BusRepository) GetRetryStats(configID int64, from, to *time.Time) ([]model.RetryBucket, error) {
+	query := `SELECT retry_count, seats_after IS NULL AS timed_out, COUNT(*)
+			  FROM bus_arrivals
+			  WHERE route_config_id = ? AND retry_count IS NOT NULL AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " GROUP BY retry_count, timed_out ORDER BY timed_out ASC, retry_count ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retry stats: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []model.RetryBucket{}
+	for rows.Next() {
+		var b model.RetryBucket
+		if err := rows.Scan(&b.RetryCount, &b.TimedOut, &b.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan retry stats: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+// GetApproachDurationStats returns the distribution of approach_seconds for a config,
+// to size tracking and retry windows per route. Percentiles use the nearest rank.
+func (r *BusRepository) GetApproachDurationStats(configID int64) (*model.ApproachDurationStats, error) {
+	rows, err := r.db.Query(`SELECT approach_seconds FROM bus_arrivals
+			  WHERE route_config_id = ? AND approach_seconds IS NOT NULL AND is_scheduled = 0
+			  ORDER BY approach_seconds ASC`, configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approach durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []int
+	for rows.Next() {
+		var d int
+		if err := rows.Scan(&d); err != nil {
+			return nil, fmt.Errorf("failed to scan approach duration: %w", err)
+		}
+		durations = append(durations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &model.ApproachDurationStats{Count: len(durations), ByMinute: map[int]int{}}
+	if len(durations) == 0 {
+		return stats, nil
+	}
+
+	rank := func(p float64) int {
+		i := int(math.Ceil(p*float64(len(durations)))) - 1
+		return durations[max(i, 0)]
+	}
+	sum := 0
+	for _, d := range durations {
+		sum += d
+		stats.ByMinute[d/60]++
+	}
+	stats.MinSeconds = durations[0]
+	stats.P25Seconds = rank(0.25)
+	stats.MedianSeconds = rank(0.5)
+	stats.P75Seconds = rank(0.75)
+	stats.P90Seconds = rank(0.9)
+	stats.MaxSeconds = durations[len(durations)-1]
+	stats.MeanSeconds = float64(sum) / float64(len(durations))
+	return stats, nil
+}
+
+// GetClockDriftReport lists the UTC offsets arrivals were recorded under and the
+// observed arrivals whose arrival_time is more than maxSkewMin minutes away from
+// created_at (up to limit of the newest). Scheduled-only rows are stored long after
+// their time and are left out.
+func (r *BusRepository) GetClockDriftReport(maxSkewMin, limit int) (*model.ClockDriftReport, error) {
+	report := &model.ClockDriftReport{Offsets: []model.OffsetSpan{}, Skewed: []model.ClockSkew{}, MaxSkewMin: maxSkewMin}
+
+	rows, err := r.db.Query(`SELECT utc_offset_min, COUNT(*), MIN(created_at), MAX(created_at)
+			  FROM bus_arrivals WHERE utc_offset_min IS NOT NULL AND is_scheduled = 0
+			  GROUP BY utc_offset_min ORDER BY MIN(created_at) ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recorded offsets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var span model.OffsetSpan
+		var first, last string
+		if err := rows.Scan(&span.OffsetMin, &span.Count, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan recorded offset: %w", err)
+		}
+		span.First, _ = time.Parse(time.DateTime, first)
+		span.Last, _ = time.Parse(time.DateTime, last)
+		report.Offsets = append(report.Offsets, span)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.Consistent = len(report.Offsets) <= 1
+
+	// julianday reads the offset stored with arrival_time and works in UTC
+	skew := `(julianday(created_at) - julianday(arrival_time)) * 1440`
+	where := ` FROM bus_arrivals WHERE is_scheduled = 0 AND ABS(` + skew + `) > ?`
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+where, maxSkewMin).Scan(&report.SkewedCount); err != nil {
+		return nil, fmt.Errorf("failed to count skewed arrivals: %w", err)
+	}
+
+	rows, err = r.db.Query(`SELECT id, route_config_id, arrival_time, created_at, `+skew+where+
+		` ORDER BY id DESC LIMIT ?`, maxSkewMin, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query skewed arrivals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.ClockSkew
+		if err := rows.Scan(&s.ArrivalID, &s.ConfigID, &s.ArrivalTime, &s.CreatedAt, &s.SkewMinutes); err != nil {
+			return nil, fmt.Errorf("failed to scan skewed arrival: %w", err)
+		}
+		report.Skewed = append(report.Skewed, s)
+	}
+
+	return report, rows.Err()
+}
+
+// suspectSeatsCondition is the SQL form of model.SeatsSuspect over the stored columns
+var suspectSeatsCondition = fmt.Sprintf(
+	`(seats_before IS NOT NULL AND (seats_before < 0 OR seats_before > %[1]d))
+	 OR (seats_after IS NOT NULL AND (seats_after < 0 OR seats_after > %[1]d))`,
+	model.MaxPlausibleSeats)
+
+// RecomputeBoarding re-evaluates stored seat values for a config (0 = all configs)
+// and flags impossible ones as suspect so they drop out of boarding statistics.
+// Boarding itself is derived at query time, so flagging is all that's needed.
+// Returns the number of rows whose flag changed.
+func (r *BusRepository) RecomputeBoarding(configID int64) (int64, error) {
+	query := `UPDATE bus_arrivals SET is_suspect = NOT is_suspect
+			  WHERE is_suspect != (CASE WHEN ` + suspectSeatsCondition + ` THEN 1 ELSE 0 END)`
+	args := []interface{}{}
+	if configID != 0 {
+		query += " AND route_config_id = ?"
+		args = append(args, configID)
+	}
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute suspect flags: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetArrivalTotals fills the arrival part of the system overview: total rows,
+// rows since the given time, and the earliest/latest arrival_time.
+// MIN/MAX use the arrival_time index, so this stays cheap on large databases.
+func (r *BusRepository) GetArrivalTotals(overview *model.SystemOverview, since time.Time) error {
+	var earliest, latest sql.NullString
+	query := `SELECT COUNT(*), MIN(arrival_time), MAX(arrival_time) FROM bus_arrivals WHERE is_scheduled = 0`
+	if err := r.db.QueryRow(query).Scan(&overview.TotalArrivals, &earliest, &latest); err != nil {
+		return fmt.Errorf("failed to get arrival totals: %w", err)
+	}
+
+	if earliest.Valid {
+		t, err := parseDBTime(earliest.String)
+		if err != nil {
+			return err
+		}
+		overview.EarliestArrival = &t
+	}
+	if latest.Valid {
+		t, err := parseDBTime(latest.String)
+		if err != nil {
+			return err
+		}
+		overview.LatestArrival = &t
+	}
+
+	recentQuery := `SELECT COUNT(*) FROM bus_arrivals WHERE is_scheduled = 0 AND arrival_time >= ?`
+	if err := r.db.QueryRow(recentQuery, dbTime(since)).Scan(&overview.ArrivalsLast24h); err != nil {
+		return fmt.Errorf("failed to count recent arrivals: %w", err)
+	}
+
+	return nil
+}
+
+// NormalizeStoredPlates re-derives bus_number from the raw plate with NormalizePlateIn
+// and the config's region, keeping the original in raw_bus_number. This covers rows
+// written before plate normalization existed (raw_bus_number NULL) and rows whose
+// region prefix was stripped regardless of region. Returns the rows changed.
+func (r *BusRepository) NormalizeStoredPlates() (int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT COALESCE(ba.raw_bus_number, ba.bus_number), rc.region
+			  FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id
+			  WHERE ba.bus_number != ''`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query stored plates: %w", err)
+	}
+	type rawPlate struct{ plate, region string }
+	var plates []rawPlate
+	for rows.Next() {
+		var p rawPlate
+		if err := rows.Scan(&p.plate, &p.region); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan plate: %w", err)
+		}
+		plates = append(plates, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE bus_arrivals SET raw_bus_number = COALESCE(raw_bus_number, bus_number), bus_number = ?
+			  WHERE COALESCE(raw_bus_number, bus_number) = ? AND bus_number != ?
+			  AND route_config_id IN (SELECT id FROM route_configs WHERE region = ?)`
+	updated := 0
+	for _, p := range plates {
+		normalized := model.NormalizePlateIn(p.plate, p.region)
+		result, err := r.db.Exec(query, normalized, p.plate, normalized, p.region)
+		if err != nil {
+			return updated, fmt.Errorf("failed to normalize plate %s: %w", p.plate, err)
+		}
+		n, _ := result.RowsAffected()
+		updated += int(n)
+	}
+	return updated, nil
+}
+
+// NormalizeStoredTimes rewrites arrival times stored with an offset other than
+// displayZone's (recorded while the host was in another zone) as the same instant
+// in displayZone, so that range filters compare like with like. Times without an
+// offset are left alone since their zone is unknown. Returns the rows changed.
+func (r *BusRepository) NormalizeStoredTimes() (int, error) {
+	offset := time.Now().In(displayZone).Format("-07:00")
+	result, err := r.db.Exec(`UPDATE bus_arrivals
+			  SET arrival_time = strftime('%Y-%m-%d %H:%M:%f', arrival_time, `+zoneModifier()+`) || ?
+			  WHERE arrival_time GLOB '*[+-][0-9][0-9]:[0-9][0-9]' AND substr(arrival_time, -6) != ?`,
+		offset, offset)
+	if err != nil {
+		return 0, fmt.Errorf("failed to normalize stored arrival times: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// ForEachByConfig calls fn for every observed arrival of a config in time order,
+// optionally bounded by from/to, without loading them all into memory. Timetable-only
+// rows are skipped. It returns the number of rows visited.
+func (r *BusRepository) ForEachByConfig(configID int64, from, to *time.Time, fn func(*model.BusArrivalWithConfig) error) (int64, error) {
+	query := `SELECT ` + arrivalWithConfigColumns + `
+		FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id
+		WHERE ba.route_config_id = ? AND ba.is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " ORDER BY ba.arrival_time ASC, ba.id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query arrivals: %w", err)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		arrival, err := scanArrivalWithConfig(rows)
+		if err != nil {
+			return count, fmt.Errorf("failed to scan arrival: %w", err)
+		}
+		if err := fn(arrival); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, rows.Err()
+}
+
+// GetBoardingByRouteType computes arrival and boarding stats per route type across all configs.
+// Configs registered before route types were stored are grouped under "unknown".
+func (r *BusRepository) GetBoardingByRouteType(from, to *time.Time, weighted bool) (map[string]model.BusArrivalStats, error) {
+	where := " WHERE ba.is_scheduled = 0"
+	args := []interface{}{}
+	if from != nil {
+		where += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		where += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+
+	const routeType = `COALESCE(NULLIF(rc.route_type, ''), 'unknown')`
+	query := `SELECT ` + routeType + ` AS route_type,
+				COUNT(*),
+				` + seatAveragesSQL(weighted) + `,
+				` + samplingSQL + `
+			  FROM bus_arrivals ba
+			  JOIN route_configs rc ON ba.route_config_id = rc.id` + where + `
+			  GROUP BY route_type`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boarding by route type: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]model.BusArrivalStats)
+	for rows.Next() {
+		var typeName string
+		var stats model.BusArrivalStats
+		var avgBefore, avgAfter, avgBoarding, avgAlighting, avgInterval sql.NullFloat64
+		var minInterval, maxInterval sql.NullInt64
+		if err := rows.Scan(&typeName, &stats.TotalArrivals, &avgBefore, &avgAfter, &avgBoarding, &avgAlighting,
+			&stats.StampedArrivals, &avgInterval, &minInterval, &maxInterval); err != nil {
+			return nil, fmt.Errorf("failed to scan route type stats: %w", err)
+		}
+		stats.Sampling = sampling(weighted, stats.StampedArrivals, avgInterval, minInterval, maxInterval)
+		stats.AvgBefore = avgBefore.Float64
+		stats.AvgAfter = avgAfter.Float64
+		stats.AvgBoarding = avgBoarding.Float64
+		stats.AvgAlighting = avgAlighting.Float64
+		stats.BusiestHours = []string{}
+		if from != nil {
+			stats.PeriodFrom = from.Format("2006-01-02")
+		}
+		if to != nil {
+			stats.PeriodTo = to.Format("2006-01-02")
+		}
+		result[typeName] = stats
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Busiest hours per type
+	hourQuery := `SELECT route_type, hour FROM (
+					SELECT ` + routeType + ` AS route_type, ` + localHourSQL("ba.arrival_time") + ` AS hour,
+						ROW_NUMBER() OVER (PARTITION BY ` + routeType + ` ORDER BY COUNT(*) DESC) AS rank
+					FROM bus_arrivals ba
+					JOIN route_configs rc ON ba.route_config_id = rc.id` + where + `
+					GROUP BY route_type, hour
+				  ) WHERE rank <= 3 ORDER BY route_type, rank`
+
+	hourRows, err := r.db.Query(hourQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get busiest hours by route type: %w", err)
+	}
+	defer hourRows.Close()
+
+	for hourRows.Next() {
+		var typeName string
+		var hour int
+		if err := hourRows.Scan(&typeName, &hour); err != nil {
+			return nil, fmt.Errorf("failed to scan hour: %w", err)
+		}
+		stats := result[typeName]
+		stats.BusiestHours = append(stats.BusiestHours, fmt.Sprintf("%02d:00-%02d:00", hour, hour+1))
+		result[typeName] = stats
+	}
+
+	return result, hourRows.Err()
+}
+
+// GetTopBoardings returns the n arrivals with the most passengers boarding in a period.
+// Rows without both seat values or flagged suspect are skipped.
+func (r *BusRepository) GetTopBoardings(routeID, stationID string, from, to *time.Time, n int) ([]*model.BusArrivalWithConfig, error) {
+	query := `SELECT ` + arrivalWithConfigColumns + `
+		FROM bus_arrivals ba JOIN route_configs rc ON ba.route_config_id = rc.id
+		WHERE rc.route_id = ? AND rc.station_id = ?
+		AND ba.seats_before IS NOT NULL AND ba.seats_after IS NOT NULL AND ba.is_suspect = 0 AND ba.is_scheduled = 0`
+	args := []interface{}{routeID, stationID}
+
+	if from != nil {
+		query += " AND ba.arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND ba.arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+
+	if n < 1 {
+		n = 10
+	}
+	query += " ORDER BY ba.seats_before - ba.seats_after DESC, ba.seats_before ASC, ba.arrival_time DESC LIMIT ?"
+	args = append(args, n)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top boardings: %w", err)
+	}
+	defer rows.Close()
+
+	var arrivals []*model.BusArrivalWithConfig
+	for rows.Next() {
+		arrival, err := scanArrivalWithConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan top boarding: %w", err)
+		}
+		arrivals = append(arrivals, arrival)
+	}
+
+	return arrivals, rows.Err()
+}
+
+// RecordHeartbeat marks that a config was successfully polled during the given minute.
+// Repeated calls for the same minute are coalesced into one row.
+func (r *BusRepository) RecordHeartbeat(configID int64, minute time.Time) error {
+	query := `INSERT OR IGNORE INTO collection_heartbeats (route_config_id, minute) VALUES (?, ?)`
+	if _, err := r.db.Exec(query, configID, dbTime(minute)); err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	return nil
+}
+
+// ProbeWrite checks that the database accepts writes, by inserting a heartbeat row
+// in a transaction that is rolled back. Fails if the file is read-only or the disk
+// has no room for the journal.
+func (r *BusRepository) ProbeWrite() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin probe write: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO collection_heartbeats (route_config_id, minute) VALUES (0, ?)`, time.Now()); err != nil {
+		return fmt.Errorf("failed to probe write: %w", err)
+	}
+	return nil
+}
+
+// AddToAggregate counts one arrival into a config's time bucket for aggregate-only
+// collection. boarding is added to the bucket's average unless nil.
+func (r *BusRepository) AddToAggregate(configID int64, bucket time.Time, boarding *int) error {
+	sum, samples := 0, 0
+	if boarding != nil {
+		sum, samples = *boarding, 1
+	}
+	query := `INSERT INTO arrival_aggregates (route_config_id, bucket, arrival_count, boarding_sum, boarding_count)
+			  VALUES (?, ?, 1, ?, ?)
+			  ON CONFLICT(route_config_id, bucket) DO UPDATE SET
+				arrival_count = arrival_count + 1,
+				boarding_sum = boarding_sum + excluded.boarding_sum,
+				boarding_count = boarding_count + excluded.boarding_count`
+	if _, err := r.db.Exec(query, configID, dbTime(bucket), sum, samples); err != nil {
+		return fmt.Errorf("failed to update arrival aggregate: %w", err)
+	}
+	return nil
+}
+
+// FindAggregates returns a config's aggregate buckets in time order
+func (r *BusRepository) FindAggregates(configID int64, from, to *time.Time) ([]model.ArrivalAggregate, error) {
+	query := `SELECT route_config_id, bucket, arrival_count, boarding_sum, boarding_count
+			  FROM arrival_aggregates
+			  WHERE route_config_id = ?`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND bucket >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND bucket <= ?"
+		args = append(args, dbTime(*to))
+	}
+	query += " ORDER BY bucket ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query arrival aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := []model.ArrivalAggregate{}
+	for rows.Next() {
+		var a model.ArrivalAggregate
+		var boardingSum int
+		if err := rows.Scan(&a.RouteConfigID, &a.Bucket, &a.ArrivalCount, &boardingSum, &a.BoardingSamples); err != nil {
+			return nil, fmt.Errorf("failed to scan arrival aggregate: %w", err)
+		}
+		if a.BoardingSamples > 0 {
+			avg := float64(boardingSum) / float64(a.BoardingSamples)
+			a.AvgBoarding = &avg
+		}
+		aggregates = append(aggregates, a)
+	}
+
+	return aggregates, rows.Err()
+}
+
+// HasObservedArrival reports whether a config recorded a real (not scheduled) pass in [from, to]
+func (r *BusRepository) HasObservedArrival(configID int64, from, to time.Time) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM bus_arrivals
+			  WHERE route_config_id = ? AND is_scheduled = 0 AND arrival_time BETWEEN ? AND ?)`
+	var exists bool
+	if err := r.db.QueryRow(query, configID, dbTime(from), dbTime(to)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check observed arrivals: %w", err)
+	}
+	return exists, nil
+}
+
+// FindHeartbeats returns the minutes in which a config was polled, oldest first
+func (r *BusRepository) FindHeartbeats(configID int64, from, to time.Time) ([]time.Time, error) {
+	query := `SELECT minute FROM collection_heartbeats
+		WHERE route_config_id = ? AND minute BETWEEN ? AND ?
+		ORDER BY minute ASC`
+
+	rows, err := r.db.Query(query, configID, dbTime(from), dbTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	var minutes []time.Time
+	for rows.Next() {
+		var minute time.Time
+		if err := rows.Scan(&minute); err != nil {
+			return nil, fmt.Errorf("failed to scan heartbeat: %w", err)
+		}
+		minutes = append(minutes, minute)
+	}
+
+	return minutes, rows.Err()
+}
+
+// GetApproachPath returns the stored approach path of an arrival (empty if none was recorded)
+func (r *BusRepository) GetApproachPath(arrivalID int64) ([]model.ApproachPoint, error) {
+	var raw sql.NullString
+	err := r.db.QueryRow("SELECT approach_path FROM bus_arrivals WHERE id = ?", arrivalID).Scan(&raw)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query approach path: %w", err)
+	}
+
+	path := []model.ApproachPoint{}
+	if raw.Valid && raw.String != "" {
+		if err := json.Unmarshal([]byte(raw.String), &path); err != nil {
+			return nil, fmt.Errorf("failed to decode approach path: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// GetSeatsByApproachDistance averages the seat readings in a config's stored approach
+// paths, keyed by how many stops away the bus was. Readings outside the plausible
+// seat range are skipped; arrivals without a path don't contribute.
+func (r *BusRepository) GetSeatsByApproachDistance(configID int64, from, to *time.Time) (map[int]float64, error) {
+	query := `SELECT approach_path FROM bus_arrivals
+			  WHERE route_config_id = ? AND approach_path IS NOT NULL AND approach_path != '' AND is_scheduled = 0`
+	args := []interface{}{configID}
+
+	if from != nil {
+		query += " AND arrival_time >= ?"
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		query += " AND arrival_time <= ?"
+		args = append(args, dbTime(*to))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approach paths: %w", err)
+	}
+	defer rows.Close()
+
+	sums := make(map[int]int)
+	counts := make(map[int]int)
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("failed to scan approach path: %w", err)
+		}
+		var path []model.ApproachPoint
+		if err := json.Unmarshal([]byte(raw), &path); err != nil {
+			return nil, fmt.Errorf("failed to decode approach path: %w", err)
+		}
+		for _, p := range path {
+			if p.Seats < 0 || p.Seats > model.MaxPlausibleSeats {
+				continue
+			}
+			sums[p.LocationNo] += p.Seats
+			counts[p.LocationNo]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	avgs := make(map[int]float64, len(counts))
+	for loc, n := range counts {
+		avgs[loc] = float64(sums[loc]) / float64(n)
+	}
+	return avgs, nil
 }
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"bus_history/internal/model"
+	"testing"
+	"time"
+)
+
+// tripArrivals builds one bus's arrivals from (sta_order, minutes after the first) pairs
+func tripArrivals(stops ...[2]int) []*model.BusArrivalWithConfig {
+	base := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+	arrivals := make([]*model.BusArrivalWithConfig, len(stops))
+	for i, s := range stops {
+		arrivals[i] = &model.BusArrivalWithConfig{
+			BusArrival: model.BusArrival{ID: int64(i + 1), ArrivalTime: base.Add(time.Duration(s[1]) * time.Minute)},
+			StaOrder:   s[0],
+		}
+	}
+	return arrivals
+}
+
+func TestTripSegment(t *testing.T) {
+	tests := []struct {
+		name      string
+		stops     [][2]int
+		target    int
+		opts      model.TripOptions
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name:      "straight trip",
+			stops:     [][2]int{{3, 0}, {8, 10}, {15, 25}, {22, 40}},
+			target:    1,
+			wantStart: 0, wantEnd: 3,
+		},
+		{
+			name: "loop route wraps without tolerance",
+			// Circular route: after the last station (30) the order restarts at 2
+			stops:     [][2]int{{20, 0}, {30, 12}, {2, 20}, {8, 30}},
+			target:    1,
+			wantStart: 0, wantEnd: 1,
+		},
+		{
+			name:      "loop route wrap kept with tolerance",
+			stops:     [][2]int{{20, 0}, {30, 12}, {2, 20}, {8, 30}},
+			target:    1,
+			opts:      model.TripOptions{OrderTolerance: 28},
+			wantStart: 0, wantEnd: 3,
+		},
+		{
+			name:      "small inconsistent drop within tolerance",
+			stops:     [][2]int{{10, 0}, {20, 10}, {18, 12}, {25, 20}},
+			target:    0,
+			opts:      model.TripOptions{OrderTolerance: 2},
+			wantStart: 0, wantEnd: 3,
+		},
+		{
+			name:      "drop beyond tolerance starts a new trip",
+			stops:     [][2]int{{10, 0}, {20, 10}, {15, 12}, {25, 20}},
+			target:    0,
+			opts:      model.TripOptions{OrderTolerance: 2},
+			wantStart: 0, wantEnd: 1,
+		},
+		{
+			name:      "sparse stations with a large gap stay one trip",
+			stops:     [][2]int{{3, 0}, {40, 55}, {70, 110}},
+			target:    1,
+			wantStart: 0, wantEnd: 2,
+		},
+		{
+			name:      "gap beyond MaxGapMinutes splits a sparse trip",
+			stops:     [][2]int{{3, 0}, {40, 65}, {70, 110}},
+			target:    2,
+			wantStart: 1, wantEnd: 2,
+		},
+		{
+			name:      "back-to-back runs are not stitched",
+			stops:     [][2]int{{3, 0}, {40, 30}, {3, 45}, {40, 75}},
+			target:    2,
+			opts:      model.TripOptions{OrderTolerance: 2},
+			wantStart: 2, wantEnd: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tripSegment(tripArrivals(tt.stops...), tt.target, tripOptionDefaults(tt.opts))
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("tripSegment = [%d, %d], want [%d, %d]", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestTripOptionDefaults(t *testing.T) {
+	got := tripOptionDefaults(model.TripOptions{OrderTolerance: -3})
+	want := model.TripOptions{WindowHours: 6, OrderTolerance: 0, MaxGapMinutes: 60}
+	if got != want {
+		t.Errorf("tripOptionDefaults = %+v, want %+v", got, want)
+	}
+
+	set := model.TripOptions{WindowHours: 2, OrderTolerance: 1, MaxGapMinutes: 15}
+	if got := tripOptionDefaults(set); got != set {
+		t.Errorf("tripOptionDefaults changed set options: %+v", got)
+	}
+}
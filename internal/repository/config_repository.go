@@ -16,10 +16,30 @@ func NewConfigRepository(db *sql.DB) *ConfigRepository {
 	return &ConfigRepository{db: db}
 }
 
-// FindAll retrieves all route configs
+// routeConfigColumns is the column list matched by scanRouteConfig
+const routeConfigColumns = `id, route_id, route_name, display_name, station_id, station_name, direction, route_type, route_group, sta_order, is_active,
+	expected_headway_min, interval_ms, min_record_gap_sec, record_approach, stop_type, record_mode, region, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRouteConfig scans a row selected with routeConfigColumns
+func scanRouteConfig(row rowScanner) (*model.RouteConfig, error) {
+	var cfg model.RouteConfig
+	err := row.Scan(&cfg.ID, &cfg.RouteID, &cfg.RouteName, &cfg.DisplayName, &cfg.StationID, &cfg.StationName, &cfg.Direction, &cfg.RouteType, &cfg.RouteGroup, &cfg.StaOrder,
+		&cfg.IsActive, &cfg.ExpectedHeadwayMin, &cfg.IntervalMs, &cfg.MinRecordGapSec, &cfg.RecordApproach, &cfg.StopType, &cfg.RecordMode, &cfg.Region, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// FindAll retrieves all route configs that have not been deleted
 func (r *ConfigRepository) FindAll() ([]*model.RouteConfig, error) {
-	query := `SELECT id, route_id, route_name, station_id, station_name, direction, sta_order, is_active, created_at, updated_at 
-			  FROM route_configs ORDER BY route_name ASC, sta_order ASC`
+	query := `SELECT ` + routeConfigColumns + `
+			  FROM route_configs WHERE deleted_at IS NULL ORDER BY route_name ASC, sta_order ASC`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -29,25 +49,95 @@ func (r *ConfigRepository) FindAll() ([]*model.RouteConfig, error) {
 
 	var configs []*model.RouteConfig
 	for rows.Next() {
-		var cfg model.RouteConfig
-		if err := rows.Scan(&cfg.ID, &cfg.RouteID, &cfg.RouteName, &cfg.StationID, &cfg.StationName, &cfg.Direction, &cfg.StaOrder,
-			&cfg.IsActive, &cfg.CreatedAt, &cfg.UpdatedAt); err != nil {
+		cfg, err := scanRouteConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan route config: %w", err)
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, rows.Err()
+}
+
+// FindDeleted retrieves the soft-deleted route configs, most recently deleted first
+func (r *ConfigRepository) FindDeleted() ([]*model.RouteConfig, error) {
+	query := `SELECT ` + routeConfigColumns + `
+			  FROM route_configs WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted route configs: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*model.RouteConfig
+	for rows.Next() {
+		cfg, err := scanRouteConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan route config: %w", err)
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, rows.Err()
+}
+
+// FindAllWithStats retrieves all configs (like FindAll) with their arrival count and
+// latest arrival time, in a single query
+func (r *ConfigRepository) FindAllWithStats() ([]*model.RouteConfigWithStats, error) {
+	query := `SELECT ` + routeConfigColumns + `, COALESCE(s.arrival_count, 0), s.last_arrival
+			  FROM route_configs
+			  LEFT JOIN (
+				SELECT route_config_id, COUNT(*) AS arrival_count, MAX(arrival_time) AS last_arrival
+				FROM bus_arrivals WHERE is_scheduled = 0 GROUP BY route_config_id
+			  ) s ON s.route_config_id = route_configs.id
+			  WHERE deleted_at IS NULL ORDER BY route_name ASC, sta_order ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route configs with stats: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*model.RouteConfigWithStats
+	for rows.Next() {
+		var count int64
+		var lastArrival sql.NullString
+		cfg, err := scanRouteConfig(extraColumns{rows, []interface{}{&count, &lastArrival}})
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan route config: %w", err)
 		}
-		configs = append(configs, &cfg)
+
+		c := &model.RouteConfigWithStats{RouteConfig: *cfg, ArrivalCount: count}
+		if lastArrival.Valid {
+			t, err := parseDBTime(lastArrival.String)
+			if err != nil {
+				return nil, err
+			}
+			c.LastArrivalAt = &t
+		}
+		configs = append(configs, c)
 	}
 
 	return configs, rows.Err()
 }
 
-// FindByID retrieves a route config by ID
+// extraColumns scans columns selected after routeConfigColumns into extra
+type extraColumns struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (e extraColumns) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// FindByID retrieves a route config by ID, including soft-deleted ones
 func (r *ConfigRepository) FindByID(id int64) (*model.RouteConfig, error) {
-	query := `SELECT id, route_id, route_name, station_id, station_name, direction, sta_order, is_active, created_at, updated_at 
+	query := `SELECT ` + routeConfigColumns + `
 			  FROM route_configs WHERE id = ?`
 
-	var cfg model.RouteConfig
-	err := r.db.QueryRow(query, id).Scan(&cfg.ID, &cfg.RouteID, &cfg.RouteName, &cfg.StationID,
-		&cfg.StationName, &cfg.Direction, &cfg.StaOrder, &cfg.IsActive, &cfg.CreatedAt, &cfg.UpdatedAt)
+	cfg, err := scanRouteConfig(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -55,13 +145,13 @@ func (r *ConfigRepository) FindByID(id int64) (*model.RouteConfig, error) {
 		return nil, fmt.Errorf("failed to query route config: %w", err)
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 // FindActive retrieves all active route configs
 func (r *ConfigRepository) FindActive() ([]*model.RouteConfig, error) {
-	query := `SELECT id, route_id, route_name, station_id, station_name, direction, sta_order, is_active, created_at, updated_at 
-			  FROM route_configs WHERE is_active = TRUE ORDER BY route_name ASC, sta_order ASC`
+	query := `SELECT ` + routeConfigColumns + `
+			  FROM route_configs WHERE is_active = TRUE AND deleted_at IS NULL ORDER BY route_name ASC, sta_order ASC`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -71,23 +161,62 @@ func (r *ConfigRepository) FindActive() ([]*model.RouteConfig, error) {
 
 	var configs []*model.RouteConfig
 	for rows.Next() {
-		var cfg model.RouteConfig
-		if err := rows.Scan(&cfg.ID, &cfg.RouteID, &cfg.RouteName, &cfg.StationID, &cfg.StationName, &cfg.Direction, &cfg.StaOrder,
-			&cfg.IsActive, &cfg.CreatedAt, &cfg.UpdatedAt); err != nil {
+		cfg, err := scanRouteConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan route config: %w", err)
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, rows.Err()
+}
+
+// FindByRoute retrieves all route configs for a route
+func (r *ConfigRepository) FindByRoute(routeID string) ([]*model.RouteConfig, error) {
+	query := `SELECT ` + routeConfigColumns + `
+			  FROM route_configs WHERE route_id = ? AND deleted_at IS NULL ORDER BY sta_order ASC`
+
+	rows, err := r.db.Query(query, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route configs by route: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*model.RouteConfig
+	for rows.Next() {
+		cfg, err := scanRouteConfig(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan route config: %w", err)
 		}
-		configs = append(configs, &cfg)
+		configs = append(configs, cfg)
 	}
 
 	return configs, rows.Err()
 }
 
+// CountConfigs returns the number of configs and how many of them are active
+func (r *ConfigRepository) CountConfigs() (total int, active int, err error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_active THEN 1 ELSE 0 END), 0)
+		FROM route_configs WHERE deleted_at IS NULL`
+	if err := r.db.QueryRow(query).Scan(&total, &active); err != nil {
+		return 0, 0, fmt.Errorf("failed to count route configs: %w", err)
+	}
+	return total, active, nil
+}
+
 // Create creates a new route config
 func (r *ConfigRepository) Create(cfg *model.RouteConfig) error {
-	query := `INSERT INTO route_configs (route_id, route_name, station_id, station_name, direction, sta_order, is_active) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO route_configs (route_id, route_name, display_name, station_id, station_name, direction, route_type, route_group, sta_order, is_active, expected_headway_min, interval_ms, min_record_gap_sec, record_approach, stop_type, record_mode, region) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, cfg.RouteID, cfg.RouteName, cfg.StationID, cfg.StationName, cfg.Direction, cfg.StaOrder, cfg.IsActive)
+	if cfg.StopType == "" {
+		cfg.StopType = model.StopTypeMixed
+	}
+	if cfg.RecordMode == "" {
+		cfg.RecordMode = model.RecordModePass
+	}
+	result, err := r.db.Exec(query, cfg.RouteID, cfg.RouteName, cfg.DisplayName, cfg.StationID, cfg.StationName, cfg.Direction, cfg.RouteType, cfg.RouteGroup, cfg.StaOrder, cfg.IsActive,
+		cfg.ExpectedHeadwayMin, cfg.IntervalMs, cfg.MinRecordGapSec, cfg.RecordApproach, cfg.StopType, cfg.RecordMode, cfg.Region)
 	if err != nil {
 		return fmt.Errorf("failed to create route config: %w", err)
 	}
@@ -101,8 +230,9 @@ func (r *ConfigRepository) Create(cfg *model.RouteConfig) error {
 	return nil
 }
 
-// Update updates an existing route config
-func (r *ConfigRepository) Update(id int64, stationName *string, isActive *bool) error {
+// Update updates an existing route config; nil fields are left unchanged.
+// An empty displayName clears the display name.
+func (r *ConfigRepository) Update(id int64, stationName *string, isActive *bool, displayName *string) error {
 	query := "UPDATE route_configs SET"
 	args := []interface{}{}
 	updates := []string{}
@@ -115,6 +245,10 @@ func (r *ConfigRepository) Update(id int64, stationName *string, isActive *bool)
 		updates = append(updates, " is_active = ?")
 		args = append(args, *isActive)
 	}
+	if displayName != nil {
+		updates = append(updates, " display_name = ?")
+		args = append(args, *displayName)
+	}
 
 	if len(updates) == 0 {
 		return nil
@@ -135,9 +269,11 @@ func (r *ConfigRepository) Update(id int64, stationName *string, isActive *bool)
 	return nil
 }
 
-// Delete deletes a route config by ID
+// Delete soft-deletes a route config: it is deactivated and hidden from the
+// Find* listings, but its arrivals keep joining to it so history is preserved
 func (r *ConfigRepository) Delete(id int64) error {
-	query := "DELETE FROM route_configs WHERE id = ?"
+	query := `UPDATE route_configs SET is_active = FALSE, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ? AND deleted_at IS NULL`
 	_, err := r.db.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete route config: %w", err)
@@ -145,12 +281,385 @@ func (r *ConfigRepository) Delete(id int64) error {
 	return nil
 }
 
+// HardDelete permanently removes a route config together with its arrivals, timetable,
+// aggregates and heartbeats
+func (r *ConfigRepository) HardDelete(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM bus_arrivals WHERE route_config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete arrivals: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM config_schedule_exceptions WHERE config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete schedule exceptions: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM config_schedules WHERE config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete timetable: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM arrival_aggregates WHERE route_config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete arrival aggregates: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM collection_heartbeats WHERE route_config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete heartbeats: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM route_configs WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete route config: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeduplicateConfigs collapses configs with the same route, station and direction
+// into the oldest of them: the duplicates' arrivals, aggregates, heartbeats, timetables and
+// schedule exceptions are moved over (the kept config's own entries win) and the duplicates removed.
+// The kept config stays active if any of the group was. Returns the number removed.
+func (r *ConfigRepository) DeduplicateConfigs() (int, error) {
+	rows, err := r.db.Query(`SELECT id, route_id, station_id, direction FROM route_configs
+			  WHERE deleted_at IS NULL ORDER BY id ASC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query route configs: %w", err)
+	}
+
+	type key struct{ routeID, stationID, direction string }
+	keep := make(map[key]int64)
+	dups := make(map[int64]int64) // duplicate ID -> kept ID
+	for rows.Next() {
+		var id int64
+		var k key
+		if err := rows.Scan(&id, &k.routeID, &k.stationID, &k.direction); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan route config: %w", err)
+		}
+		if keptID, ok := keep[k]; ok {
+			dups[id] = keptID
+		} else {
+			keep[k] = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(dups) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for dupID, keptID := range dups {
+		if _, err := tx.Exec("UPDATE bus_arrivals SET route_config_id = ? WHERE route_config_id = ?", keptID, dupID); err != nil {
+			return 0, fmt.Errorf("failed to move arrivals: %w", err)
+		}
+		if _, err := tx.Exec("UPDATE OR IGNORE collection_heartbeats SET route_config_id = ? WHERE route_config_id = ?", keptID, dupID); err != nil {
+			return 0, fmt.Errorf("failed to move heartbeats: %w", err)
+		}
+		if _, err := tx.Exec("UPDATE OR IGNORE config_schedule_exceptions SET config_id = ? WHERE config_id = ?", keptID, dupID); err != nil {
+			return 0, fmt.Errorf("failed to move schedule exceptions: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE route_configs SET is_active = is_active OR (SELECT is_active FROM route_configs WHERE id = ?),
+				updated_at = CURRENT_TIMESTAMP WHERE id = ?`, dupID, keptID); err != nil {
+			return 0, fmt.Errorf("failed to update kept config: %w", err)
+		}
+		// Rows left behind collided with the kept config's own
+		if _, err := tx.Exec("DELETE FROM collection_heartbeats WHERE route_config_id = ?", dupID); err != nil {
+			return 0, fmt.Errorf("failed to delete heartbeats: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM config_schedule_exceptions WHERE config_id = ?", dupID); err != nil {
+			return 0, fmt.Errorf("failed to delete schedule exceptions: %w", err)
+		}
+		if _, err := tx.Exec("UPDATE OR IGNORE config_schedules SET config_id = ? WHERE config_id = ?", keptID, dupID); err != nil {
+			return 0, fmt.Errorf("failed to move timetable: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM config_schedules WHERE config_id = ?", dupID); err != nil {
+			return 0, fmt.Errorf("failed to delete timetable: %w", err)
+		}
+		// Buckets both configs have are summed; "WHERE true" keeps SQLite from
+		// reading ON CONFLICT as a join constraint
+		if _, err := tx.Exec(`INSERT INTO arrival_aggregates (route_config_id, bucket, arrival_count, boarding_sum, boarding_count)
+				SELECT ?, bucket, arrival_count, boarding_sum, boarding_count FROM arrival_aggregates WHERE route_config_id = ? AND true
+				ON CONFLICT(route_config_id, bucket) DO UPDATE SET
+					arrival_count = arrival_count + excluded.arrival_count,
+					boarding_sum = boarding_sum + excluded.boarding_sum,
+					boarding_count = boarding_count + excluded.boarding_count`, keptID, dupID); err != nil {
+			return 0, fmt.Errorf("failed to move arrival aggregates: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM arrival_aggregates WHERE route_config_id = ?", dupID); err != nil {
+			return 0, fmt.Errorf("failed to delete arrival aggregates: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM route_configs WHERE id = ?", dupID); err != nil {
+			return 0, fmt.Errorf("failed to delete route config: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(dups), nil
+}
+
 // UpdateStatus updates the is_active status of a route config
 func (r *ConfigRepository) UpdateStatus(id int64, isActive bool) error {
-	query := "UPDATE route_configs SET is_active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	query := "UPDATE route_configs SET is_active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
 	_, err := r.db.Exec(query, isActive, id)
 	if err != nil {
 		return fmt.Errorf("failed to update route config status: %w", err)
 	}
 	return nil
 }
+
+// UpdateExpectedHeadway sets the scheduled headway (minutes) used for gap alerts; 0 disables alerts
+func (r *ConfigRepository) UpdateExpectedHeadway(id int64, minutes int) error {
+	query := "UPDATE route_configs SET expected_headway_min = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, minutes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update expected headway: %w", err)
+	}
+	return nil
+}
+
+// UpdateInterval sets the per-config polling interval override; nil reverts to the global interval
+func (r *ConfigRepository) UpdateInterval(id int64, intervalMs *int) error {
+	query := "UPDATE route_configs SET interval_ms = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, intervalMs, id)
+	if err != nil {
+		return fmt.Errorf("failed to update polling interval: %w", err)
+	}
+	return nil
+}
+
+// UpdateMinRecordGap sets the per-config minimum seconds between records of one bus;
+// nil reverts to the global setting
+func (r *ConfigRepository) UpdateMinRecordGap(id int64, seconds *int) error {
+	query := "UPDATE route_configs SET min_record_gap_sec = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, seconds, id)
+	if err != nil {
+		return fmt.Errorf("failed to update minimum record gap: %w", err)
+	}
+	return nil
+}
+
+// UpdateRecordApproach turns storing per-arrival approach paths on or off
+func (r *ConfigRepository) UpdateRecordApproach(id int64, enabled bool) error {
+	query := "UPDATE route_configs SET record_approach = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, enabled, id)
+	if err != nil {
+		return fmt.Errorf("failed to update approach recording: %w", err)
+	}
+	return nil
+}
+
+// UpdateRouteGroup sets the group label of a config; "" removes it from its group
+func (r *ConfigRepository) UpdateRouteGroup(id int64, group string) error {
+	query := "UPDATE route_configs SET route_group = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, group, id)
+	if err != nil {
+		return fmt.Errorf("failed to update route group: %w", err)
+	}
+	return nil
+}
+
+// UpdateStopType sets how the config's seat delta is interpreted
+func (r *ConfigRepository) UpdateStopType(id int64, stopType string) error {
+	query := "UPDATE route_configs SET stop_type = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, stopType, id)
+	if err != nil {
+		return fmt.Errorf("failed to update stop type: %w", err)
+	}
+	return nil
+}
+
+// UpdateRecordMode sets when the config's arrivals are recorded
+func (r *ConfigRepository) UpdateRecordMode(id int64, mode string) error {
+	query := "UPDATE route_configs SET record_mode = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, mode, id)
+	if err != nil {
+		return fmt.Errorf("failed to update record mode: %w", err)
+	}
+	return nil
+}
+
+// SetScheduleTimes replaces a config's timetable with the given "HH:MM" times
+func (r *ConfigRepository) SetScheduleTimes(configID int64, times []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM config_schedules WHERE config_id = ?", configID); err != nil {
+		return fmt.Errorf("failed to clear timetable: %w", err)
+	}
+	for _, t := range times {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO config_schedules (config_id, time) VALUES (?, ?)", configID, t); err != nil {
+			return fmt.Errorf("failed to save timetable: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// FindScheduleTimes returns a config's timetable as "HH:MM" times in order
+func (r *ConfigRepository) FindScheduleTimes(configID int64) ([]string, error) {
+	rows, err := r.db.Query("SELECT time FROM config_schedules WHERE config_id = ? ORDER BY time ASC", configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timetable: %w", err)
+	}
+	defer rows.Close()
+
+	times := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan timetable: %w", err)
+		}
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+// UpdateStaOrder sets the config's position on its route
+func (r *ConfigRepository) UpdateStaOrder(id int64, staOrder int) error {
+	query := "UPDATE route_configs SET sta_order = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, staOrder, id)
+	if err != nil {
+		return fmt.Errorf("failed to update sta_order: %w", err)
+	}
+	return nil
+}
+
+// FindStaOrderConflicts lists configs of a route that share a sta_order across
+// different stations, or have none (<= 0), either of which breaks trip reconstruction
+func (r *ConfigRepository) FindStaOrderConflicts(routeID string) ([]model.ConflictPair, error) {
+	configs, err := r.FindByRoute(routeID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []model.ConflictPair{}
+	for i, a := range configs {
+		if a.StaOrder <= 0 {
+			conflicts = append(conflicts, model.ConflictPair{A: a, Reason: "missing"})
+			continue
+		}
+		// Sorted by sta_order, so equal ones are adjacent
+		for _, b := range configs[i+1:] {
+			if b.StaOrder != a.StaOrder {
+				break
+			}
+			if b.StationID != a.StationID {
+				conflicts = append(conflicts, model.ConflictPair{A: a, B: b, Reason: "duplicate"})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// SetScheduleException enables or disables collection for a config on one date,
+// replacing any exception already set for that date
+func (r *ConfigRepository) SetScheduleException(configID int64, date string, enabled bool) error {
+	query := `INSERT INTO config_schedule_exceptions (config_id, date, enabled) VALUES (?, ?, ?)
+			  ON CONFLICT(config_id, date) DO UPDATE SET enabled = excluded.enabled`
+	_, err := r.db.Exec(query, configID, date, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set schedule exception: %w", err)
+	}
+	return nil
+}
+
+// RemoveScheduleException restores the normal schedule for a config on one date
+func (r *ConfigRepository) RemoveScheduleException(configID int64, date string) error {
+	_, err := r.db.Exec("DELETE FROM config_schedule_exceptions WHERE config_id = ? AND date = ?", configID, date)
+	if err != nil {
+		return fmt.Errorf("failed to remove schedule exception: %w", err)
+	}
+	return nil
+}
+
+// FindScheduleExceptions returns the exceptions of a config ordered by date
+func (r *ConfigRepository) FindScheduleExceptions(configID int64) ([]model.ScheduleException, error) {
+	query := "SELECT config_id, date, enabled FROM config_schedule_exceptions WHERE config_id = ? ORDER BY date ASC"
+	rows, err := r.db.Query(query, configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := []model.ScheduleException{}
+	for rows.Next() {
+		var e model.ScheduleException
+		if err := rows.Scan(&e.ConfigID, &e.Date, &e.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule exception: %w", err)
+		}
+		exceptions = append(exceptions, e)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// FindScheduleException returns the exception for a config on a date, or nil if none is set
+func (r *ConfigRepository) FindScheduleException(configID int64, date string) (*model.ScheduleException, error) {
+	e := &model.ScheduleException{}
+	query := "SELECT config_id, date, enabled FROM config_schedule_exceptions WHERE config_id = ? AND date = ?"
+	err := r.db.QueryRow(query, configID, date).Scan(&e.ConfigID, &e.Date, &e.Enabled)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find schedule exception: %w", err)
+	}
+	return e, nil
+}
+
+// PlanImport compares imported configs against the existing ones by route and station.
+// Duplicates within the import itself are only counted once.
+func (r *ConfigRepository) PlanImport(imported []*model.RouteConfig) (*model.ImportPlan, error) {
+	existing, err := r.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ routeID, stationID string }
+	byKey := make(map[key]*model.RouteConfig, len(existing))
+	for _, cfg := range existing {
+		byKey[key{cfg.RouteID, cfg.StationID}] = cfg
+	}
+
+	plan := &model.ImportPlan{
+		New:       []*model.RouteConfig{},
+		Existing:  []*model.RouteConfig{},
+		Conflicts: []model.ImportConflict{},
+	}
+	seen := make(map[key]bool)
+	for _, cfg := range imported {
+		k := key{cfg.RouteID, cfg.StationID}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		current, ok := byKey[k]
+		switch {
+		case !ok:
+			plan.New = append(plan.New, cfg)
+		case current.Direction != cfg.Direction:
+			plan.Conflicts = append(plan.Conflicts, model.ImportConflict{Imported: cfg, Existing: current})
+		default:
+			plan.Existing = append(plan.Existing, current)
+		}
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"bus_history/internal/model"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+)
+
+// IsStorageUnavailable reports whether err means the database file can't be
+// written at all (disk full, read-only, I/O failure or a detached drive), as
+// opposed to a failure of one statement such as a constraint or a busy lock
+func IsStorageUnavailable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrFull, sqlite3.ErrReadonly, sqlite3.ErrIoErr, sqlite3.ErrCantOpen:
+		return true
+	}
+	return false
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems it reports.
+// An empty result means the database file is consistent.
+func IntegrityCheck(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	problems := []string{}
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+
+	return problems, rows.Err()
+}
+
+// MissingIndexes returns the names in expected that have no index in the database
+func MissingIndexes(db *sql.DB, expected []string) ([]string, error) {
+	missing := []string{}
+	for _, name := range expected {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", name).Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up index %s: %w", name, err)
+		}
+		if count == 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// DeleteOrphanArrivals removes arrivals whose route config row no longer exists.
+// Soft-deleted configs still exist, so their history is kept.
+func DeleteOrphanArrivals(db *sql.DB) (int64, error) {
+	result, err := db.Exec(`DELETE FROM bus_arrivals
+		WHERE route_config_id NOT IN (SELECT id FROM route_configs)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned arrivals: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// AppliedMigrations lists the recorded schema migrations, oldest first
+func AppliedMigrations(db *sql.DB) ([]model.AppliedMigration, error) {
+	rows, err := db.Query("SELECT version, statement, applied_at FROM schema_migrations ORDER BY version ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := []model.AppliedMigration{}
+	for rows.Next() {
+		var m model.AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Statement, &m.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, rows.Err()
+}
+
+// RecordMigration marks a schema migration as applied
+func RecordMigration(db *sql.DB, version int, statement string) error {
+	_, err := db.Exec("INSERT OR IGNORE INTO schema_migrations (version, statement) VALUES (?, ?)", version, statement)
+	if err != nil {
+		return fmt.Errorf("failed to record schema migration: %w", err)
+	}
+	return nil
+}
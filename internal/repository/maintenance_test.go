@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"testing"
+)
+
+func TestIsStorageUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"disk full", sqlite3.Error{Code: sqlite3.ErrFull}, true},
+		{"read-only", sqlite3.Error{Code: sqlite3.ErrReadonly}, true},
+		{"I/O error", sqlite3.Error{Code: sqlite3.ErrIoErr, ExtendedCode: sqlite3.ErrIoErrWrite}, true},
+		{"can't open", sqlite3.Error{Code: sqlite3.ErrCantOpen}, true},
+		{"wrapped", fmt.Errorf("failed to create bus arrival: %w", sqlite3.Error{Code: sqlite3.ErrFull}), true},
+		{"constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, false},
+		{"not a sqlite error", errors.New("failed to encode approach path"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStorageUnavailable(tt.err); got != tt.want {
+				t.Errorf("IsStorageUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// dbTimeFormats are the layouts go-sqlite3 writes time.Time values with.
+// Aggregates such as MIN(arrival_time) come back as plain text, so they
+// have to be parsed by hand.
+var dbTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// displayZone is the zone arrival times are stored in and bucketed by, whatever
+// zone the host was in when they were recorded
+var displayZone = loadDisplayZone()
+
+func loadDisplayZone() *time.Location {
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		return time.FixedZone("KST", 9*60*60)
+	}
+	return loc
+}
+
+// dbTime converts a time bound as a query argument to displayZone. Timestamps are
+// compared as text, so an argument in any other zone would be off by the difference.
+func dbTime(t time.Time) time.Time {
+	return t.In(displayZone)
+}
+
+// zoneModifier is the SQLite date modifier moving a UTC time into displayZone.
+// SQLite date functions read the offset stored with a timestamp and work in UTC,
+// so this buckets rows correctly whatever offset they were stored with. Korea
+// has no DST, so one offset holds for every row.
+func zoneModifier() string {
+	_, offset := time.Now().In(displayZone).Zone()
+	return fmt.Sprintf("'%+d minutes'", offset/60)
+}
+
+// localHourSQL returns SQL for the two-digit hour of a timestamp column in displayZone
+func localHourSQL(col string) string {
+	return "strftime('%H', " + col + ", " + zoneModifier() + ")"
+}
+
+// localDaySQL returns SQL for the YYYY-MM-DD date of a timestamp column in displayZone
+func localDaySQL(col string) string {
+	return "date(" + col + ", " + zoneModifier() + ")"
+}
+
+// localDayHourSQL returns SQL for "YYYY-MM-DD HH" of a timestamp column in displayZone
+func localDayHourSQL(col string) string {
+	return "strftime('%Y-%m-%d %H', " + col + ", " + zoneModifier() + ")"
+}
+
+// julianToTime converts a SQLite julianday value to a time, rounded to the
+// millisecond to absorb the floating point error
+func julianToTime(jd float64) time.Time {
+	const unixEpochJD = 2440587.5
+	ms := math.Round((jd - unixEpochJD) * 86400000)
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// parseDBTime parses a timestamp stored as text by the SQLite driver
+func parseDBTime(s string) (time.Time, error) {
+	for _, layout := range dbTimeFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"bus_history/internal/model"
+	"time"
+)
+
+// BusStore is the arrival storage used by the app and the collector.
+// BusRepository is the SQL implementation.
+type BusStore interface {
+	Create(arrival *model.BusArrival) error
+	UpdateSeatsAfter(id int64, seatsAfter int) error
+	FindMissingSeatsAfter(since time.Time) ([]*model.BusArrival, error)
+	LastRecordedAt(configID int64, busNumber string) (*time.Time, error)
+	FindByID(id int64) (*model.BusArrivalWithConfig, error)
+	FindByFilter(filter model.BusArrivalFilter) ([]*model.BusArrivalWithConfig, int64, error)
+	FindByFilterCursor(filter model.BusArrivalFilter, afterTime time.Time, afterID int64, limit int) ([]*model.BusArrivalWithConfig, error)
+	FindByBusNumber(busNumber string, from, to *time.Time, page, limit int) ([]*model.BusArrivalWithConfig, int64, error)
+	ForEachByConfig(configID int64, from, to *time.Time, fn func(*model.BusArrivalWithConfig) error) (int64, error)
+	GetStatistics(filter model.BusArrivalFilter) (*model.BusArrivalStats, error)
+	GetBoardingByRouteType(from, to *time.Time, weighted bool) (map[string]model.BusArrivalStats, error)
+	GetTopBoardings(routeID, stationID string, from, to *time.Time, n int) ([]*model.BusArrivalWithConfig, error)
+	GetTripByArrivalID(id int64, opts model.TripOptions) ([]*model.BusArrivalWithConfig, error)
+	FindHeadwayGaps(cfg *model.RouteConfig, from time.Time, to *time.Time, factor float64) ([]model.HeadwayAlert, error)
+	GetServiceSpan(configID int64, from, to *time.Time) ([]model.DailySpan, error)
+	GetDatesWithData(configID int64, loc *time.Location) ([]string, error)
+	GetDailyBoarding(configID int64, from, to *time.Time) ([]model.DailyBoarding, error)
+	GetHourBoardingCorrelation(configID int64, from, to *time.Time) (float64, int, error)
+	GetRetryStats(configID int64, from, to *time.Time) ([]model.RetryBucket, error)
+	GetApproachDurationStats(configID int64) (*model.ApproachDurationStats, error)
+	GetClockDriftReport(maxSkewMin, limit int) (*model.ClockDriftReport, error)
+	GetRegularityScore(configID int64, from, to *time.Time) (float64, int, error)
+	GetBoardingSeries(configID int64, from, to *time.Time, maxPoints int) ([]model.SeriesPoint, error)
+	GetHourlyCountsVsExpected(configID int64, expectedPerHour float64, from, to *time.Time) ([]model.HourlyComparison, error)
+	GetDirectionSplit(configID int64, from, to *time.Time) (map[string]int, error)
+	GetStatsByDirectionHour(stationID string, from, to *time.Time) (map[string][24]float64, error)
+	GetBusiestStations(routeID string, from, to *time.Time) ([]model.StationRank, error)
+	GetRouteSnapshot(routeID string, at time.Time, window time.Duration) (*model.RouteSnapshot, error)
+	GetArrivalTotals(overview *model.SystemOverview, since time.Time) error
+	RecomputeBoarding(configID int64) (int64, error)
+	NormalizeStoredPlates() (int, error)
+	NormalizeStoredTimes() (int, error)
+	RecordHeartbeat(configID int64, minute time.Time) error
+	ProbeWrite() error
+	PreviewPurge(cutoff time.Time) (*model.PurgePreview, error)
+	HasObservedArrival(configID int64, from, to time.Time) (bool, error)
+	AddToAggregate(configID int64, bucket time.Time, boarding *int) error
+	FindAggregates(configID int64, from, to *time.Time) ([]model.ArrivalAggregate, error)
+	FindHeartbeats(configID int64, from, to time.Time) ([]time.Time, error)
+	GetApproachPath(arrivalID int64) ([]model.ApproachPoint, error)
+	GetSeatsByApproachDistance(configID int64, from, to *time.Time) (map[int]float64, error)
+}
+
+// ConfigStore is the route config storage used by the app and the collector.
+// ConfigRepository is the SQL implementation.
+type ConfigStore interface {
+	FindAll() ([]*model.RouteConfig, error)
+	FindAllWithStats() ([]*model.RouteConfigWithStats, error)
+	FindDeleted() ([]*model.RouteConfig, error)
+	FindByID(id int64) (*model.RouteConfig, error)
+	FindActive() ([]*model.RouteConfig, error)
+	FindByRoute(routeID string) ([]*model.RouteConfig, error)
+	CountConfigs() (total int, active int, err error)
+	Create(cfg *model.RouteConfig) error
+	Update(id int64, stationName *string, isActive *bool, displayName *string) error
+	Delete(id int64) error
+	HardDelete(id int64) error
+	DeduplicateConfigs() (int, error)
+	UpdateStatus(id int64, isActive bool) error
+	UpdateExpectedHeadway(id int64, minutes int) error
+	UpdateInterval(id int64, intervalMs *int) error
+	UpdateMinRecordGap(id int64, seconds *int) error
+	UpdateRouteGroup(id int64, group string) error
+	UpdateRecordApproach(id int64, enabled bool) error
+	UpdateStopType(id int64, stopType string) error
+	UpdateRecordMode(id int64, mode string) error
+	UpdateStaOrder(id int64, staOrder int) error
+	FindStaOrderConflicts(routeID string) ([]model.ConflictPair, error)
+	PlanImport(imported []*model.RouteConfig) (*model.ImportPlan, error)
+	SetScheduleException(configID int64, date string, enabled bool) error
+	RemoveScheduleException(configID int64, date string) error
+	FindScheduleExceptions(configID int64) ([]model.ScheduleException, error)
+	FindScheduleException(configID int64, date string) (*model.ScheduleException, error)
+	SetScheduleTimes(configID int64, times []string) error
+	FindScheduleTimes(configID int64) ([]string, error)
+}
+
+var (
+	_ BusStore    = (*BusRepository)(nil)
+	_ ConfigStore = (*ConfigRepository)(nil)
+)
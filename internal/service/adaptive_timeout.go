@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Adaptive request timeouts.
+//
+// Each client keeps an exponential moving average of its response latency
+// (time to response headers) and of the mean absolute deviation from it:
+//
+//	ema  = ema + alpha × (sample − ema)
+//	dev  = dev + alpha × (|sample − ema| − dev)
+//	p95 ≈ ema + 2 × dev
+//
+// and derives the per-request timeout as
+//
+//	timeout = clamp(k × p95, adaptiveMinTimeout, adaptiveMaxTimeout)
+//
+// so a fast region fails fast while a temporarily slow one gets more slack.
+// Until adaptiveWarmupSamples responses are seen the fixed timeout is used.
+const (
+	DefaultRequestTimeout = 30 * time.Second
+
+	adaptiveAlpha         = 0.2
+	adaptiveK             = 3.0
+	adaptiveMinTimeout    = 5 * time.Second
+	adaptiveMaxTimeout    = 60 * time.Second
+	adaptiveWarmupSamples = 5
+)
+
+// latencyEstimator tracks the EMA latency and deviation for one client
+type latencyEstimator struct {
+	mu      sync.Mutex
+	ema     float64 // seconds
+	dev     float64 // seconds
+	samples int
+}
+
+func (e *latencyEstimator) observe(d time.Duration) {
+	sample := d.Seconds()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.samples == 0 {
+		e.ema = sample
+		e.dev = sample / 2
+	} else {
+		diff := sample - e.ema
+		if diff < 0 {
+			diff = -diff
+		}
+		e.ema += adaptiveAlpha * (sample - e.ema)
+		e.dev += adaptiveAlpha * (diff - e.dev)
+	}
+	e.samples++
+}
+
+// timeout returns the current adaptive timeout, or fixed while warming up
+func (e *latencyEstimator) timeout(fixed time.Duration) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.samples < adaptiveWarmupSamples {
+		return fixed
+	}
+
+	p95 := e.ema + 2*e.dev
+	t := time.Duration(adaptiveK * p95 * float64(time.Second))
+	if t < adaptiveMinTimeout {
+		return adaptiveMinTimeout
+	}
+	if t > adaptiveMaxTimeout {
+		return adaptiveMaxTimeout
+	}
+	return t
+}
+
+// adaptiveTransport applies the estimator's timeout to every request and feeds
+// it the observed latency. Timed-out requests count as a sample of the timeout
+// so a region that suddenly slows down widens its own timeout.
+type adaptiveTransport struct {
+	base      http.RoundTripper
+	fixed     time.Duration
+	estimator *latencyEstimator
+}
+
+func (t *adaptiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.estimator.timeout(t.fixed)
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded {
+			t.estimator.observe(timeout)
+		}
+		return nil, err
+	}
+	t.estimator.observe(time.Since(start))
+
+	// The deadline also covers reading the body, so only cancel once it is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// newHTTPClient builds a client with either the fixed timeout or an adaptive one
+func newHTTPClient(adaptive bool) *http.Client {
+	if !adaptive {
+		return &http.Client{Timeout: DefaultRequestTimeout}
+	}
+	return &http.Client{
+		Transport: &adaptiveTransport{
+			base:      http.DefaultTransport,
+			fixed:     DefaultRequestTimeout,
+			estimator: &latencyEstimator{},
+		},
+	}
+}
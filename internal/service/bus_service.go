@@ -11,16 +11,44 @@ import (
 
 // BusService provides unified access to both GBIS (Gyeonggi) and Incheon bus APIs
 type BusService struct {
+	apiClient     *OpenAPIClient // Gyeonggi per-route arrivals, used by the collector
 	gbisClient    *GBISClient
 	incheonClient *IncheonClient
+
+	regionMu    sync.Mutex
+	regionCache map[string]Region // "route:<id>" / "station:<id>" -> region, see DetectRegion
+
+	routeStations *routeStationsCache
+
+	limiter *rateLimiter // Shared by all clients
 }
 
 // NewBusService creates a new unified bus service
-func NewBusService(gbisClient *GBISClient, incheonClient *IncheonClient) *BusService {
-	return &BusService{
+func NewBusService(apiClient *OpenAPIClient, gbisClient *GBISClient, incheonClient *IncheonClient) *BusService {
+	s := &BusService{
+		apiClient:     apiClient,
 		gbisClient:    gbisClient,
 		incheonClient: incheonClient,
+		regionCache:   make(map[string]Region),
+		routeStations: newRouteStationsCache(),
+		limiter:       &rateLimiter{},
+	}
+	if apiClient != nil {
+		apiClient.limiter = s.limiter
 	}
+	if gbisClient != nil {
+		gbisClient.limiter = s.limiter
+	}
+	if incheonClient != nil {
+		incheonClient.limiter = s.limiter
+	}
+	return s
+}
+
+// SetRateLimit caps the API requests of all clients together at perSecond
+// (<= 0 = unlimited). Requests over the cap wait for their turn.
+func (s *BusService) SetRateLimit(perSecond int) {
+	s.limiter.setRate(perSecond)
 }
 
 // SearchRoutes searches for routes in both Gyeonggi and Incheon
@@ -111,32 +139,83 @@ func (s *BusService) SearchStations(ctx context.Context, keyword string) ([]mode
 	return allStations, nil
 }
 
-// GetRouteStations returns stations for a route from the appropriate API
+// GetRouteStations returns stations for a route from the appropriate API.
+// Lists are cached per region and route for routeStationsTTL.
 func (s *BusService) GetRouteStations(ctx context.Context, routeID string, region string) ([]model.RouteStation, error) {
-	if region == "인천" || region == "incheon" {
-		return s.incheonClient.GetRouteStations(routeID)
+	key := routeStationsKey{region: ParseRegion(region), routeID: routeID}
+	if stations, ok := s.routeStations.get(key); ok {
+		return stations, nil
+	}
+
+	var stations []model.RouteStation
+	var err error
+	if key.region == RegionIncheon {
+		stations, err = s.incheonClient.GetRouteStations(routeID)
+	} else {
+		// Default to GBIS
+		stations, err = s.gbisClient.GetRouteStations(routeID)
+	}
+	if err != nil {
+		return nil, err
 	}
-	// Default to GBIS
-	return s.gbisClient.GetRouteStations(routeID)
+
+	s.routeStations.put(key, stations)
+	return stations, nil
+}
+
+// ClearRouteStationCache drops all cached route station lists
+func (s *BusService) ClearRouteStationCache() {
+	s.routeStations.clear()
 }
 
-// GetBusLocations returns bus locations for a route
+// GetBusLocations returns bus locations for a route.
+// The Incheon API has no location service, so Incheon routes fail with ErrUnsupportedRegion.
 func (s *BusService) GetBusLocations(ctx context.Context, routeID string, region string) ([]model.BusLocation, error) {
-	if region == "인천" || region == "incheon" {
-		// Incheon doesn't have a direct equivalent, return empty
-		return []model.BusLocation{}, nil
+	if isIncheon(region) {
+		return nil, fmt.Errorf("bus locations are not available for %s: %w", RegionIncheon, ErrUnsupportedRegion)
 	}
 	return s.gbisClient.GetBusLocations(routeID)
 }
 
 // GetBusArrivalsByStation returns arrivals for a station
 func (s *BusService) GetBusArrivalsByStation(ctx context.Context, stationID string, region string) ([]model.APIBusArrival, error) {
-	if region == "인천" || region == "incheon" {
+	if isIncheon(region) {
 		return s.incheonClient.GetBusArrivalsByStation(stationID)
 	}
 	return s.gbisClient.GetBusArrivalsByStation(stationID)
 }
 
+// GetRouteArrivals returns the approaching buses of one route at a station
+func (s *BusService) GetRouteArrivals(ctx context.Context, routeID, stationID string, region string) ([]model.BusArrivalInfo, error) {
+	if !isIncheon(region) {
+		return s.apiClient.GetRouteArrivalList(routeID, stationID)
+	}
+
+	// The Incheon API only lists arrivals per station, so keep this route's buses
+	stationArrivals, err := s.incheonClient.GetBusArrivalsByStation(stationID)
+	if err != nil {
+		return nil, err
+	}
+	var arrivals []model.BusArrivalInfo
+	for _, a := range stationArrivals {
+		if strconv.Itoa(a.RouteID) != routeID {
+			continue
+		}
+		arrivals = append(arrivals, model.BusArrivalInfo{
+			RouteID:       a.RouteID,
+			RouteName:     a.RouteName,
+			StationID:     a.StationID,
+			StationSeq:    a.StationSeq,
+			PlateNo:       a.PlateNo,
+			RemainSeatCnt: a.RemainSeatCnt,
+			PredictTime1:  a.PredictTime1,
+			LocationNo1:   a.LocationNo1,
+			LowPlate1:     a.LowPlate1,
+		})
+	}
+	return arrivals, nil
+}
+
 // StationRouteInfo represents a route passing through a station
 type StationRouteInfo struct {
 	RouteID       int    `json:"routeId"`
@@ -145,9 +224,149 @@ type StationRouteInfo struct {
 	Direction     string `json:"direction"` // 상행 or 하행
 }
 
+// StationRouteSeats is a route at a station with the seats on its next bus there.
+// The seat fields are nil when no bus of the route is approaching or the API
+// doesn't report seats for it.
+type StationRouteSeats struct {
+	StationRouteInfo
+	RemainSeatCnt *int `json:"remainSeatCnt"`
+	PredictTime   *int `json:"predictTime"` // Minutes until that bus arrives
+}
+
+// DirectionPreview explains which direction a config at a station would capture
+type DirectionPreview struct {
+	Direction    string `json:"direction"`    // 상행, 하행, 회차, or "" if the station is not on the route
+	StationSeq   int    `json:"stationSeq"`   // -1 if the station is not on the route
+	TurnSeq      int    `json:"turnSeq"`      // -1 if the route has no turn point
+	HasTurnPoint bool   `json:"hasTurnPoint"` // false means the direction is a one-way assumption
+}
+
+// detectDirection locates the station and the route's turn point in the station list.
+// Stations before the turn point are 상행, after it 하행. Routes without a turn point
+// are treated as one-way (상행).
+func detectDirection(stations []model.RouteStation, stationID int) DirectionPreview {
+	preview := DirectionPreview{StationSeq: -1, TurnSeq: turnSeq(stations)}
+
+	for _, st := range stations {
+		if st.StationID == stationID {
+			preview.StationSeq = st.StationSeq
+		}
+	}
+	preview.HasTurnPoint = preview.TurnSeq != -1
+
+	if preview.StationSeq == -1 {
+		return preview
+	}
+	preview.Direction = directionAtSeq(preview.StationSeq, preview.TurnSeq)
+	return preview
+}
+
+// turnSeq returns the station order of the route's turn point, -1 if it has none
+func turnSeq(stations []model.RouteStation) int {
+	turn := -1
+	for _, st := range stations {
+		if st.TurnYn == "Y" {
+			turn = st.StationSeq
+		}
+	}
+	return turn
+}
+
+// directionAtSeq applies the turn-point rule to one station order
+func directionAtSeq(seq, turn int) string {
+	switch {
+	case turn == -1:
+		return "상행"
+	case seq < turn:
+		return "상행"
+	case seq == turn:
+		return "회차"
+	default:
+		return "하행"
+	}
+}
+
+// StationDirections returns the direction (상행, 하행 or 회차) of every station on a route
+// in route order, using the same turn-point rule as PreviewDirection
+func (s *BusService) StationDirections(ctx context.Context, routeID, region string) ([]model.RouteStation, []string, error) {
+	stations, err := s.GetRouteStations(ctx, routeID, region)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	turn := turnSeq(stations)
+	directions := make([]string, len(stations))
+	for i, st := range stations {
+		directions[i] = directionAtSeq(st.StationSeq, turn)
+	}
+	return stations, directions, nil
+}
+
+// DirectionAt returns the direction of a route at a station order (staOrder from the
+// arrival APIs), which tells the two passes of a loop route at one station apart
+func (s *BusService) DirectionAt(ctx context.Context, routeID, region string, staOrder int) (string, error) {
+	stations, err := s.GetRouteStations(ctx, routeID, region)
+	if err != nil {
+		return "", err
+	}
+	for _, st := range stations {
+		if st.StationSeq == staOrder {
+			return directionAtSeq(staOrder, turnSeq(stations)), nil
+		}
+	}
+	return "", fmt.Errorf("route %s has no station at order %d", routeID, staOrder)
+}
+
+// FindRouteAtStation looks up a route's details among the routes serving a station.
+// Incheon only knows routes with a bus currently approaching. Returns nil if not found.
+func (s *BusService) FindRouteAtStation(ctx context.Context, routeID, stationID, region string) (*model.RouteInfo, error) {
+	if isIncheon(region) {
+		arrivals, err := s.incheonClient.GetBusArrivalsByStation(stationID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range arrivals {
+			if strconv.Itoa(a.RouteID) == routeID {
+				return &model.RouteInfo{RouteID: a.RouteID, RouteName: a.RouteName, RouteTypeName: a.RouteTypeName}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	routes, err := s.gbisClient.GetRoutesByStation(stationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range routes {
+		if strconv.Itoa(routes[i].RouteID) == routeID {
+			return &routes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// PreviewDirection computes the direction a config for this route/station would capture
+func (s *BusService) PreviewDirection(ctx context.Context, routeID, stationID, region string) (*DirectionPreview, error) {
+	currID, err := strconv.Atoi(stationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid station id %q: %w", stationID, err)
+	}
+
+	stations, err := s.GetRouteStations(ctx, routeID, region)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := detectDirection(stations, currID)
+	if preview.StationSeq == -1 {
+		return nil, fmt.Errorf("station %s is not on route %s", stationID, routeID)
+	}
+	return &preview, nil
+}
+
 // GetStationRoutes returns routes passing through a station with direction info
 func (s *BusService) GetStationRoutes(ctx context.Context, stationID string, region string) ([]StationRouteInfo, error) {
-	if region == "인천" || region == "incheon" {
+	if isIncheon(region) {
 		// Fallback for Incheon: use arrivals since we don't have a direct routes-by-station API yet
 		arrivals, err := s.incheonClient.GetBusArrivalsByStation(stationID)
 		if err != nil {
@@ -196,37 +415,10 @@ func (s *BusService) GetStationRoutes(ctx context.Context, stationID string, reg
 
 			direction := ""
 			// Get station list for this route to find direction
-			stations, err := s.gbisClient.GetRouteStations(fmt.Sprintf("%d", route.RouteID))
+			stations, err := s.GetRouteStations(ctx, fmt.Sprintf("%d", route.RouteID), string(RegionGyeonggi))
 			if err == nil {
 				currID, _ := strconv.Atoi(stationID)
-
-				// Find turn point and current station position
-				var turnSeq int = -1
-				var currSeq int = -1
-
-				for _, st := range stations {
-					if st.TurnYn == "Y" {
-						turnSeq = st.StationSeq
-					}
-					if st.StationID == currID {
-						currSeq = st.StationSeq
-					}
-				}
-
-				if currSeq != -1 {
-					if turnSeq != -1 {
-						if currSeq < turnSeq {
-							direction = "상행"
-						} else if currSeq == turnSeq {
-							direction = "회차"
-						} else {
-							direction = "하행"
-						}
-					} else {
-						// No turn point found, maybe it's a one-way?
-						direction = "상행"
-					}
-				}
+				direction = detectDirection(stations, currID).Direction
 			}
 
 			mu.Lock()
@@ -243,3 +435,39 @@ func (s *BusService) GetStationRoutes(ctx context.Context, stationID string, reg
 	wg.Wait()
 	return result, nil
 }
+
+// GetStationRoutesWithSeats returns the routes passing a station, each with the
+// remaining seats of its soonest approaching bus. If current arrivals can't be
+// fetched the routes are still returned, just without seat info.
+func (s *BusService) GetStationRoutesWithSeats(ctx context.Context, stationID string, region string) ([]StationRouteSeats, error) {
+	routes, err := s.GetStationRoutes(ctx, stationID, region)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivals, err := s.GetBusArrivalsByStation(ctx, stationID, region)
+	if err != nil {
+		log.Printf("[BusService] Failed to get arrivals at station %s for seat info: %v", stationID, err)
+	}
+	next := make(map[int]model.APIBusArrival)
+	for _, a := range arrivals {
+		if cur, ok := next[a.RouteID]; !ok || a.PredictTime1 < cur.PredictTime1 {
+			next[a.RouteID] = a
+		}
+	}
+
+	result := make([]StationRouteSeats, 0, len(routes))
+	for _, r := range routes {
+		entry := StationRouteSeats{StationRouteInfo: r}
+		if a, ok := next[r.RouteID]; ok {
+			predict := a.PredictTime1
+			entry.PredictTime = &predict
+			if a.RemainSeatCnt >= 0 {
+				seats := a.RemainSeatCnt
+				entry.RemainSeatCnt = &seats
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGetBusLocationsUnsupportedRegion(t *testing.T) {
+	s := NewBusService(nil, nil, NewIncheonClient("", ""))
+	for _, region := range []string{string(RegionIncheon), "incheon"} {
+		t.Run(region, func(t *testing.T) {
+			locations, err := s.GetBusLocations(context.Background(), "165000012", region)
+			if !errors.Is(err, ErrUnsupportedRegion) {
+				t.Fatalf("GetBusLocations error = %v, want ErrUnsupportedRegion", err)
+			}
+			if locations != nil {
+				t.Errorf("GetBusLocations returned %d locations", len(locations))
+			}
+		})
+	}
+}
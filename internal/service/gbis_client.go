@@ -8,25 +8,56 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
+// DefaultGBISBaseURL is the production root of the Gyeonggi (GBIS) services
+const DefaultGBISBaseURL = "https://apis.data.go.kr/6410000"
+
+// GBISArrivalServicePath is the arrival service under a GBIS root, the base of OpenAPIClient
+const GBISArrivalServicePath = "/busarrivalservice/v2"
+
+// arrivalListEndpoint lists the arrivals at a station, relative to GBISArrivalServicePath
+const arrivalListEndpoint = "/getBusArrivalListv2"
+
 // GBISClient handles communication with the GBIS API for all bus services
 type GBISClient struct {
+	baseURL    string
 	serviceKey string
 	client     *http.Client
+	maxBody    int64
+	limiter    *rateLimiter // Set by NewBusService
 }
 
-// NewGBISClient creates a new GBIS API client
-func NewGBISClient(serviceKey string) *GBISClient {
+// NewGBISClient creates a new GBIS API client. An empty baseURL uses DefaultGBISBaseURL.
+func NewGBISClient(baseURL, serviceKey string) *GBISClient {
+	if baseURL == "" {
+		baseURL = DefaultGBISBaseURL
+	}
 	return &GBISClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
 		serviceKey: serviceKey,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxBody: DefaultMaxResponseBytes,
 	}
 }
 
+// SetMaxResponseBytes overrides the response size cap (<= 0 restores the default)
+func (c *GBISClient) SetMaxResponseBytes(n int64) {
+	if n <= 0 {
+		n = DefaultMaxResponseBytes
+	}
+	c.maxBody = n
+}
+
+// SetAdaptiveTimeout switches between latency-based request timeouts and the fixed default
+func (c *GBISClient) SetAdaptiveTimeout(enabled bool) {
+	c.client = newHTTPClient(enabled)
+}
+
 // ============================================================================
 // Helper Methods
 // ============================================================================
@@ -43,8 +74,9 @@ func (c *GBISClient) makeRequest(endpoint string, params url.Values) ([]byte, er
 	req.URL.RawQuery = params.Encode()
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
-	log.Printf("Requesting URL: %s", req.URL.String())
+	log.Printf("Requesting URL: %s", redactedURL(req.URL))
 
+	c.limiter.wait()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
@@ -52,14 +84,14 @@ func (c *GBISClient) makeRequest(endpoint string, params url.Values) ([]byte, er
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 		log.Printf("API returned non-200 status: %d, Body: %s", resp.StatusCode, string(bodyBytes))
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readLimitedBody(resp.Body, c.maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	return body, nil
@@ -71,7 +103,7 @@ func (c *GBISClient) makeRequest(endpoint string, params url.Values) ([]byte, er
 
 // SearchRoutes searches for bus routes by keyword
 func (c *GBISClient) SearchRoutes(keyword string) ([]model.RouteInfo, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busrouteservice/v2/getBusRouteListv2"
+	endpoint := c.baseURL + "/busrouteservice/v2/getBusRouteListv2"
 	params := url.Values{}
 	params.Add("keyword", keyword)
 
@@ -102,13 +134,9 @@ func (c *GBISClient) SearchRoutes(keyword string) ([]model.RouteInfo, error) {
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	var routes []model.RouteInfo
-	if err := json.Unmarshal(jsonResp.Response.MsgBody.BusRouteList, &routes); err != nil {
-		var singleRoute model.RouteInfo
-		if err := json.Unmarshal(jsonResp.Response.MsgBody.BusRouteList, &singleRoute); err != nil {
-			return []model.RouteInfo{}, nil
-		}
-		routes = []model.RouteInfo{singleRoute}
+	routes, err := unmarshalArrayOrSingle[model.RouteInfo](jsonResp.Response.MsgBody.BusRouteList)
+	if err != nil {
+		return nil, err
 	}
 
 	return routes, nil
@@ -116,7 +144,7 @@ func (c *GBISClient) SearchRoutes(keyword string) ([]model.RouteInfo, error) {
 
 // GetRouteStations gets all stations on a route
 func (c *GBISClient) GetRouteStations(routeID string) ([]model.RouteStation, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busrouteservice/v2/getBusRouteStationListv2"
+	endpoint := c.baseURL + "/busrouteservice/v2/getBusRouteStationListv2"
 	params := url.Values{}
 	params.Add("routeId", routeID)
 
@@ -132,7 +160,7 @@ func (c *GBISClient) GetRouteStations(routeID string) ([]model.RouteStation, err
 				ResultMsg  string `json:"resultMessage"`
 			} `json:"msgHeader"`
 			MsgBody struct {
-				BusRouteStationList []model.RouteStation `json:"busRouteStationList"`
+				BusRouteStationList json.RawMessage `json:"busRouteStationList"`
 			} `json:"msgBody"`
 		} `json:"response"`
 	}
@@ -147,7 +175,7 @@ func (c *GBISClient) GetRouteStations(routeID string) ([]model.RouteStation, err
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	return jsonResp.Response.MsgBody.BusRouteStationList, nil
+	return unmarshalArrayOrSingle[model.RouteStation](jsonResp.Response.MsgBody.BusRouteStationList)
 }
 
 // ============================================================================
@@ -156,7 +184,7 @@ func (c *GBISClient) GetRouteStations(routeID string) ([]model.RouteStation, err
 
 // SearchStations searches for bus stations by keyword
 func (c *GBISClient) SearchStations(keyword string) ([]model.StationInfo, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busstationservice/v2/getBusStationListv2"
+	endpoint := c.baseURL + "/busstationservice/v2/getBusStationListv2"
 	params := url.Values{}
 	params.Add("keyword", keyword)
 
@@ -187,13 +215,9 @@ func (c *GBISClient) SearchStations(keyword string) ([]model.StationInfo, error)
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	var stations []model.StationInfo
-	if err := json.Unmarshal(jsonResp.Response.MsgBody.BusStationList, &stations); err != nil {
-		var singleStation model.StationInfo
-		if err := json.Unmarshal(jsonResp.Response.MsgBody.BusStationList, &singleStation); err != nil {
-			return []model.StationInfo{}, nil
-		}
-		stations = []model.StationInfo{singleStation}
+	stations, err := unmarshalArrayOrSingle[model.StationInfo](jsonResp.Response.MsgBody.BusStationList)
+	if err != nil {
+		return nil, err
 	}
 
 	return stations, nil
@@ -205,7 +229,7 @@ func (c *GBISClient) SearchStations(keyword string) ([]model.StationInfo, error)
 
 // GetBusLocations gets current bus locations on a route
 func (c *GBISClient) GetBusLocations(routeID string) ([]model.BusLocation, error) {
-	endpoint := "https://apis.data.go.kr/6410000/buslocationservice/v2/getBusLocationListv2"
+	endpoint := c.baseURL + "/buslocationservice/v2/getBusLocationListv2"
 	params := url.Values{}
 	params.Add("routeId", routeID)
 
@@ -221,7 +245,7 @@ func (c *GBISClient) GetBusLocations(routeID string) ([]model.BusLocation, error
 				ResultMsg  string `json:"resultMessage"`
 			} `json:"msgHeader"`
 			MsgBody struct {
-				BusLocationList []model.BusLocation `json:"busLocationList"`
+				BusLocationList json.RawMessage `json:"busLocationList"`
 			} `json:"msgBody"`
 		} `json:"response"`
 	}
@@ -236,7 +260,16 @@ func (c *GBISClient) GetBusLocations(routeID string) ([]model.BusLocation, error
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	return jsonResp.Response.MsgBody.BusLocationList, nil
+	items, err := unmarshalArrayOrSingle[busLocationItem](jsonResp.Response.MsgBody.BusLocationList)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]model.BusLocation, len(items))
+	for i, item := range items {
+		locations[i] = item.location()
+	}
+	return locations, nil
 }
 
 // ============================================================================
@@ -244,7 +277,7 @@ func (c *GBISClient) GetBusLocations(routeID string) ([]model.BusLocation, error
 // ============================================================================
 
 func (c *GBISClient) GetBusArrivalsByStation(stationID string) ([]model.APIBusArrival, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busarrivalservice/v2/getBusArrivalListv2"
+	endpoint := c.baseURL + GBISArrivalServicePath + arrivalListEndpoint
 	params := url.Values{}
 	params.Add("stationId", stationID)
 
@@ -275,21 +308,21 @@ func (c *GBISClient) GetBusArrivalsByStation(stationID string) ([]model.APIBusAr
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	var arrivals []model.APIBusArrival
-	if err := json.Unmarshal(jsonResp.Response.MsgBody.BusArrivalList, &arrivals); err != nil {
-		var singleArrival model.APIBusArrival
-		if err := json.Unmarshal(jsonResp.Response.MsgBody.BusArrivalList, &singleArrival); err != nil {
-			return []model.APIBusArrival{}, nil
-		}
-		arrivals = []model.APIBusArrival{singleArrival}
+	items, err := unmarshalArrayOrSingle[stationArrivalItem](jsonResp.Response.MsgBody.BusArrivalList)
+	if err != nil {
+		return nil, err
 	}
 
+	arrivals := make([]model.APIBusArrival, len(items))
+	for i, item := range items {
+		arrivals[i] = item.apiArrival()
+	}
 	return arrivals, nil
 }
 
 // GetRoutesByStation gets all bus routes passing through a station
 func (c *GBISClient) GetRoutesByStation(stationID string) ([]model.RouteInfo, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busstationservice/v2/getBusStationViaRouteListv2"
+	endpoint := c.baseURL + "/busstationservice/v2/getBusStationViaRouteListv2"
 	params := url.Values{}
 	params.Add("stationId", stationID)
 
@@ -323,13 +356,9 @@ func (c *GBISClient) GetRoutesByStation(stationID string) ([]model.RouteInfo, er
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	var routes []model.RouteInfo
-	if err := json.Unmarshal(jsonResp.Response.MsgBody.BusRouteList, &routes); err != nil {
-		var singleRoute model.RouteInfo
-		if err := json.Unmarshal(jsonResp.Response.MsgBody.BusRouteList, &singleRoute); err != nil {
-			return []model.RouteInfo{}, nil
-		}
-		routes = []model.RouteInfo{singleRoute}
+	routes, err := unmarshalArrayOrSingle[model.RouteInfo](jsonResp.Response.MsgBody.BusRouteList)
+	if err != nil {
+		return nil, err
 	}
 
 	return routes, nil
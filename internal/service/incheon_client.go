@@ -8,23 +8,66 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
+// DefaultIncheonBaseURL is the production root of the Incheon bus services
+const DefaultIncheonBaseURL = "https://apis.data.go.kr/6280000"
+
+// Units of ARRIVALESTIMATETIME for SetArrivalTimeUnit
+const (
+	ArrivalUnitSeconds = "seconds" // As documented, and the default
+	ArrivalUnitMinutes = "minutes" // As some endpoints have returned
+)
+
 // IncheonClient handles communication with the Incheon Bus API
 type IncheonClient struct {
-	serviceKey string
-	client     *http.Client
+	baseURL     string
+	serviceKey  string
+	client      *http.Client
+	maxBody     int64
+	arrivalUnit string
+	limiter     *rateLimiter // Set by NewBusService
 }
 
-// NewIncheonClient creates a new Incheon Bus API client
-func NewIncheonClient(serviceKey string) *IncheonClient {
+// NewIncheonClient creates a new Incheon Bus API client. An empty baseURL uses DefaultIncheonBaseURL.
+func NewIncheonClient(baseURL, serviceKey string) *IncheonClient {
+	if baseURL == "" {
+		baseURL = DefaultIncheonBaseURL
+	}
 	return &IncheonClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
 		serviceKey: serviceKey,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxBody:     DefaultMaxResponseBytes,
+		arrivalUnit: ArrivalUnitSeconds,
+	}
+}
+
+// SetMaxResponseBytes overrides the response size cap (<= 0 restores the default)
+func (c *IncheonClient) SetMaxResponseBytes(n int64) {
+	if n <= 0 {
+		n = DefaultMaxResponseBytes
+	}
+	c.maxBody = n
+}
+
+// SetAdaptiveTimeout switches between latency-based request timeouts and the fixed default
+func (c *IncheonClient) SetAdaptiveTimeout(enabled bool) {
+	c.client = newHTTPClient(enabled)
+}
+
+// SetArrivalTimeUnit sets the unit of arrival estimates. Only ArrivalUnitMinutes
+// changes anything; any other value keeps the documented seconds.
+func (c *IncheonClient) SetArrivalTimeUnit(unit string) {
+	if unit == ArrivalUnitMinutes {
+		c.arrivalUnit = ArrivalUnitMinutes
+		return
 	}
+	c.arrivalUnit = ArrivalUnitSeconds
 }
 
 // ============================================================================
@@ -45,8 +88,9 @@ func (c *IncheonClient) makeRequest(endpoint string, params url.Values) ([]byte,
 	req.URL.RawQuery = params.Encode()
 	req.Header.Set("User-Agent", "Mozilla/5.0")
 
-	log.Printf("[Incheon] Requesting URL: %s", req.URL.String())
+	log.Printf("[Incheon] Requesting URL: %s", redactedURL(req.URL))
 
+	c.limiter.wait()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
@@ -54,14 +98,14 @@ func (c *IncheonClient) makeRequest(endpoint string, params url.Values) ([]byte,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 		log.Printf("[Incheon] API returned non-200 status: %d, Body: %s", resp.StatusCode, string(bodyBytes))
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readLimitedBody(resp.Body, c.maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	return body, nil
@@ -82,7 +126,7 @@ type IncheonRouteInfo struct {
 
 // SearchRoutes searches for bus routes by keyword
 func (c *IncheonClient) SearchRoutes(keyword string) ([]model.RouteInfo, error) {
-	endpoint := "https://apis.data.go.kr/6280000/busRouteInfo/getRouteNoList"
+	endpoint := c.baseURL + "/busRouteInfo/getRouteNoList"
 	params := url.Values{}
 	params.Add("routeNo", keyword)
 
@@ -117,14 +161,9 @@ func (c *IncheonClient) SearchRoutes(keyword string) ([]model.RouteInfo, error)
 	}
 
 	// Handle both array and single object cases
-	var incheonRoutes []IncheonRouteInfo
-	if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &incheonRoutes); err != nil {
-		var singleRoute IncheonRouteInfo
-		if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &singleRoute); err != nil {
-			// No results
-			return []model.RouteInfo{}, nil
-		}
-		incheonRoutes = []IncheonRouteInfo{singleRoute}
+	incheonRoutes, err := unmarshalArrayOrSingle[IncheonRouteInfo](jsonResp.Response.Body.Items.Item)
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to common RouteInfo format
@@ -178,7 +217,7 @@ type IncheonStationInfo struct {
 
 // SearchStations searches for bus stations by keyword
 func (c *IncheonClient) SearchStations(keyword string) ([]model.StationInfo, error) {
-	endpoint := "https://apis.data.go.kr/6280000/busStationInfo/getBstopInfoList"
+	endpoint := c.baseURL + "/busStationInfo/getBstopInfoList"
 	params := url.Values{}
 	params.Add("bstopNm", keyword)
 
@@ -211,13 +250,9 @@ func (c *IncheonClient) SearchStations(keyword string) ([]model.StationInfo, err
 			jsonResp.Response.Header.ResultMsg)
 	}
 
-	var incheonStations []IncheonStationInfo
-	if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &incheonStations); err != nil {
-		var singleStation IncheonStationInfo
-		if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &singleStation); err != nil {
-			return []model.StationInfo{}, nil
-		}
-		incheonStations = []IncheonStationInfo{singleStation}
+	incheonStations, err := unmarshalArrayOrSingle[IncheonStationInfo](jsonResp.Response.Body.Items.Item)
+	if err != nil {
+		return nil, err
 	}
 
 	stations := make([]model.StationInfo, len(incheonStations))
@@ -239,14 +274,16 @@ func (c *IncheonClient) SearchStations(keyword string) ([]model.StationInfo, err
 
 // IncheonRouteStation represents a station on a route from Incheon API
 type IncheonRouteStation struct {
-	StationID   string `json:"BSTOPID"`
-	StationName string `json:"BSTOPNM"`
-	StationSeq  int    `json:"BSTOPSEQ"`
+	StationID   string  `json:"BSTOPID"`
+	StationName string  `json:"BSTOPNM"`
+	StationSeq  int     `json:"BSTOPSEQ"`
+	PosX        float64 `json:"POSX"`
+	PosY        float64 `json:"POSY"`
 }
 
 // GetRouteStations gets all stations on a route
 func (c *IncheonClient) GetRouteStations(routeID string) ([]model.RouteStation, error) {
-	endpoint := "https://apis.data.go.kr/6280000/busRouteInfo/getRouteBstopList"
+	endpoint := c.baseURL + "/busRouteInfo/getRouteBstopList"
 	params := url.Values{}
 	params.Add("routeId", routeID)
 
@@ -279,13 +316,9 @@ func (c *IncheonClient) GetRouteStations(routeID string) ([]model.RouteStation,
 			jsonResp.Response.Header.ResultMsg)
 	}
 
-	var incheonStations []IncheonRouteStation
-	if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &incheonStations); err != nil {
-		var singleStation IncheonRouteStation
-		if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &singleStation); err != nil {
-			return []model.RouteStation{}, nil
-		}
-		incheonStations = []IncheonRouteStation{singleStation}
+	incheonStations, err := unmarshalArrayOrSingle[IncheonRouteStation](jsonResp.Response.Body.Items.Item)
+	if err != nil {
+		return nil, err
 	}
 
 	stations := make([]model.RouteStation, len(incheonStations))
@@ -296,6 +329,8 @@ func (c *IncheonClient) GetRouteStations(routeID string) ([]model.RouteStation,
 			StationID:   stationID,
 			StationName: s.StationName,
 			StationSeq:  s.StationSeq,
+			X:           s.PosX,
+			Y:           s.PosY,
 			RegionName:  "인천",
 		}
 	}
@@ -307,14 +342,14 @@ func (c *IncheonClient) GetRouteStations(routeID string) ([]model.RouteStation,
 type IncheonArrival struct {
 	RouteID       string `json:"ROUTEID"`
 	RouteName     string `json:"ROUTENO"`
-	ArrivalTime   int    `json:"ARRIVALESTIMATETIME"` // seconds
+	ArrivalTime   int    `json:"ARRIVALESTIMATETIME"` // seconds unless configured as minutes
 	RestStopCount int    `json:"REST_STOP_COUNT"`
 	PlateNo       string `json:"BUS_NUM_PLATE"`
 	RemainSeatCnt int    `json:"REMAINSEATCNT"`
 }
 
 func (c *IncheonClient) GetBusArrivalList(stationID string) ([]model.APIBusArrival, error) {
-	endpoint := "https://apis.data.go.kr/6280000/busArrInfo/getStaionArrInfo"
+	endpoint := c.baseURL + "/busArrInfo/getStaionArrInfo"
 	params := url.Values{}
 	params.Add("bstopId", stationID)
 
@@ -347,15 +382,13 @@ func (c *IncheonClient) GetBusArrivalList(stationID string) ([]model.APIBusArriv
 			jsonResp.Response.Header.ResultMsg)
 	}
 
-	var incheonArrivals []IncheonArrival
-	if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &incheonArrivals); err != nil {
-		var singleArrival IncheonArrival
-		if err := json.Unmarshal(jsonResp.Response.Body.Items.Item, &singleArrival); err != nil {
-			return []model.APIBusArrival{}, nil
-		}
-		incheonArrivals = []IncheonArrival{singleArrival}
+	incheonArrivals, err := unmarshalArrayOrSingle[IncheonArrival](jsonResp.Response.Body.Items.Item)
+	if err != nil {
+		return nil, err
 	}
 
+	inMinutes := c.arrivalUnit == ArrivalUnitMinutes
+
 	arrivals := make([]model.APIBusArrival, len(incheonArrivals))
 	for i, a := range incheonArrivals {
 		routeID := 0
@@ -366,7 +399,7 @@ func (c *IncheonClient) GetBusArrivalList(stationID string) ([]model.APIBusArriv
 		arrivals[i] = model.APIBusArrival{
 			RouteID:       routeID,
 			StationID:     stID,
-			PredictTime1:  a.ArrivalTime / 60, // Convert seconds to minutes
+			PredictTime1:  predictMinutes(a.ArrivalTime, inMinutes),
 			LocationNo1:   a.RestStopCount,
 			PlateNo:       a.PlateNo,
 			RemainSeatCnt: a.RemainSeatCnt,
@@ -376,6 +409,13 @@ func (c *IncheonClient) GetBusArrivalList(stationID string) ([]model.APIBusArriv
 	return arrivals, nil
 }
 
+func predictMinutes(arrivalTime int, inMinutes bool) int {
+	if inMinutes {
+		return arrivalTime
+	}
+	return arrivalTime / 60 // Convert seconds to minutes
+}
+
 // GetBusArrivalsByStation is an alias for GetBusArrivalList to match interface
 func (c *IncheonClient) GetBusArrivalsByStation(stationID string) ([]model.APIBusArrival, error) {
 	return c.GetBusArrivalList(stationID)
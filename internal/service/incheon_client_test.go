@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetBusArrivalListUnits(t *testing.T) {
+	const body = `{"response":{"header":{"resultCode":"00","resultMsg":"OK"},"body":{"items":{"item":[
+		{"ROUTEID":"165000012","ARRIVALESTIMATETIME":45,"REST_STOP_COUNT":1,"BUS_NUM_PLATE":"인천70바1234"},
+		{"ROUTEID":"165000012","ARRIVALESTIMATETIME":600,"REST_STOP_COUNT":5,"BUS_NUM_PLATE":"인천70바5678"}]}}}}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		unit string
+		want []int
+	}{
+		{"default is seconds", "", []int{0, 10}},
+		{"explicit seconds", ArrivalUnitSeconds, []int{0, 10}},
+		{"explicit minutes", ArrivalUnitMinutes, []int{45, 600}},
+		{"unknown unit reads seconds", "auto", []int{0, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewIncheonClient(srv.URL, "key")
+			c.SetArrivalTimeUnit(tt.unit)
+
+			arrivals, err := c.GetBusArrivalList("89000001")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(arrivals) != len(tt.want) {
+				t.Fatalf("got %d arrivals, want %d", len(arrivals), len(tt.want))
+			}
+			for i, a := range arrivals {
+				if a.PredictTime1 != tt.want[i] {
+					t.Errorf("arrival %d: PredictTime1 = %d, want %d", i, a.PredictTime1, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bus_history/internal/model"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// LiveRoute identifies a route whose bus positions should be kept fresh
+type LiveRoute struct {
+	RouteID string
+	Region  string
+}
+
+type liveLocationEntry struct {
+	locations []model.BusLocation
+	fetchedAt time.Time
+}
+
+// LiveLocationCache keeps the latest bus positions per route for the live map.
+// Entries older than the TTL are refetched on demand.
+type LiveLocationCache struct {
+	busService *BusService
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[LiveRoute]liveLocationEntry
+}
+
+// NewLiveLocationCache creates a new live location cache
+func NewLiveLocationCache(busService *BusService, ttl time.Duration) *LiveLocationCache {
+	return &LiveLocationCache{
+		busService: busService,
+		ttl:        ttl,
+		entries:    make(map[LiveRoute]liveLocationEntry),
+	}
+}
+
+// Get returns the cached positions for a route, refreshing them if stale
+func (c *LiveLocationCache) Get(ctx context.Context, routeID, region string) ([]model.BusLocation, error) {
+	key := LiveRoute{RouteID: routeID, Region: region}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && time.Since(entry.fetchedAt) < c.ttl {
+		return entry.locations, nil
+	}
+
+	return c.refresh(ctx, key)
+}
+
+// Poll refreshes the positions of the given routes every interval until ctx is done
+func (c *LiveLocationCache) Poll(ctx context.Context, interval time.Duration, routes func() []LiveRoute) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, route := range routes() {
+				// Routes in regions without a location API only fail when asked for directly
+				if _, err := c.refresh(ctx, route); err != nil && !errors.Is(err, ErrUnsupportedRegion) {
+					log.Printf("[LiveLocations] Error refreshing route %s: %v", route.RouteID, err)
+				}
+			}
+		}
+	}
+}
+
+func (c *LiveLocationCache) refresh(ctx context.Context, key LiveRoute) ([]model.BusLocation, error) {
+	locations, err := c.busService.GetBusLocations(ctx, key.RouteID, key.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	// The location API occasionally lists the same vehicle twice; keep the latest entry per plate
+	index := make(map[string]int)
+	deduped := make([]model.BusLocation, 0, len(locations))
+	for _, loc := range locations {
+		plate := model.NormalizePlate(loc.PlateNo)
+		if i, seen := index[plate]; seen {
+			deduped[i] = loc
+			continue
+		}
+		index[plate] = len(deduped)
+		deduped = append(deduped, loc)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = liveLocationEntry{locations: deduped, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return deduped, nil
+}
@@ -3,10 +3,11 @@ package service
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"bus_history/internal/model"
@@ -17,22 +18,38 @@ type OpenAPIClient struct {
 	baseURL    string
 	serviceKey string
 	client     *http.Client
+	maxBody    int64
+	limiter    *rateLimiter // Set by NewBusService
 }
 
 // NewOpenAPIClient creates a new API client
 func NewOpenAPIClient(baseURL, serviceKey string) *OpenAPIClient {
 	return &OpenAPIClient{
-		baseURL:    baseURL,
+		baseURL:    strings.TrimRight(baseURL, "/"),
 		serviceKey: serviceKey,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxBody: DefaultMaxResponseBytes,
 	}
 }
 
+// SetMaxResponseBytes overrides the response size cap (<= 0 restores the default)
+func (c *OpenAPIClient) SetMaxResponseBytes(n int64) {
+	if n <= 0 {
+		n = DefaultMaxResponseBytes
+	}
+	c.maxBody = n
+}
+
+// SetAdaptiveTimeout switches between latency-based request timeouts and the fixed default
+func (c *OpenAPIClient) SetAdaptiveTimeout(enabled bool) {
+	c.client = newHTTPClient(enabled)
+}
+
 // GetBusArrivalList retrieves bus arrival information for a station
 func (c *OpenAPIClient) GetBusArrivalList(stationID string) ([]model.BusArrivalInfo, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busarrivalservice/v2/getBusArrivalListv2"
+	endpoint := c.baseURL + arrivalListEndpoint
 
 	params := url.Values{}
 	params.Add("serviceKey", c.serviceKey)
@@ -47,8 +64,9 @@ func (c *OpenAPIClient) GetBusArrivalList(stationID string) ([]model.BusArrivalI
 	req.URL.RawQuery = params.Encode()
 	req.Header.Set("User-Agent", "Mozilla/5.0")
 
-	log.Printf("[OpenAPI] Requesting: %s", req.URL.String())
+	log.Printf("[OpenAPI] Requesting: %s", redactedURL(req.URL))
 
+	c.limiter.wait()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
@@ -59,9 +77,9 @@ func (c *OpenAPIClient) GetBusArrivalList(stationID string) ([]model.BusArrivalI
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readLimitedBody(resp.Body, c.maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	var jsonResp struct {
@@ -86,21 +104,21 @@ func (c *OpenAPIClient) GetBusArrivalList(stationID string) ([]model.BusArrivalI
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
-	var arrivals []model.BusArrivalInfo
-	if err := json.Unmarshal(jsonResp.Response.MsgBody.BusArrivalList, &arrivals); err != nil {
-		var singleArrival model.BusArrivalInfo
-		if err := json.Unmarshal(jsonResp.Response.MsgBody.BusArrivalList, &singleArrival); err != nil {
-			return []model.BusArrivalInfo{}, nil
-		}
-		arrivals = []model.BusArrivalInfo{singleArrival}
+	items, err := unmarshalArrayOrSingle[stationArrivalItem](jsonResp.Response.MsgBody.BusArrivalList)
+	if err != nil {
+		return nil, err
 	}
 
+	arrivals := make([]model.BusArrivalInfo, len(items))
+	for i, item := range items {
+		arrivals[i] = item.arrivalInfo()
+	}
 	return arrivals, nil
 }
 
 // GetRouteArrivalList retrieves bus arrival information for a specific route at a station
 func (c *OpenAPIClient) GetRouteArrivalList(routeID, stationID string) ([]model.BusArrivalInfo, error) {
-	endpoint := "https://apis.data.go.kr/6410000/busarrivalservice/v2/getBusArrivalItemv2"
+	endpoint := c.baseURL + "/getBusArrivalItemv2"
 
 	params := url.Values{}
 	params.Add("serviceKey", c.serviceKey)
@@ -116,8 +134,9 @@ func (c *OpenAPIClient) GetRouteArrivalList(routeID, stationID string) ([]model.
 	req.URL.RawQuery = params.Encode()
 	req.Header.Set("User-Agent", "Mozilla/5.0")
 
-	log.Printf("[OpenAPI] Requesting: %s", req.URL.String())
+	log.Printf("[OpenAPI] Requesting: %s", redactedURL(req.URL))
 
+	c.limiter.wait()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
@@ -128,11 +147,17 @@ func (c *OpenAPIClient) GetRouteArrivalList(routeID, stationID string) ([]model.
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readLimitedBody(resp.Body, c.maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
+	return parseRouteArrivals(body, routeID, stationID)
+}
+
+// parseRouteArrivals decodes a route arrival response into one BusArrivalInfo per
+// approaching bus of routeID
+func parseRouteArrivals(body []byte, routeID, stationID string) ([]model.BusArrivalInfo, error) {
 	var jsonResp struct {
 		Response struct {
 			MsgHeader struct {
@@ -140,20 +165,8 @@ func (c *OpenAPIClient) GetRouteArrivalList(routeID, stationID string) ([]model.
 				ResultMsg  string `json:"resultMessage"`
 			} `json:"msgHeader"`
 			MsgBody struct {
-				BusArrivalItem struct {
-					PlateNo1       string `json:"plateNo1"`
-					PlateNo2       string `json:"plateNo2"`
-					PredictTime1   int    `json:"predictTime1"`
-					PredictTime2   int    `json:"predictTime2"`
-					LocationNo1    int    `json:"locationNo1"`
-					LocationNo2    int    `json:"locationNo2"`
-					RemainSeatCnt1 int    `json:"remainSeatCnt1"`
-					RemainSeatCnt2 int    `json:"remainSeatCnt2"`
-					LowPlate1      int    `json:"lowPlate1"`
-					LowPlate2      int    `json:"lowPlate2"`
-					RouteID        int    `json:"routeId"`
-					StationID      int    `json:"stationId"`
-				} `json:"busArrivalItem"`
+				BusArrivalItem json.RawMessage `json:"busArrivalItem"`
+				BusArrivalList json.RawMessage `json:"busArrivalList"`
 			} `json:"msgBody"`
 		} `json:"response"`
 	}
@@ -168,34 +181,92 @@ func (c *OpenAPIClient) GetRouteArrivalList(routeID, stationID string) ([]model.
 			jsonResp.Response.MsgHeader.ResultMsg)
 	}
 
+	// The item endpoint normally answers with a single busArrivalItem, but some
+	// route types come back in the busArrivalList form instead
+	msgBody := jsonResp.Response.MsgBody
+	items, err := unmarshalArrayOrSingle[routeArrivalItem](msgBody.BusArrivalItem)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		if items, err = unmarshalArrayOrSingle[routeArrivalItem](msgBody.BusArrivalList); err != nil {
+			return nil, err
+		}
+		if len(items) > 0 {
+			log.Printf("[OpenAPI] Route %s at station %s answered in the busArrivalList form (%d items)",
+				routeID, stationID, len(items))
+		}
+	}
+
+	var arrivals []model.BusArrivalInfo
+	for _, item := range items {
+		// The list form can include other routes serving the station
+		if item.RouteID != 0 && strconv.Itoa(int(item.RouteID)) != routeID {
+			continue
+		}
+		arrivals = append(arrivals, item.arrivals()...)
+	}
+
+	return arrivals, nil
+}
+
+// routeArrivalItem is one entry of the route arrival response, carrying the
+// next two buses of a route
+type routeArrivalItem struct {
+	PlateNo1       string     `json:"plateNo1"`
+	PlateNo2       string     `json:"plateNo2"`
+	PredictTime1   flexInt    `json:"predictTime1"`
+	PredictTime2   flexInt    `json:"predictTime2"`
+	LocationNo1    flexInt    `json:"locationNo1"`
+	LocationNo2    flexInt    `json:"locationNo2"`
+	RemainSeatCnt1 flexInt    `json:"remainSeatCnt1"`
+	RemainSeatCnt2 flexInt    `json:"remainSeatCnt2"`
+	LowPlate1      flexInt    `json:"lowPlate1"`
+	LowPlate2      flexInt    `json:"lowPlate2"`
+	RouteID        flexInt    `json:"routeId"`
+	RouteName      flexString `json:"routeName"`
+	StationID      flexInt    `json:"stationId"`
+	StaOrder       flexInt    `json:"staOrder"`
+}
+
+// arrivals splits the item into one BusArrivalInfo per approaching bus. Each
+// plate is tracked on its own, so a blank slot or a second slot repeating the
+// first bus must not turn into a separate entry.
+func (item routeArrivalItem) arrivals() []model.BusArrivalInfo {
 	var arrivals []model.BusArrivalInfo
-	item := jsonResp.Response.MsgBody.BusArrivalItem
 
-	if item.PlateNo1 != "" {
+	plate1 := model.NormalizePlate(item.PlateNo1)
+	plate2 := model.NormalizePlate(item.PlateNo2)
+
+	if plate1 != "" {
 		arrivals = append(arrivals, model.BusArrivalInfo{
-			RouteID:       item.RouteID,
-			StationID:     item.StationID,
+			RouteID:       int(item.RouteID),
+			RouteName:     string(item.RouteName),
+			StationID:     int(item.StationID),
+			StationSeq:    int(item.StaOrder),
 			PlateNo:       item.PlateNo1,
-			PredictTime1:  item.PredictTime1,
-			LocationNo1:   item.LocationNo1,
-			RemainSeatCnt: item.RemainSeatCnt1,
-			LowPlate1:     item.LowPlate1,
+			PredictTime1:  int(item.PredictTime1),
+			LocationNo1:   int(item.LocationNo1),
+			RemainSeatCnt: int(item.RemainSeatCnt1),
+			LowPlate1:     int(item.LowPlate1),
 		})
 	}
 
-	if item.PlateNo2 != "" {
+	if plate2 != "" && plate2 != plate1 {
 		arrivals = append(arrivals, model.BusArrivalInfo{
-			RouteID:       item.RouteID,
-			StationID:     item.StationID,
+			RouteID:       int(item.RouteID),
+			RouteName:     string(item.RouteName),
+			StationID:     int(item.StationID),
+			StationSeq:    int(item.StaOrder),
 			PlateNo:       item.PlateNo2,
-			PredictTime1:  item.PredictTime2,
-			LocationNo1:   item.LocationNo2,
-			RemainSeatCnt: item.RemainSeatCnt2,
-			LowPlate1:     item.LowPlate2,
+			PredictTime1:  int(item.PredictTime2),
+			LocationNo1:   int(item.LocationNo2),
+			RemainSeatCnt: int(item.RemainSeatCnt2),
+			LowPlate1:     int(item.LowPlate2),
 		})
 	}
 
-	return arrivals, nil
+	return arrivals
 }
 
 func min(a, b int) int {
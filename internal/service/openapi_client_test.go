@@ -0,0 +1,64 @@
+package service
+
+import "testing"
+
+func TestParseRouteArrivals(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		plates []string
+	}{
+		{
+			name: "single busArrivalItem",
+			body: `{"response":{"msgHeader":{"resultCode":0},"msgBody":{"busArrivalItem":
+				{"routeId":200000115,"plateNo1":"경기70아1234","plateNo2":"경기70아5678","remainSeatCnt1":12,"remainSeatCnt2":"30","locationNo1":2,"locationNo2":7}}}}`,
+			plates: []string{"경기70아1234", "경기70아5678"},
+		},
+		{
+			name: "busArrivalList with another route",
+			body: `{"response":{"msgHeader":{"resultCode":0},"msgBody":{"busArrivalList":[
+				{"routeId":200000115,"plateNo1":"경기70아1234","plateNo2":"","remainSeatCnt1":12,"locationNo1":2},
+				{"routeId":200000999,"plateNo1":"경기70아9999","plateNo2":"","remainSeatCnt1":5,"locationNo1":1}]}}}`,
+			plates: []string{"경기70아1234"},
+		},
+		{
+			name:   "busArrivalList as a single object",
+			body:   `{"response":{"msgHeader":{"resultCode":0},"msgBody":{"busArrivalList":{"routeId":200000115,"plateNo1":"70아1234"}}}}`,
+			plates: []string{"70아1234"},
+		},
+		{
+			name:   "no buses",
+			body:   `{"response":{"msgHeader":{"resultCode":0},"msgBody":{"busArrivalItem":{"routeId":200000115,"plateNo1":"","plateNo2":""}}}}`,
+			plates: nil,
+		},
+		{
+			name:   "empty body",
+			body:   `{"response":{"msgHeader":{"resultCode":0},"msgBody":{}}}`,
+			plates: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrivals, err := parseRouteArrivals([]byte(tt.body), "200000115", "228000001")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(arrivals) != len(tt.plates) {
+				t.Fatalf("got %d arrivals, want %d: %+v", len(arrivals), len(tt.plates), arrivals)
+			}
+			for i, plate := range tt.plates {
+				if arrivals[i].PlateNo != plate {
+					t.Errorf("arrival %d plate = %q, want %q", i, arrivals[i].PlateNo, plate)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRouteArrivalsAPIError(t *testing.T) {
+	body := `{"response":{"msgHeader":{"resultCode":4,"resultMessage":"결과가 존재하지 않습니다."},"msgBody":{}}}`
+	if _, err := parseRouteArrivals([]byte(body), "1", "2"); err == nil {
+		t.Error("expected an error for a non-zero result code")
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out API requests so that together they stay under a per-second
+// cap. A BusService shares one between all of its clients, since every regional API
+// counts against the same service key.
+type rateLimiter struct {
+	mu   sync.Mutex
+	gap  time.Duration // Minimum time between request starts, 0 = unlimited
+	next time.Time     // Earliest start of the next request
+}
+
+func (l *rateLimiter) setRate(perSecond int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gap = 0
+	if perSecond > 0 {
+		l.gap = time.Second / time.Duration(perSecond)
+	}
+}
+
+// wait blocks until the caller may start a request. A nil limiter never waits.
+func (l *rateLimiter) wait() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if l.gap <= 0 {
+		l.mu.Unlock()
+		return
+	}
+	start := time.Now()
+	if l.next.After(start) {
+		start = l.next
+	}
+	l.next = start.Add(l.gap)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(start))
+}
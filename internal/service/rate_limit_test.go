@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		perSecond int
+		min, max  time.Duration // For 6 requests: 5 gaps after the first
+	}{
+		{"unlimited", 0, 0, 250 * time.Millisecond},
+		{"capped", 10, 5 * 100 * time.Millisecond, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gbis := NewGBISClient(server.URL, "key")
+			incheon := NewIncheonClient(server.URL, "key")
+			s := NewBusService(NewOpenAPIClient(server.URL, "key"), gbis, incheon)
+			s.SetRateLimit(tt.perSecond)
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			for range 3 {
+				wg.Add(2)
+				go func() { defer wg.Done(); gbis.makeRequest(server.URL, url.Values{}) }()
+				go func() { defer wg.Done(); incheon.makeRequest(server.URL, url.Values{}) }()
+			}
+			wg.Wait()
+
+			if elapsed := time.Since(start); elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("6 requests took %s, want %s-%s", elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
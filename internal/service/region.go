@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Region selects which regional API serves a route or station.
+// The values match the region names the search results carry.
+type Region string
+
+const (
+	RegionGyeonggi Region = "경기"
+	RegionIncheon  Region = "인천"
+)
+
+// ErrUnsupportedRegion is returned by calls that a region's API has no equivalent for
+var ErrUnsupportedRegion = errors.New("unsupported region")
+
+// isIncheon reports whether a region string given by the frontend means Incheon
+func isIncheon(region string) bool {
+	return region == string(RegionIncheon) || region == "incheon"
+}
+
+// ParseRegion maps a region string given by the frontend to a Region;
+// anything that isn't Incheon is served by GBIS
+func ParseRegion(region string) Region {
+	if isIncheon(region) {
+		return RegionIncheon
+	}
+	return RegionGyeonggi
+}
+
+// SupportsRegion reports whether a client is configured for a config's region.
+// An empty region is a config saved before regions were recorded and served by GBIS.
+func (s *BusService) SupportsRegion(region string) bool {
+	switch region {
+	case "", string(RegionGyeonggi), "gyeonggi":
+		return s.apiClient != nil && s.gbisClient != nil
+	case string(RegionIncheon), "incheon":
+		return s.incheonClient != nil
+	}
+	return false
+}
+
+// regionFromID guesses the region from the known ID spaces: Incheon route and
+// station IDs are 9 digits starting with 16, Gyeonggi ones 9 digits starting with 2.
+// ok is false when the ID matches neither.
+func regionFromID(id int) (region Region, ok bool) {
+	s := strconv.Itoa(id)
+	if len(s) != 9 {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(s, "16"):
+		return RegionIncheon, true
+	case strings.HasPrefix(s, "2"):
+		return RegionGyeonggi, true
+	}
+	return "", false
+}
+
+// DetectRegion determines which region a route or station ID belongs to. kind is
+// "route" or "station". The ID space decides when it is conclusive; otherwise
+// the GBIS and Incheon APIs are probed. Results are cached for the service's lifetime.
+func (s *BusService) DetectRegion(ctx context.Context, kind string, id int) (Region, error) {
+	if kind != "route" && kind != "station" {
+		return "", fmt.Errorf("unknown kind %q, expected route or station", kind)
+	}
+
+	key := kind + ":" + strconv.Itoa(id)
+	s.regionMu.Lock()
+	region, cached := s.regionCache[key]
+	s.regionMu.Unlock()
+	if cached {
+		return region, nil
+	}
+
+	region, ok := regionFromID(id)
+	if !ok {
+		var err error
+		if region, err = s.probeRegion(ctx, kind, strconv.Itoa(id)); err != nil {
+			return "", err
+		}
+	}
+
+	s.regionMu.Lock()
+	s.regionCache[key] = region
+	s.regionMu.Unlock()
+	return region, nil
+}
+
+// probeRegion asks each regional API about the ID and returns the first that knows it
+func (s *BusService) probeRegion(ctx context.Context, kind, id string) (Region, error) {
+	if kind == "route" {
+		if stations, err := s.GetRouteStations(ctx, id, string(RegionGyeonggi)); err == nil && len(stations) > 0 {
+			return RegionGyeonggi, nil
+		}
+		if stations, err := s.GetRouteStations(ctx, id, string(RegionIncheon)); err == nil && len(stations) > 0 {
+			return RegionIncheon, nil
+		}
+	} else {
+		if routes, err := s.gbisClient.GetRoutesByStation(id); err == nil && len(routes) > 0 {
+			return RegionGyeonggi, nil
+		}
+		// Incheon has no route-list lookup for a station; arrivals only show while buses run
+		if arrivals, err := s.incheonClient.GetBusArrivalsByStation(id); err == nil && len(arrivals) > 0 {
+			return RegionIncheon, nil
+		}
+	}
+
+	log.Printf("[BusService] Could not determine region for %s %s", kind, id)
+	return "", fmt.Errorf("could not determine region for %s %s", kind, id)
+}
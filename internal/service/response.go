@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bus_history/internal/model"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxResponseBytes caps how much of an API response body is read.
+// Real responses are a few hundred KB at most.
+const DefaultMaxResponseBytes int64 = 10 << 20
+
+// readLimitedBody reads the whole body, failing instead of buffering more than limit bytes
+func readLimitedBody(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response body exceeds limit of %d bytes", limit)
+	}
+	return body, nil
+}
+
+// redactedURL is u for logging, with the serviceKey query value masked so the
+// data.go.kr key never reaches the log sink
+func redactedURL(u *url.URL) string {
+	q := u.Query()
+	if !q.Has("serviceKey") {
+		return u.String()
+	}
+	q.Set("serviceKey", "REDACTED")
+	masked := *u
+	masked.RawQuery = q.Encode()
+	return masked.String()
+}
+
+// unmarshalArrayOrSingle decodes a list field from the public data APIs.
+// These APIs return a JSON array when there are several items, a bare object
+// when there is exactly one, and null/""/nothing when there are none.
+func unmarshalArrayOrSingle[T any](raw json.RawMessage) ([]T, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`)) {
+		return []T{}, nil
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var items []T
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse item list: %w", err)
+		}
+		if items == nil {
+			items = []T{}
+		}
+		return items, nil
+	case '{':
+		var item T
+		if err := json.Unmarshal(trimmed, &item); err != nil {
+			return nil, fmt.Errorf("failed to parse single item: %w", err)
+		}
+		return []T{item}, nil
+	default:
+		return nil, fmt.Errorf("unexpected item list format: %.32s", trimmed)
+	}
+}
+
+// flexString accepts a JSON string or number; the APIs encode some names
+// (e.g. route names like 7700) as bare numbers
+type flexString string
+
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		*f = ""
+		return nil
+	}
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return err
+		}
+		*f = flexString(s)
+		return nil
+	}
+	*f = flexString(trimmed)
+	return nil
+}
+
+// flexInt accepts a JSON number or a number in a string; some endpoint
+// versions quote numeric fields like remainSeatCnt
+type flexInt int
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		*f = 0
+		return nil
+	}
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return err
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			*f = 0
+			return nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %w", s, err)
+		}
+		*f = flexInt(n)
+		return nil
+	}
+	var n int
+	if err := json.Unmarshal(trimmed, &n); err != nil {
+		return err
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+// stationArrivalItem is a busArrivalList item of the station arrival endpoints
+// as sent, with numeric fields that may come quoted
+type stationArrivalItem struct {
+	RouteID       flexInt    `json:"routeId"`
+	RouteName     flexString `json:"routeName"`
+	RouteTypeName string     `json:"routeTypeName"`
+	StationID     flexInt    `json:"stationId"`
+	StaOrder      flexInt    `json:"staOrder"`
+	PlateNo       string     `json:"plateNo"`
+	RemainSeatCnt flexInt    `json:"remainSeatCnt"`
+	PredictTime1  flexInt    `json:"predictTime1"`
+	LocationNo1   flexInt    `json:"locationNo1"`
+	LowPlate1     flexInt    `json:"lowPlate1"`
+	Direction     string     `json:"direction"`
+}
+
+func (a stationArrivalItem) arrivalInfo() model.BusArrivalInfo {
+	return model.BusArrivalInfo{
+		RouteID:       int(a.RouteID),
+		RouteName:     string(a.RouteName),
+		StationID:     int(a.StationID),
+		StationSeq:    int(a.StaOrder),
+		PlateNo:       a.PlateNo,
+		RemainSeatCnt: int(a.RemainSeatCnt),
+		PredictTime1:  int(a.PredictTime1),
+		LocationNo1:   int(a.LocationNo1),
+		LowPlate1:     int(a.LowPlate1),
+	}
+}
+
+func (a stationArrivalItem) apiArrival() model.APIBusArrival {
+	return model.APIBusArrival{
+		RouteID:       int(a.RouteID),
+		RouteName:     string(a.RouteName),
+		RouteTypeName: a.RouteTypeName,
+		StationID:     int(a.StationID),
+		StationSeq:    int(a.StaOrder),
+		PlateNo:       a.PlateNo,
+		RemainSeatCnt: int(a.RemainSeatCnt),
+		PredictTime1:  int(a.PredictTime1),
+		LocationNo1:   int(a.LocationNo1),
+		LowPlate1:     int(a.LowPlate1),
+		Direction:     a.Direction,
+	}
+}
+
+// busLocationItem is a busLocationList item as sent, with numeric fields that may come quoted
+type busLocationItem struct {
+	RouteID       flexInt `json:"routeId"`
+	StationID     flexInt `json:"stationId"`
+	StationSeq    flexInt `json:"stationSeq"`
+	PlateNo       string  `json:"plateNo"`
+	PlateType     flexInt `json:"plateType"`
+	RemainSeatCnt flexInt `json:"remainSeatCnt"`
+	StationName   string  `json:"stationName"`
+}
+
+func (l busLocationItem) location() model.BusLocation {
+	return model.BusLocation{
+		RouteID:       int(l.RouteID),
+		StationID:     int(l.StationID),
+		StationSeq:    int(l.StationSeq),
+		PlateNo:       l.PlateNo,
+		PlateType:     int(l.PlateType),
+		RemainSeatCnt: int(l.RemainSeatCnt),
+		StationName:   l.StationName,
+	}
+}
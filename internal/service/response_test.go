@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalArrayOrSingle(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    []item
+		wantErr bool
+	}{
+		{name: "array", raw: `[{"id":1},{"id":2}]`, want: []item{{ID: 1}, {ID: 2}}},
+		{name: "single object", raw: `{"id":7}`, want: []item{{ID: 7}}},
+		{name: "empty array", raw: `[]`, want: []item{}},
+		{name: "null", raw: `null`, want: []item{}},
+		{name: "empty string", raw: `""`, want: []item{}},
+		{name: "missing", raw: ``, want: []item{}},
+		{name: "whitespace", raw: "  \n", want: []item{}},
+		{name: "unexpected scalar", raw: `42`, wantErr: true},
+		{name: "malformed array", raw: `[{"id":"x"}]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unmarshalArrayOrSingle[item](json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    int
+		wantErr bool
+	}{
+		{"number", `12`, 12, false},
+		{"quoted number", `"12"`, 12, false},
+		{"quoted with spaces", `" 7 "`, 7, false},
+		{"negative", `"-1"`, -1, false},
+		{"empty string", `""`, 0, false},
+		{"null", `null`, 0, false},
+		{"not a number", `"abc"`, 0, true},
+		{"float", `1.5`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got flexInt
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && int(got) != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStationArrivalItemQuotedFields(t *testing.T) {
+	var item stationArrivalItem
+	data := `{"routeId":"200000115","routeName":7700,"staOrder":"12","plateNo":"경기70아1234","remainSeatCnt":"","predictTime1":"5","locationNo1":2}`
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	got := item.arrivalInfo()
+	if got.RouteID != 200000115 || got.RouteName != "7700" || got.StationSeq != 12 ||
+		got.RemainSeatCnt != 0 || got.PredictTime1 != 5 || got.LocationNo1 != 2 {
+		t.Errorf("arrivalInfo() = %+v", got)
+	}
+}
+
+func TestRedactedURL(t *testing.T) {
+	u, _ := url.Parse("https://apis.data.go.kr/6410000/busarrivalservice/v2/getBusArrivalListv2?format=json&serviceKey=abc%2Bsecret%3D%3D&stationId=228000704")
+
+	got := redactedURL(u)
+	if strings.Contains(got, "abc") || strings.Contains(got, "secret") {
+		t.Errorf("redactedURL() = %q, still contains the service key", got)
+	}
+	if !strings.Contains(got, "stationId=228000704") || !strings.Contains(got, "serviceKey=REDACTED") {
+		t.Errorf("redactedURL() = %q, want the other parameters kept and the key masked", got)
+	}
+	if u.Query().Get("serviceKey") != "abc+secret==" {
+		t.Errorf("redactedURL() modified the request URL: %q", u.String())
+	}
+}
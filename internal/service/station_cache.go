@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bus_history/internal/model"
+	"slices"
+	"sync"
+	"time"
+)
+
+// routeStationsTTL is how long a route's station list is reused; route topology rarely changes
+const routeStationsTTL = 10 * time.Minute
+
+type routeStationsKey struct {
+	region  Region
+	routeID string
+}
+
+type routeStationsEntry struct {
+	stations  []model.RouteStation
+	fetchedAt time.Time
+}
+
+// routeStationsCache holds station lists per (region, route) for GetRouteStations
+type routeStationsCache struct {
+	mu      sync.Mutex
+	entries map[routeStationsKey]routeStationsEntry
+}
+
+func newRouteStationsCache() *routeStationsCache {
+	return &routeStationsCache{entries: make(map[routeStationsKey]routeStationsEntry)}
+}
+
+// get returns a copy of a fresh cached list
+func (c *routeStationsCache) get(key routeStationsKey) ([]model.RouteStation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) >= routeStationsTTL {
+		return nil, false
+	}
+	return slices.Clone(entry.stations), true
+}
+
+func (c *routeStationsCache) put(key routeStationsKey, stations []model.RouteStation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = routeStationsEntry{stations: slices.Clone(stations), fetchedAt: time.Now()}
+}
+
+func (c *routeStationsCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
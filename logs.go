@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// recentLogLines is how many log lines the sink keeps for GetRecentLogs
+const recentLogLines = 1000
+
+// logEmitInterval batches log lines into one "log-lines" event, so that a burst
+// of logging (a poll cycle across many configs) doesn't flood the frontend
+const logEmitInterval = 250 * time.Millisecond
+
+// Log levels, lowest first
+const (
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+var logLevelRank = map[string]int{LogLevelInfo: 0, LogLevelWarn: 1, LogLevelError: 2}
+
+// LogLine is one line written through the standard logger
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"` // LogLevelError, LogLevelWarn or LogLevelInfo, from the message prefix
+	Message string    `json:"message"`
+}
+
+// logSink keeps the most recent log lines in a ring buffer and forwards new
+// lines to the frontend in "log-lines" events once a context is attached
+type logSink struct {
+	mu      sync.Mutex
+	lines   []LogLine
+	next    int
+	full    bool
+	ctx     context.Context
+	pending []LogLine // Lines not emitted yet
+	timer   *time.Timer
+}
+
+func newLogSink(size int) *logSink {
+	return &logSink{lines: make([]LogLine, size)}
+}
+
+// attach starts emitting events on ctx
+func (s *logSink) attach(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+}
+
+// Write implements io.Writer; the log package calls it once per entry
+func (s *logSink) Write(p []byte) (int, error) {
+	msg := serviceKeyParam.ReplaceAllString(strings.TrimRight(string(p), "\n"), "${1}REDACTED")
+	line := LogLine{Time: time.Now(), Level: logLevel(msg), Message: msg}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines[s.next] = line
+	s.next = (s.next + 1) % len(s.lines)
+	if s.next == 0 {
+		s.full = true
+	}
+	if s.ctx != nil && len(s.pending) < len(s.lines) {
+		s.pending = append(s.pending, line)
+		if s.timer == nil {
+			s.timer = time.AfterFunc(logEmitInterval, s.flush)
+		}
+	}
+	return len(p), nil
+}
+
+// flush emits the pending lines as one "log-lines" event
+func (s *logSink) flush() {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.timer = nil
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	// Emit outside the lock in case the runtime logs through us
+	if ctx != nil && len(batch) > 0 {
+		runtime.EventsEmit(ctx, "log-lines", batch)
+	}
+}
+
+// recent returns up to n of the newest lines at or above minLevel, oldest first
+// (n <= 0 = all kept)
+func (s *logSink) recent(n int, minLevel string) []LogLine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := s.next
+	if s.full {
+		count = len(s.lines)
+	}
+	if n <= 0 || n > count {
+		n = count
+	}
+
+	// Walk back from the newest line, then reverse into oldest-first order
+	minRank := logLevelRank[minLevel]
+	var out []LogLine
+	for i := 1; i <= count && len(out) < n; i++ {
+		line := s.lines[(s.next-i+len(s.lines))%len(s.lines)]
+		if logLevelRank[line.Level] >= minRank {
+			out = append(out, line)
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if out == nil {
+		out = []LogLine{}
+	}
+	return out
+}
+
+// checkLogLevel fails unless level is "" (all lines) or one of the log levels
+func checkLogLevel(level string) error {
+	if _, ok := logLevelRank[level]; !ok && level != "" {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	return nil
+}
+
+// serviceKeyParam matches a serviceKey query value, so a request URL logged
+// anywhere can't carry the data.go.kr key to the frontend
+var serviceKeyParam = regexp.MustCompile(`(?i)(serviceKey=)[^&\s"]*`)
+
+// logPrefix matches what the code puts before a message: the standard logger's
+// date and time, then an optional "[Component]" tag
+var logPrefix = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? )?(\[[^\]]*\] )?`)
+
+// logLevel reads the level from the start of the message, after the logPrefix:
+// the ❌/⚠️ markers, or a leading "Error"/"Failed"/"Warning"/"Timeout" word. A
+// word elsewhere in the message (a bus named in an error count, say) doesn't count.
+func logLevel(msg string) string {
+	body := strings.TrimSpace(msg[len(logPrefix.FindString(msg)):])
+	word, _, _ := strings.Cut(strings.ToLower(body), " ")
+	word = strings.TrimRight(word, ":")
+	switch {
+	case strings.HasPrefix(body, "❌") || word == "error" || word == "failed":
+		return LogLevelError
+	case strings.HasPrefix(body, "⚠️") || word == "warning" || word == "warn" || word == "timeout":
+		return LogLevelWarn
+	default:
+		return LogLevelInfo
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"2026/10/16 08:00:00 [Collector] ❌ Error saving bus arrival: disk full", LogLevelError},
+		{"2026/10/16 08:00:00 [Collector] Error fetching data for route 200000115", LogLevelError},
+		{"2026/10/16 08:00:00 Failed to read applied migrations: locked", LogLevelError},
+		{"2026/10/16 08:00:00 [Collector] ⚠️ Timeout waiting for seat data for bus 70아1234", LogLevelWarn},
+		{"2026/10/16 08:00:00 [Incheon] Warning: unexpected field", LogLevelWarn},
+		{"2026/10/16 08:00:00 [Collector] Filled seats_after of 0/2 arrivals, 2 failed lookups", LogLevelInfo},
+		{"2026/10/16 08:00:00 [Tracking] Bus 70아1234 getting closer: location=2, seats=20", LogLevelInfo},
+		{"Error without the standard prefix", LogLevelError},
+		{"", LogLevelInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := logLevel(tt.msg); got != tt.want {
+				t.Errorf("logLevel(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogSinkRecentByLevel(t *testing.T) {
+	s := newLogSink(4)
+	for _, msg := range []string{"Error one", "info two", "⚠️ warn three", "info four", "Failed five"} {
+		s.Write([]byte(msg + "\n"))
+	}
+
+	tests := []struct {
+		n     int
+		level string
+		want  []string
+	}{
+		{0, "", []string{"info two", "⚠️ warn three", "info four", "Failed five"}},
+		{2, "", []string{"info four", "Failed five"}},
+		{0, LogLevelWarn, []string{"⚠️ warn three", "Failed five"}},
+		{1, LogLevelWarn, []string{"Failed five"}},
+		{0, LogLevelError, []string{"Failed five"}},
+	}
+	for _, tt := range tests {
+		got := s.recent(tt.n, tt.level)
+		if len(got) != len(tt.want) {
+			t.Errorf("recent(%d, %q) returned %d lines, want %d", tt.n, tt.level, len(got), len(tt.want))
+			continue
+		}
+		for i, line := range got {
+			if line.Message != tt.want[i] {
+				t.Errorf("recent(%d, %q)[%d] = %q, want %q", tt.n, tt.level, i, line.Message, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestLogSinkRedactsServiceKey(t *testing.T) {
+	s := newLogSink(4)
+	s.Write([]byte("2026/10/16 08:00:00 [OpenAPI] Requesting: https://apis.data.go.kr/6410000/busarrivalservice/v2/getBusArrivalListv2?format=json&serviceKey=abc%2Bsecret%3D%3D&stationId=228000704\n"))
+
+	got := s.recent(0, "")
+	if len(got) != 1 {
+		t.Fatalf("recent() returned %d lines, want 1", len(got))
+	}
+	if strings.Contains(got[0].Message, "abc") || strings.Contains(got[0].Message, "secret") {
+		t.Errorf("recent() line %q still contains the service key", got[0].Message)
+	}
+	if !strings.Contains(got[0].Message, "serviceKey=REDACTED&stationId=228000704") {
+		t.Errorf("recent() line %q, want the key masked and the rest kept", got[0].Message)
+	}
+}
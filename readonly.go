@@ -0,0 +1,28 @@
+package main
+
+import "errors"
+
+// errReadOnly is returned by bindings that change data or state in read-only mode
+var errReadOnly = errors.New("read-only mode: changes are disabled on this display")
+
+// checkWritable fails when the ReadOnly setting is on. Every binding that changes
+// configs, settings, stored data or the collector calls it first:
+//
+//   - Configs: CreateConfig, CreateConfigUnchecked, CreateConfigsForRoute,
+//     ImportConfigs, DeleteConfig, PurgeConfig, DeduplicateConfigs, ToggleConfig,
+//     RederiveStaOrders, SetConfigInterval, SetDisplayName, SetExpectedHeadway,
+//     SetMinRecordGap, SetRecordApproach, SetRecordMode, SetRouteGroup, SetStopType,
+//     SetScheduleException, RemoveScheduleException, SetScheduleTimes
+//   - Collection: StartCollection, StopCollection, StartCollector, StopCollector,
+//     SetCollectionInterval, ResetCollectionInterval
+//   - Settings and data: SaveSettings, UpdateSettings, ImportBundle,
+//     RecomputeBoarding, RepairDatabase
+//
+// Reads, exports and the route search stay available. Since SaveSettings is gated
+// too, read-only mode is turned off by editing the settings file.
+func (a *App) checkWritable() error {
+	if a.settings != nil && a.settings.ReadOnly {
+		return errReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bus_history/internal/model"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A matched pass counts as on time from scheduleEarlyMin minutes early up to
+// scheduleLateMin minutes late
+const (
+	scheduleEarlyMin = 1
+	scheduleLateMin  = 5
+)
+
+// CompareToSchedule scores a config's observed passes against a timetable CSV with
+// a "time" column. Times given as "HH:MM" form a daily timetable, applied to every
+// date with collected data so days without collection don't count as missed; full
+// timestamps (as in context files) are compared as they are. Each scheduled time is
+// matched to the nearest observed pass within toleranceMin (default 10) minutes.
+func (a *App) CompareToSchedule(configID int64, schedulePath string, toleranceMin int) (*model.ScheduleAdherence, error) {
+	if a.busRepo == nil {
+		return nil, fmt.Errorf("DB not initialized")
+	}
+	cfg, err := a.configRepo.FindByID(configID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("config %d not found", configID)
+	}
+	if toleranceMin <= 0 {
+		toleranceMin = 10
+	}
+
+	daily, fixed, err := loadScheduleCSV(schedulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := fixed
+	days := 0
+	if len(daily) > 0 {
+		loc, _ := time.LoadLocation("Asia/Seoul")
+		dates, err := a.busRepo.GetDatesWithData(configID, loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, date := range dates {
+			day, err := time.ParseInLocation("2006-01-02", date, loc)
+			if err != nil {
+				continue
+			}
+			for _, offset := range daily {
+				scheduled = append(scheduled, day.Add(offset))
+			}
+		}
+		days = len(dates)
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].Before(scheduled[j]) })
+
+	report := &model.ScheduleAdherence{
+		ToleranceMin: toleranceMin,
+		EarlyMin:     scheduleEarlyMin,
+		LateMin:      scheduleLateMin,
+		Days:         days,
+	}
+	if len(scheduled) == 0 {
+		return report, nil
+	}
+
+	tolerance := time.Duration(toleranceMin) * time.Minute
+	from := scheduled[0].Add(-tolerance)
+	to := scheduled[len(scheduled)-1].Add(tolerance)
+	var observed []time.Time
+	if _, err := a.busRepo.ForEachByConfig(configID, &from, &to, func(arrival *model.BusArrivalWithConfig) error {
+		observed = append(observed, arrival.ArrivalTime)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(observed, func(i, j int) bool { return observed[i].Before(observed[j]) })
+
+	matchSchedule(report, scheduled, observed, tolerance)
+	return report, nil
+}
+
+// matchSchedule pairs each scheduled time, in order, with the nearest observed time
+// within tolerance that no earlier scheduled time took, and tallies the report.
+// Both slices must be sorted.
+func matchSchedule(report *model.ScheduleAdherence, scheduled, observed []time.Time, tolerance time.Duration) {
+	used := make([]bool, len(observed))
+	var deviationSum float64
+	matched := 0
+
+	for _, s := range scheduled {
+		report.Scheduled++
+
+		best := -1
+		idx := sort.Search(len(observed), func(i int) bool { return !observed[i].Before(s) })
+		for i := idx - 1; i >= 0 && s.Sub(observed[i]) <= tolerance; i-- {
+			if !used[i] {
+				best = i
+				break
+			}
+		}
+		for i := idx; i < len(observed) && observed[i].Sub(s) <= tolerance; i++ {
+			if !used[i] {
+				if best < 0 || observed[i].Sub(s) < s.Sub(observed[best]) {
+					best = i
+				}
+				break
+			}
+		}
+		if best < 0 {
+			report.Missed++
+			continue
+		}
+
+		used[best] = true
+		deviation := observed[best].Sub(s).Minutes()
+		deviationSum += deviation
+		matched++
+		switch {
+		case deviation < -scheduleEarlyMin:
+			report.Early++
+		case deviation > scheduleLateMin:
+			report.Late++
+		default:
+			report.OnTime++
+		}
+	}
+
+	if matched > 0 {
+		report.AvgDeviationMin = math.Round(deviationSum/float64(matched)*10) / 10
+	}
+	if report.Scheduled > 0 {
+		report.OnTimeRate = float64(report.OnTime) / float64(report.Scheduled)
+	}
+}
+
+// loadScheduleCSV reads a timetable CSV's "time" column, returning "HH:MM" entries
+// as offsets from midnight and full timestamps separately
+func loadScheduleCSV(path string) ([]time.Duration, []time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read schedule file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse schedule CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("schedule CSV is empty")
+	}
+
+	timeCol := -1
+	for i, name := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(name), "time") {
+			timeCol = i
+		}
+	}
+	if timeCol < 0 {
+		return nil, nil, fmt.Errorf("schedule CSV has no time column")
+	}
+
+	var daily []time.Duration
+	var fixed []time.Time
+	for line, rec := range records[1:] {
+		value := strings.TrimSpace(rec[timeCol])
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse("15:04", value); err == nil {
+			daily = append(daily, time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute)
+			continue
+		}
+		t, err := parseContextTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("schedule CSV line %d: %w", line+2, err)
+		}
+		fixed = append(fixed, t)
+	}
+	return daily, fixed, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bus_history/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// validateConcurrency bounds the API calls ValidateAllConfigs makes at once
+const validateConcurrency = 4
+
+// ConfigValidation is the result of checking one config against the live API
+type ConfigValidation struct {
+	ConfigID    int64    `json:"configId"`
+	RouteName   string   `json:"routeName"`
+	StationName string   `json:"stationName"`
+	Healthy     bool     `json:"healthy"`
+	Problems    []string `json:"problems"`
+	Direction   string   `json:"direction"`  // Direction the API gives the station now, "" if unknown
+	StationSeq  int      `json:"stationSeq"` // Position of the station on the route now, -1 if not on it
+}
+
+// ValidateConfig checks a config against the live API: its region has a client,
+// the route still serves the station, and the stored direction and station order
+// match the route's current station list
+func (a *App) ValidateConfig(configID int64) (*ConfigValidation, error) {
+	if a.configRepo == nil || a.busService == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+
+	cfg, err := a.configRepo.FindByID(configID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("config %d not found", configID)
+	}
+	return a.validateConfig(cfg), nil
+}
+
+// ValidateAllConfigs runs ValidateConfig over every config, a few at a time, after
+// dropping cached station lists so that route changes show up. Results follow
+// the order of GetConfigs.
+func (a *App) ValidateAllConfigs() ([]ConfigValidation, error) {
+	if a.configRepo == nil || a.busService == nil {
+		return nil, fmt.Errorf("system not initialized")
+	}
+
+	configs, err := a.configRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	a.busService.ClearRouteStationCache()
+
+	results := make([]ConfigValidation, len(configs))
+	sem := make(chan struct{}, validateConcurrency)
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func(i int, cfg *model.RouteConfig) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = *a.validateConfig(cfg)
+		}(i, cfg)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func (a *App) validateConfig(cfg *model.RouteConfig) *ConfigValidation {
+	v := &ConfigValidation{
+		ConfigID:    cfg.ID,
+		RouteName:   cfg.Label(),
+		StationName: cfg.StationName,
+		Problems:    []string{},
+		StationSeq:  -1,
+	}
+
+	if !a.busService.SupportsRegion(cfg.Region) {
+		v.Problems = append(v.Problems, fmt.Sprintf("no API client for region %q", cfg.Region))
+		return v
+	}
+
+	if err := a.checkStationOnRoute(cfg.RouteID, cfg.StationID, cfg.Region); err != nil {
+		v.Problems = append(v.Problems, err.Error())
+		return v
+	}
+
+	// Served from the station list just loaded
+	stations, directions, err := a.busService.StationDirections(a.ctx, cfg.RouteID, cfg.Region)
+	if err != nil {
+		v.Problems = append(v.Problems, fmt.Sprintf("failed to detect direction: %v", err))
+		return v
+	}
+	occurrences := stationOccurrences(stations, directions, cfg.StationID)
+	match := matchOccurrence(occurrences, cfg)
+	v.Direction = match.Direction
+	v.StationSeq = match.StationSeq
+
+	if cfg.StaOrder > 0 && cfg.StaOrder != match.StationSeq {
+		v.Problems = append(v.Problems, fmt.Sprintf("sta_order is %d, the route now lists the station at %s",
+			cfg.StaOrder, occurrenceSeqs(occurrences)))
+	}
+	if cfg.Direction != "" && match.Direction != "" && cfg.Direction != match.Direction {
+		v.Problems = append(v.Problems, fmt.Sprintf("direction is %s, the route now gives %s at station order %d",
+			cfg.Direction, match.Direction, match.StationSeq))
+	}
+
+	v.Healthy = len(v.Problems) == 0
+	return v
+}
+
+// stationOccurrence is one place a station appears in a route's station list.
+// Loop routes list a station twice, once in each direction.
+type stationOccurrence struct {
+	StationSeq int
+	Direction  string
+}
+
+// stationOccurrences returns every occurrence of a station on a route, in route order
+func stationOccurrences(stations []model.RouteStation, directions []string, stationID string) []stationOccurrence {
+	var occurrences []stationOccurrence
+	for i, st := range stations {
+		if strconv.Itoa(st.StationID) == stationID {
+			occurrences = append(occurrences, stationOccurrence{StationSeq: st.StationSeq, Direction: directions[i]})
+		}
+	}
+	return occurrences
+}
+
+// matchOccurrence picks the occurrence a config stands for: the one at its
+// sta_order, else the first in its direction, else the first on the route
+func matchOccurrence(occurrences []stationOccurrence, cfg *model.RouteConfig) stationOccurrence {
+	if len(occurrences) == 0 {
+		return stationOccurrence{StationSeq: -1}
+	}
+	if cfg.StaOrder > 0 {
+		for _, o := range occurrences {
+			if o.StationSeq == cfg.StaOrder {
+				return o
+			}
+		}
+	}
+	if cfg.Direction != "" {
+		for _, o := range occurrences {
+			if o.Direction == cfg.Direction {
+				return o
+			}
+		}
+	}
+	return occurrences[0]
+}
+
+// occurrenceSeqs lists the station orders of the occurrences, e.g. "5" or "5 and 31"
+func occurrenceSeqs(occurrences []stationOccurrence) string {
+	seqs := make([]string, len(occurrences))
+	for i, o := range occurrences {
+		seqs[i] = strconv.Itoa(o.StationSeq)
+	}
+	return strings.Join(seqs, " and ")
+}
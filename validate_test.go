@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bus_history/internal/model"
+	"testing"
+)
+
+func TestMatchOccurrence(t *testing.T) {
+	// A loop route listing the station on both passes
+	loop := []model.RouteStation{
+		{StationID: 100, StationSeq: 1},
+		{StationID: 200, StationSeq: 5},
+		{StationID: 300, StationSeq: 10, TurnYn: "Y"},
+		{StationID: 200, StationSeq: 15},
+	}
+	directions := []string{"상행", "상행", "회차", "하행"}
+
+	tests := []struct {
+		name      string
+		cfg       model.RouteConfig
+		wantSeq   int
+		wantDir   string
+		wantCount int
+	}{
+		{"first pass by sta_order", model.RouteConfig{StationID: "200", StaOrder: 5, Direction: "상행"}, 5, "상행", 2},
+		{"second pass by sta_order", model.RouteConfig{StationID: "200", StaOrder: 15, Direction: "하행"}, 15, "하행", 2},
+		{"second pass by direction only", model.RouteConfig{StationID: "200", Direction: "하행"}, 15, "하행", 2},
+		{"stale sta_order falls back to direction", model.RouteConfig{StationID: "200", StaOrder: 7, Direction: "하행"}, 15, "하행", 2},
+		{"nothing stored takes the first pass", model.RouteConfig{StationID: "200"}, 5, "상행", 2},
+		{"single occurrence", model.RouteConfig{StationID: "100", StaOrder: 1}, 1, "상행", 1},
+		{"not on route", model.RouteConfig{StationID: "999"}, -1, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences := stationOccurrences(loop, directions, tt.cfg.StationID)
+			if len(occurrences) != tt.wantCount {
+				t.Fatalf("got %d occurrences, want %d", len(occurrences), tt.wantCount)
+			}
+			got := matchOccurrence(occurrences, &tt.cfg)
+			if got.StationSeq != tt.wantSeq || got.Direction != tt.wantDir {
+				t.Errorf("matched (%d, %q), want (%d, %q)", got.StationSeq, got.Direction, tt.wantSeq, tt.wantDir)
+			}
+		})
+	}
+}
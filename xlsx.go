@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet streams rows into one sheet of a workbook. Rows are appended in order,
+// and the sheet must be flushed before the next one is started or the workbook saved.
+type xlsxSheet struct {
+	sw  *excelize.StreamWriter
+	row int
+}
+
+// newXLSXSheet adds a sheet named name to wb (or reuses an existing one) for streaming
+func newXLSXSheet(wb *excelize.File, name string) (*xlsxSheet, error) {
+	if _, err := wb.NewSheet(name); err != nil {
+		return nil, fmt.Errorf("failed to add sheet %s: %w", name, err)
+	}
+	sw, err := wb.NewStreamWriter(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add sheet %s: %w", name, err)
+	}
+	return &xlsxSheet{sw: sw}, nil
+}
+
+// Row appends a row. Strings become text cells, numbers number cells, and nil
+// (including nil *int) empty cells.
+func (s *xlsxSheet) Row(cells ...any) error {
+	for i, cell := range cells {
+		if v, ok := cell.(*int); ok {
+			if v == nil {
+				cells[i] = nil
+			} else {
+				cells[i] = *v
+			}
+		}
+	}
+
+	s.row++
+	ref, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	return s.sw.SetRow(ref, cells)
+}
+
+// Flush finishes the sheet
+func (s *xlsxSheet) Flush() error {
+	return s.sw.Flush()
+}